@@ -0,0 +1,512 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Parâmetro da linha de comando (ex: --nosleep, --model gpt-4). Pode ser informado antes ou
+	// depois do texto da pergunta, com um ou dois hífens e com o valor após "=" ou no argumento seguinte.
+	Opcao struct {
+		Nome       string // Nome do parâmetro, sem os hífens.
+		Argumento  string // Descrição do valor exibida no help (ex: "<arquivo>"). Se vazio, o parâmetro é booleano.
+		Descricao  string // Texto exibido no help. Pode ter mais de uma linha.
+		Subcomando string // Se informado, o parâmetro só é aceito nesse subcomando.
+		Padrao     string // Se informado, o valor padrão é exibido no help, após a descrição.
+
+		// Aplica o valor do parâmetro. Nos parâmetros booleanos, o valor é "true" ou "false".
+		Define func(sessao *Sessao, valor string) error
+	}
+
+	// Subcomando da linha de comando (ex: gpt ask, gpt chat). É sempre o primeiro argumento
+	// que não é parâmetro. Se não for informado, a linha de comando é tratada como a pergunta.
+	Subcomando struct {
+		Nome       string   // Nome do subcomando.
+		Aliases    []string // Nomes alternativos do subcomando.
+		Argumentos string   // Descrição dos argumentos exibida no help (ex: "<pergunta>").
+		Descricao  string   // Texto exibido no help.
+
+		// Executa o subcomando com os demais argumentos (já sem os parâmetros).
+		// Retorna a pergunta a ser enviada à IA, se houver.
+		Executa func(sessao *Sessao, args []string) (string, error)
+	}
+)
+
+const (
+	// Código de saída do programa quando a linha de comando é inválida.
+	SAIDA_USO_INVALIDO = 2
+)
+
+var (
+	opcoes        = make([]*Opcao, 0)       // Parâmetros na ordem em que foram registrados (usada no help).
+	opcoesPorNome = make(map[string]*Opcao) // Parâmetros indexados pelo nome.
+
+	subcomandos        = make([]*Subcomando, 0)       // Subcomandos na ordem em que foram registrados.
+	subcomandosPorNome = make(map[string]*Subcomando) // Subcomandos indexados pelo nome e pelos aliases.
+)
+
+// Registra os parâmetros e os subcomandos básicos.
+// Os demais são registrados na função init() do arquivo onde são implementados.
+func init() {
+	registraOpcao(&Opcao{
+		Nome:      "help",
+		Descricao: "Exibe estas informações de ajuda.",
+		Define:    defineBooleano(func(s *Sessao) *bool { return &s.pediuAjuda }),
+	})
+	registraOpcao(&Opcao{
+		Nome: "nosleep",
+		Descricao: "Imprime a resposta de uma só vez, sem delay.\n" +
+			"Tecle \033[36mESC\033[m para interromper a impressão da resposta.\n" +
+			"Tecle \033[36mESPAÇO\033[m para imprimir a resposta completa sem delay.\n" +
+			"Durante a narração: \033[36mF8\033[m pausa/continua, \033[36mseta para a direita\033[m pula o trecho,\n" +
+			"\033[36msetas para cima/baixo\033[m alteram o volume e \033[36mPage Up/Page Down\033[m a velocidade.",
+		Define: defineBooleano(func(s *Sessao) *bool { return &s.noSleep }),
+	})
+	registraOpcao(&Opcao{
+		Nome:      "printjson",
+		Descricao: "Imprime o conteúdo json retornado pelo servidor (payload).",
+		Define:    defineBooleano(func(s *Sessao) *bool { return &s.printJson }),
+	})
+	registraOpcao(&Opcao{
+		Nome: "json",
+		Descricao: "Imprime somente um objeto json com a resposta, o modelo, o motivo do fim,\n" +
+			"os tokens consumidos, o tempo de resposta e o erro (se houver). Não narra a resposta.",
+		Define: func(sessao *Sessao, valor string) error {
+			if valor == "true" {
+				sessao.ativaSaidaJson()
+			}
+			return nil
+		},
+	})
+	registraOpcao(&Opcao{
+		Nome:      "interativo",
+		Descricao: "Executa no modo interativo, para manter o histórico da conversa (o mesmo que \033[36mgpt chat\033[m).",
+		Define:    defineBooleano(func(s *Sessao) *bool { return &s.interativo }),
+	})
+	registraOpcao(&Opcao{
+		Nome:      "model",
+		Argumento: "<modelo>",
+		Descricao: "Usa o modelo informado, em vez do parâmetro GPT_MODEL (ex: \033[36m--model gpt-4\033[m).",
+		Define: defineTexto(func(s *Settings, valor string) {
+			s.GPT_MODEL = valor
+		}),
+	})
+	registraOpcao(&Opcao{
+		Nome:      "temperature",
+		Argumento: "<0.0 a 2.0>",
+		Descricao: "Usa a temperatura informada, em vez do parâmetro TEMPERATURE.",
+		Define: func(sessao *Sessao, valor string) error {
+			t, err := strconv.ParseFloat(valor, 32)
+			if err != nil || t < 0 || t > 2 {
+				return novoErro("informe um número entre 0.0 e 2.0")
+			}
+			sessao.sobrepoe(func(s *Settings) { s.TEMPERATURE = float32(t) })
+			return nil
+		},
+	})
+	registraOpcao(&Opcao{
+		Nome:      "lang",
+		Argumento: "<idioma>",
+		Descricao: "Usa o idioma informado na resposta e na narração, em vez do parâmetro IDIOMA (ex: \033[36m--lang en-us\033[m).\n" +
+			"Com \033[36m--lang auto\033[m, responde e narra no idioma detectado na pergunta e na resposta.",
+		Define: defineTexto(func(s *Settings, valor string) {
+			s.IDIOMA = valor
+		}),
+	})
+	registraOpcao(&Opcao{
+		Nome:      "tts",
+		Descricao: "Ativa (\033[36m--tts\033[m) ou desativa (\033[36m--tts=false\033[m) a narração, em vez do parâmetro TTS.",
+		Define: func(sessao *Sessao, valor string) error {
+			b := valor == "true"
+			sessao.sobrepoe(func(s *Settings) { s.TTS = b })
+			return nil
+		},
+	})
+	registraOpcao(&Opcao{
+		Nome:      "timeout",
+		Argumento: "<segundos>",
+		Descricao: "Tempo máximo de espera pela resposta, em vez do parâmetro TIMEOUT.",
+		Define: func(sessao *Sessao, valor string) error {
+			t, err := strconv.Atoi(valor)
+			if err != nil || t <= 0 {
+				return novoErro("informe a quantidade de segundos (maior que zero)")
+			}
+			sessao.sobrepoe(func(s *Settings) { s.TIMEOUT = t })
+			return nil
+		},
+	})
+	registraOpcao(&Opcao{
+		Nome:      "export",
+		Argumento: "<arquivo>",
+		Descricao: "Grava a conversa no arquivo informado após cada resposta.\n" +
+			"O formato é definido pela extensão: .md, .html ou .json (ex: \033[36m--export conversa.md\033[m).",
+		Define: func(sessao *Sessao, valor string) error {
+			sessao.arquivoExport = valor
+			return nil
+		},
+	})
+	registraOpcao(&Opcao{
+		Nome:      "save-audio",
+		Argumento: "<arquivo>",
+		Descricao: "Grava a narração de cada resposta no arquivo informado (.mp3 ou .wav).",
+		Define: func(sessao *Sessao, valor string) error {
+			sessao.arquivoAudio = valor
+			return nil
+		},
+	})
+	registraOpcao(&Opcao{
+		Nome:      "history",
+		Argumento: "<arquivo>",
+		Descricao: "Inicia a conversa com o histórico gravado no arquivo informado\n" +
+			"(array JSON de mensagens role/content ou transcrição em Markdown).",
+		Define: func(sessao *Sessao, valor string) error {
+			n, err := sessao.importaHistorico(valor)
+			if err == nil {
+				sessao.tela.Printf(traduz("%d mensagens carregadas de \"%s\"\r\n"), n, valor)
+			}
+			return err
+		},
+	})
+	registraOpcao(&Opcao{
+		Nome:      "template",
+		Argumento: "<nome>",
+		Descricao: "Monta a pergunta a partir de um template da pasta ./templates.\n" +
+			"Os demais argumentos no formato chave=valor preenchem as variáveis\n" +
+			"(ex: \033[36m--template traducao idioma=inglês Bom dia\033[m).",
+		Define: func(sessao *Sessao, valor string) error {
+			sessao.nomeTemplate = valor
+			return nil
+		},
+	})
+
+	registraSubcomando(&Subcomando{
+		Nome:       "ask",
+		Argumentos: "<pergunta>",
+		Descricao:  "Faz uma única pergunta e encerra (padrão, se a pergunta for informada).",
+		Executa: func(sessao *Sessao, args []string) (string, error) {
+			sessao.interativo = false
+			pergunta, err := sessao.montaPergunta(args)
+			if err == nil && pergunta == "" && !sessao.saidaJson {
+				err = novoErro("informe a pergunta (ex: gpt ask \"Qual a capital do Brasil?\")")
+			}
+			return pergunta, err
+		},
+	})
+	registraSubcomando(&Subcomando{
+		Nome:       "chat",
+		Argumentos: "[pergunta]",
+		Descricao:  "Inicia o modo interativo, que mantém o histórico da conversa (padrão, sem argumentos).",
+		Executa: func(sessao *Sessao, args []string) (string, error) {
+			sessao.interativo = true
+			return sessao.montaPergunta(args)
+		},
+	})
+	registraSubcomando(&Subcomando{
+		Nome:       "config",
+		Argumentos: "[param=valor ...]",
+		Descricao: "Exibe as configurações ou altera e grava os parâmetros informados\n" +
+			"no arquivo settings.json (ex: \033[36mgpt config tts=false lang=en-us\033[m).",
+		Executa: func(sessao *Sessao, args []string) (string, error) {
+			sessao.encerrada = true
+			if len(args) == 0 {
+				sessao.printSettings()
+				return "", nil
+			}
+			mudou := false
+			for _, arg := range args {
+				if !strings.Contains(arg, "=") {
+					return "", novoErro("parâmetro \"%s\" inválido. Use: gpt config param=valor", arg)
+				}
+				if sessao.trataComandoSet(arg) {
+					mudou = true
+				}
+				sessao.tela.Println()
+			}
+			if mudou {
+				sessao.gravaSettings()
+			}
+			return "", nil
+		},
+	})
+}
+
+// Adiciona o parâmetro ao registro. Aborta se o nome já estiver em uso, pois trata-se de erro de programação.
+func registraOpcao(o *Opcao) {
+	if _, existe := opcoesPorNome[o.Nome]; existe {
+		panic(fmt.Sprintf("parâmetro \"--%s\" registrado em duplicidade", o.Nome))
+	}
+	opcoesPorNome[o.Nome] = o
+	opcoes = append(opcoes, o)
+}
+
+// Adiciona o subcomando ao registro. Aborta se o nome ou algum alias já estiver em uso.
+func registraSubcomando(s *Subcomando) {
+	for _, nome := range append([]string{s.Nome}, s.Aliases...) {
+		if _, existe := subcomandosPorNome[nome]; existe {
+			panic(fmt.Sprintf("subcomando \"%s\" registrado em duplicidade", nome))
+		}
+		subcomandosPorNome[nome] = s
+	}
+	subcomandos = append(subcomandos, s)
+}
+
+// Retorna a função que define um parâmetro booleano no campo da sessão informado.
+func defineBooleano(campo func(s *Sessao) *bool) func(sessao *Sessao, valor string) error {
+	return func(sessao *Sessao, valor string) error {
+		*campo(sessao) = valor == "true"
+		return nil
+	}
+}
+
+// Retorna a função que define um parâmetro de texto nas configurações. O valor não pode ser vazio.
+func defineTexto(f func(s *Settings, valor string)) func(sessao *Sessao, valor string) error {
+	return func(sessao *Sessao, valor string) error {
+		if strings.TrimSpace(valor) == "" {
+			return novoErro("o valor não pode ser vazio")
+		}
+		sessao.sobrepoe(func(s *Settings) { f(s, valor) })
+		return nil
+	}
+}
+
+// Altera as configurações atuais, sem gravar no arquivo settings.json. A alteração é guardada
+// para ser reaplicada se as configurações forem recarregadas.
+func (sessao *Sessao) sobrepoe(f func(s *Settings)) {
+	sessao.sobreposicoes = append(sessao.sobreposicoes, f)
+	f(&sessao.carregadas)
+
+	sessao.mutexSettings.Lock()
+	f(sessao.settings)
+	sessao.mutexSettings.Unlock()
+	sessao.sincronizaControles()
+}
+
+// Interpreta a linha de comando (sem o nome do programa): aplica os parâmetros e executa o subcomando.
+// Retorna a pergunta a ser enviada à IA, se houver.
+func (sessao *Sessao) interpretaLinhaDeComando(args []string) (string, error) {
+	posicionais, fimDasOpcoes, err := sessao.interpretaOpcoes(args)
+	if err != nil {
+		return "", err
+	}
+
+	if sessao.pediuAjuda {
+		sessao.printHelp()
+		sessao.encerrada = true
+		return "", nil
+	}
+
+	// O subcomando é o primeiro argumento, se não vier depois do "--".
+	var sub *Subcomando
+	if len(posicionais) > 0 && fimDasOpcoes > 0 {
+		if sub = subcomandosPorNome[posicionais[0]]; sub != nil {
+			posicionais = posicionais[1:]
+		}
+	}
+
+	// Verifica se os parâmetros informados pertencem ao subcomando.
+	for _, arg := range args[:indiceFimDasOpcoes(args)] {
+		if o := opcaoDoArgumento(arg); o != nil && o.Subcomando != "" && (sub == nil || sub.Nome != o.Subcomando) {
+			return "", novoErro("o parâmetro \"--%s\" só pode ser usado no subcomando \"%s\"", o.Nome, o.Subcomando)
+		}
+	}
+
+	if sub != nil {
+		return sub.Executa(sessao, posicionais)
+	}
+
+	// Sem subcomando: com a pergunta, faz uma única pergunta (ou inicia o modo interativo com ela,
+	// se o parâmetro --interativo foi informado). Sem a pergunta, inicia o modo interativo.
+	if sessao.interativo || (len(posicionais) == 0 && sessao.nomeTemplate == "" && !sessao.saidaJson) {
+		return subcomandosPorNome["chat"].Executa(sessao, posicionais)
+	}
+	return subcomandosPorNome["ask"].Executa(sessao, posicionais)
+}
+
+// Separa os parâmetros (aplicando-os) dos demais argumentos, que são retornados na ordem informada.
+// Também retorna a quantidade de argumentos que vieram antes do "--" (que encerra os parâmetros);
+// se não houver "--", retorna a quantidade total de argumentos.
+func (sessao *Sessao) interpretaOpcoes(args []string) ([]string, int, error) {
+	posicionais := make([]string, 0, len(args))
+	fimDasOpcoes := -1
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" && fimDasOpcoes < 0 {
+			fimDasOpcoes = len(posicionais)
+			continue
+		}
+		if fimDasOpcoes >= 0 || !strings.HasPrefix(arg, "-") || arg == "-" {
+			posicionais = append(posicionais, arg)
+			continue
+		}
+
+		nome, valor, temValor := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		o := opcoesPorNome[nome]
+		if o == nil {
+			return nil, 0, erroOpcaoDesconhecida(arg, nome)
+		}
+
+		if o.Argumento == "" {
+			// Parâmetro booleano: o valor é opcional (ex: --tts=false).
+			if !temValor {
+				valor = "true"
+			}
+			b, err := strconv.ParseBool(valor)
+			if err != nil {
+				return nil, 0, novoErro("valor \"%s\" inválido para o parâmetro \"--%s\": use true ou false", valor, o.Nome)
+			}
+			valor = strconv.FormatBool(b)
+		} else if !temValor {
+			if i+1 >= len(args) {
+				return nil, 0, novoErro("o parâmetro \"--%s\" requer um valor: --%s %s", o.Nome, o.Nome, traduz(o.Argumento))
+			}
+			i++
+			valor = args[i]
+		}
+
+		if err := o.Define(sessao, valor); err != nil {
+			return nil, 0, novoErro("parâmetro \"--%s\": %w", o.Nome, err)
+		}
+	}
+
+	if fimDasOpcoes < 0 {
+		fimDasOpcoes = len(posicionais)
+	}
+	return posicionais, fimDasOpcoes, nil
+}
+
+// Posição do "--" nos argumentos, ou o total de argumentos, se não houver.
+func indiceFimDasOpcoes(args []string) int {
+	for i, arg := range args {
+		if arg == "--" {
+			return i
+		}
+	}
+	return len(args)
+}
+
+// Parâmetro correspondente ao argumento (ex: "--model=gpt-4"), ou nil se não for um parâmetro.
+func opcaoDoArgumento(arg string) *Opcao {
+	if !strings.HasPrefix(arg, "-") {
+		return nil
+	}
+	nome, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+	return opcoesPorNome[nome]
+}
+
+// Erro do parâmetro inexistente, sugerindo o parâmetro de nome mais parecido (ex: --nosleeep).
+func erroOpcaoDesconhecida(arg, nome string) error {
+	// A sugestão deve manter parte do nome digitado: "-5" não sugere "--to", que difere em tudo.
+	sugestao, menor := "", 3
+	if n := len([]rune(nome)); n < menor {
+		menor = n
+	}
+	for _, o := range opcoes {
+		if d := distancia(nome, o.Nome); d < menor {
+			sugestao, menor = o.Nome, d
+		}
+	}
+	if sugestao != "" {
+		return novoErro("parâmetro \"%s\" desconhecido. Você quis dizer \"--%s\"?", arg, sugestao)
+	}
+	return novoErro("parâmetro \"%s\" desconhecido. Para enviar um texto iniciado por \"-\", use \"--\" antes do texto", arg)
+}
+
+// Distância de edição (Levenshtein) entre os textos: a quantidade mínima de inserções,
+// remoções e trocas de caracteres para transformar um texto no outro.
+func distancia(a, b string) int {
+	x, y := []rune(a), []rune(b)
+	anterior := make([]int, len(y)+1)
+	atual := make([]int, len(y)+1)
+	for j := range anterior {
+		anterior[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		atual[0] = i
+		for j := 1; j <= len(y); j++ {
+			custo := 1
+			if x[i-1] == y[j-1] {
+				custo = 0
+			}
+			atual[j] = menorDe(anterior[j]+1, atual[j-1]+1, anterior[j-1]+custo)
+		}
+		anterior, atual = atual, anterior
+	}
+	return anterior[len(y)]
+}
+
+func menorDe(valores ...int) int {
+	m := valores[0]
+	for _, v := range valores[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// Monta a pergunta com os argumentos. Se o parâmetro --template foi informado, os argumentos
+// são as variáveis do template (chave=valor) e o texto livre.
+func (sessao *Sessao) montaPergunta(args []string) (string, error) {
+	if sessao.nomeTemplate == "" {
+		return strings.TrimSpace(strings.Join(args, " ")), nil
+	}
+	pergunta, err := aplicaTemplate(sessao.nomeTemplate, args)
+	sessao.nomeTemplate = ""
+	return pergunta, err
+}
+
+// Imprime a ajuda dos subcomandos e dos parâmetros da linha de comando.
+func (sessao *Sessao) printHelpLinhaDeComando() {
+	sessao.tela.Println(traduz("Uso: \033[96mgpt [subcomando] [parâmetros] [texto]\033[m"))
+	sessao.tela.Println(traduz("\r\nSubcomandos:"))
+	for _, s := range subcomandos {
+		sessao.imprimeItemAjuda(strings.TrimSpace(s.Nome+" "+traduz(s.Argumentos)), traduz(s.Descricao))
+	}
+
+	sessao.tela.Println(traduz("\r\nParâmetros (use \033[36m--\033[m para encerrar os parâmetros e enviar o restante como texto):"))
+	for _, o := range opcoes {
+		nome := "--" + o.Nome
+		if o.Argumento != "" {
+			nome += " " + traduz(o.Argumento)
+		}
+		descricao := traduz(o.Descricao)
+		if o.Padrao != "" {
+			descricao += " " + traduzf("(padrão: %s).", o.Padrao)
+		}
+		sessao.imprimeItemAjuda(nome, descricao)
+	}
+}
+
+// Imprime o item da ajuda, alinhando as linhas da descrição.
+func (sessao *Sessao) imprimeItemAjuda(nome, descricao string) {
+	linhas := strings.Split(descricao, "\n")
+	sessao.tela.Printf("\t\033[36m%-26s\033[m %s\r\n", nome, linhas[0])
+	for _, l := range linhas[1:] {
+		sessao.tela.Printf("\t%-26s %s\r\n", "", l)
+	}
+}
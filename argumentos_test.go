@@ -0,0 +1,249 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInterpretaLinhaDeComando(t *testing.T) {
+	testes := []struct {
+		nome     string
+		args     []string
+		pergunta string
+		erro     string
+		saida    string
+		verifica func(sessao *Sessao) bool
+	}{
+		{nome: "pergunta sem subcomando", args: []string{"Qual", "a", "capital?"}, pergunta: "Qual a capital?",
+			verifica: func(sessao *Sessao) bool { return !sessao.interativo }},
+		{nome: "sem argumentos", args: []string{}, verifica: func(sessao *Sessao) bool { return sessao.interativo }},
+		{nome: "parâmetros depois da pergunta", args: []string{"Olá", "-nosleep", "--printjson"}, pergunta: "Olá",
+			verifica: func(sessao *Sessao) bool { return sessao.noSleep && sessao.printJson }},
+		{nome: "ask", args: []string{"ask", "--nosleep", "Olá"}, pergunta: "Olá", verifica: func(sessao *Sessao) bool { return sessao.noSleep && !sessao.interativo }},
+		{nome: "ask sem pergunta", args: []string{"ask"}, erro: "informe a pergunta"},
+		{nome: "chat com pergunta", args: []string{"chat", "Olá"}, pergunta: "Olá", verifica: func(sessao *Sessao) bool { return sessao.interativo }},
+		{nome: "--interativo", args: []string{"--interativo", "Olá"}, pergunta: "Olá", verifica: func(sessao *Sessao) bool { return sessao.interativo }},
+		{nome: "-- encerra os parâmetros", args: []string{"ask", "--", "--nosleep", "é", "parâmetro?"}, pergunta: "--nosleep é parâmetro?",
+			verifica: func(sessao *Sessao) bool { return !sessao.noSleep }},
+		{nome: "subcomando depois do --", args: []string{"--", "chat"}, pergunta: "chat", verifica: func(sessao *Sessao) bool { return !sessao.interativo }},
+		{nome: "sobreposições", args: []string{"--model", "gpt-4", "--temperature=0.7", "--lang", "en-us", "--tts=false", "--timeout", "5", "Olá"},
+			pergunta: "Olá", verifica: func(sessao *Sessao) bool {
+				return sessao.settings.GPT_MODEL == "gpt-4" && sessao.settings.TEMPERATURE == 0.7 && sessao.settings.IDIOMA == "en-us" && !sessao.settings.TTS && sessao.settings.TIMEOUT == 5
+			}},
+		{nome: "--tts", args: []string{"--tts", "Olá"}, pergunta: "Olá", verifica: func(sessao *Sessao) bool { return sessao.settings.TTS }},
+		{nome: "parâmetro com erro de digitação", args: []string{"--nosleeep", "Olá"}, erro: `"--nosleeep" desconhecido. Você quis dizer "--nosleep"?`},
+		{nome: "parâmetro desconhecido", args: []string{"-5", "mais", "3"}, erro: `use "--" antes do texto`},
+		{nome: "parâmetro sem valor", args: []string{"Olá", "--model"}, erro: `"--model" requer um valor: --model <modelo>`},
+		{nome: "valor vazio", args: []string{"--lang=", "Olá"}, erro: "não pode ser vazio"},
+		{nome: "temperatura inválida", args: []string{"--temperature", "3", "Olá"}, erro: "entre 0.0 e 2.0"},
+		{nome: "timeout inválido", args: []string{"--timeout", "0", "Olá"}, erro: "maior que zero"},
+		{nome: "booleano inválido", args: []string{"--nosleep=talvez", "Olá"}, erro: "use true ou false"},
+		{nome: "parâmetro de outro subcomando", args: []string{"--addr", ":9000", "Olá"}, erro: `só pode ser usado no subcomando "serve"`},
+		{nome: "help", args: []string{"--help"}, saida: "Subcomandos:", verifica: func(sessao *Sessao) bool { return sessao.encerrada }},
+		{nome: "help com valor padrão", args: []string{"--help"}, saida: "serve\033[m (padrão: " + ENDERECO_SERVIDOR + ")."},
+		{nome: "config sem argumentos", args: []string{"config"}, saida: "gpt-teste", verifica: func(sessao *Sessao) bool { return sessao.encerrada }},
+		{nome: "config", args: []string{"config", "tts=false", "lang=en-us"}, verifica: func(sessao *Sessao) bool {
+			gravado, _ := os.ReadFile(SETTINGS)
+			return sessao.encerrada && !sessao.settings.TTS && strings.Contains(string(gravado), `"IDIOMA": "en-us"`)
+		}},
+		{nome: "config inválido", args: []string{"config", "tts"}, erro: `"tts" inválido`},
+		{nome: "sessions", args: []string{"sessions"}, saida: "Nenhuma conversa gravada.", verifica: func(sessao *Sessao) bool { return sessao.encerrada }},
+		{nome: "search", args: []string{"search", "go"}, saida: "Nenhuma conversa encontrada.", verifica: func(sessao *Sessao) bool { return sessao.encerrada }},
+		{nome: "speak sem texto", args: []string{"speak"}, erro: "informe o texto"},
+		{nome: "serve com argumentos", args: []string{"serve", "8080"}, erro: "--addr"},
+		{nome: "translate", args: []string{"translate", "Bom", "dia", "--to=en-us"}, pergunta: "Bom dia",
+			verifica: func(sessao *Sessao) bool {
+				return sessao.modoTraducao && !sessao.interativo && sessao.idiomaDestino == "en-us" && sessao.idiomaOrigem == ""
+			}},
+		{nome: "translate sem texto", args: []string{"translate", "--from", "auto", "--to", "pt"}, verifica: func(sessao *Sessao) bool {
+			return sessao.modoTraducao && sessao.interativo && sessao.idiomaDestino == "pt"
+		}},
+		{nome: "--to fora do translate", args: []string{"--to", "en", "Olá"}, erro: `só pode ser usado no subcomando "translate"`},
+		{nome: "erro de digitação curto", args: []string{"--tp", "en", "Olá"}, erro: `Você quis dizer "--to"?`},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			sessao := configuraTeste(t)
+
+			var pergunta string
+			var err error
+			saida := capturaSaida(t, sessao, func() { pergunta, err = sessao.interpretaLinhaDeComando(tt.args) })
+
+			if tt.erro != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.erro) {
+					t.Fatalf("erro = %v, esperado conter %q", err, tt.erro)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pergunta != tt.pergunta {
+				t.Errorf("pergunta = %q, esperado %q", pergunta, tt.pergunta)
+			}
+			if !strings.Contains(saida, tt.saida) {
+				t.Errorf("saída = %q, esperado conter %q", saida, tt.saida)
+			}
+			if tt.verifica != nil && !tt.verifica(sessao) {
+				t.Error("parâmetros não foram aplicados")
+			}
+		})
+	}
+}
+
+// Os parâmetros da linha de comando continuam valendo após recarregar o settings.json (ex: "/reset").
+func TestSobreposicoesAoRecarregar(t *testing.T) {
+	sessao := configuraTeste(t)
+	if err := os.WriteFile(SETTINGS, []byte(`{"GPT_MODEL": "gpt-arquivo", "IDIOMA": "pt-br", "TIMEOUT": 30}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	capturaSaida(t, sessao, func() {
+		if _, err := sessao.interpretaLinhaDeComando([]string{"--model", "gpt-4", "Olá"}); err != nil {
+			t.Fatal(err)
+		}
+		sessao.carregaConfiguracoes()
+	})
+
+	if sessao.settings.GPT_MODEL != "gpt-4" || sessao.settings.TIMEOUT != 30 {
+		t.Errorf("GPT_MODEL = %q, TIMEOUT = %d; esperado gpt-4 e 30", sessao.settings.GPT_MODEL, sessao.settings.TIMEOUT)
+	}
+}
+
+// Os parâmetros da linha de comando não são gravados no settings.json pelos comandos que o alteram.
+func TestSobreposicoesNaoSaoGravadas(t *testing.T) {
+	testes := []struct {
+		nome    string
+		args    []string
+		comando string
+	}{
+		{"config", []string{"--model", "gpt-4", "--timeout", "5", "config", "tts=false"}, ""},
+		{"/set", []string{"--model", "gpt-4", "--timeout", "5", "--interativo"}, "/set tts=false"},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			sessao := configuraTeste(t)
+			original := `{"GPT_MODEL": "gpt-arquivo", "IDIOMA": "pt-br", "TTS": true, "TIMEOUT": 30, "VOZES": {"openai": {"pt": "nova"}}}`
+			if err := os.WriteFile(SETTINGS, []byte(original), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			capturaSaida(t, sessao, func() {
+				sessao.carregaConfiguracoes()
+				if _, err := sessao.interpretaLinhaDeComando(tt.args); err != nil {
+					t.Fatal(err)
+				}
+				if tt.comando != "" {
+					sessao.executaComando(tt.comando)
+				}
+			})
+			if sessao.settings.GPT_MODEL != "gpt-4" || sessao.settings.TTS {
+				t.Fatalf("GPT_MODEL = %q, TTS = %v; esperado gpt-4 e false", sessao.settings.GPT_MODEL, sessao.settings.TTS)
+			}
+
+			// O arquivo gravado é o original, somente com o TTS alterado.
+			esperado := Settings{VOLUME: VOLUME_MAXIMO, VELOCIDADE: 1}
+			json.Unmarshal([]byte(original), &esperado)
+			esperado.TTS = false
+			gravado := Settings{}
+			conteudo, err := os.ReadFile(SETTINGS)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = json.Unmarshal(conteudo, &gravado); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gravado, esperado) {
+				t.Errorf("settings.json gravado = %+v, esperado %+v", gravado, esperado)
+			}
+		})
+	}
+}
+
+// Linha de comando inválida: informa o erro e encerra com o código 2, sem perguntar nada.
+func TestLinhaDeComandoInvalida(t *testing.T) {
+	f, sessao := novoServidorFake(t)
+	sessao.argumentos = []string{"gpt", "--nosleeep", "Olá"}
+
+	saida := capturaSaida(t, sessao, sessao.executaSessao)
+	if !strings.Contains(saida, `Você quis dizer "--nosleep"?`) || !strings.Contains(saida, "gpt --help") {
+		t.Errorf("saída = %q", saida)
+	}
+	if sessao.codigoSaida != SAIDA_USO_INVALIDO {
+		t.Errorf("código de saída = %d, esperado %d", sessao.codigoSaida, SAIDA_USO_INVALIDO)
+	}
+	if n := len(f.Requisicoes()); n != 0 {
+		t.Errorf("%d perguntas enviadas, esperado nenhuma", n)
+	}
+}
+
+// Pergunta ou tradução da linha de comando sem resposta: encerra com o código 1, sem entrar
+// no modo interativo.
+func TestFalhaForaDoModoInterativo(t *testing.T) {
+	tests := [][]string{
+		{"gpt", "ask", "Olá"},
+		{"gpt", "translate", "--to", "en-us", "Olá"},
+	}
+
+	for _, args := range tests {
+		t.Run(strings.Join(args[1:], " "), func(t *testing.T) {
+			f, sessao := novoServidorFake(t)
+			f.ErroChat = "modelo inexistente"
+			sessao.argumentos = args
+			entradaTeste(sessao, "Outra pergunta", "/quit")
+
+			capturaSaida(t, sessao, sessao.executaSessao)
+			if sessao.codigoSaida != 1 {
+				t.Errorf("código de saída = %d, esperado 1", sessao.codigoSaida)
+			}
+			if n := len(f.Requisicoes()); n != 1 {
+				t.Errorf("%d requisições enviadas, esperado 1", n)
+			}
+		})
+	}
+}
+
+func TestDistancia(t *testing.T) {
+	testes := []struct {
+		a, b string
+		d    int
+	}{
+		{"nosleep", "nosleep", 0},
+		{"nosleeep", "nosleep", 1},
+		{"modle", "model", 2},
+		{"idioma", "", 6},
+		{"ação", "acao", 2},
+	}
+	for _, tt := range testes {
+		if d := distancia(tt.a, tt.b); d != tt.d {
+			t.Errorf("distancia(%q, %q) = %d, esperado %d", tt.a, tt.b, d, tt.d)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// Tamanho máximo (em caracteres) de cada bloco de texto enviado para o TTS.
+	// Acima disso, o audio do Google é truncado.
+	TAMANHO_BLOCO = 100
+)
+
+// Divide o texto em blocos de até "limite" caracteres (runas, e não bytes, para contar
+// corretamente os caracteres acentuados). As frases inteiras são agrupadas no mesmo bloco
+// enquanto couberem. Uma frase maior que o limite é quebrada nas vírgulas, ponto-e-vírgulas
+// e dois-pontos; se ainda assim não couber, entre as palavras; e uma palavra maior que
+// o limite (ex: URLs) é cortada no limite.
+func divideEmBlocos(texto string, limite int) []string {
+	blocos := make([]string, 0)
+	atual := ""
+
+	for _, frase := range divideFrases(texto) {
+		for _, parte := range quebraFrase(frase, limite) {
+			switch {
+			case atual == "":
+				atual = parte
+			case tamanho(atual)+1+tamanho(parte) <= limite:
+				atual += " " + parte
+			default:
+				blocos = append(blocos, atual)
+				atual = parte
+			}
+		}
+	}
+
+	if atual != "" {
+		blocos = append(blocos, atual)
+	}
+	return blocos
+}
+
+// Separa o texto em frases. A frase termina em ".", "!", "?", "…" ou ";" seguido de espaço,
+// ou em uma quebra de linha (títulos e itens de lista também são tratados como frases).
+// O ponto seguido de número ou letra (ex: "3.14", "www.site.com") não termina a frase.
+func divideFrases(texto string) []string {
+	frases := make([]string, 0)
+	runas := []rune(texto)
+	inicio := 0
+
+	adiciona := func(fim int) {
+		if frase := strings.Join(strings.Fields(string(runas[inicio:fim])), " "); frase != "" {
+			frases = append(frases, frase)
+		}
+		inicio = fim
+	}
+
+	for i, c := range runas {
+		switch {
+		case c == '\n':
+			adiciona(i + 1)
+		case strings.ContainsRune(".!?…;", c) && (i+1 == len(runas) || unicode.IsSpace(runas[i+1])):
+			adiciona(i + 1)
+		}
+	}
+	adiciona(len(runas))
+
+	return frases
+}
+
+// Quebra a frase em partes de até "limite" caracteres, preferindo as pausas naturais.
+func quebraFrase(frase string, limite int) []string {
+	if tamanho(frase) <= limite {
+		return []string{frase}
+	}
+
+	partes := make([]string, 0)
+	for _, oracao := range divideOracoes(frase) {
+		if tamanho(oracao) <= limite {
+			partes = append(partes, oracao)
+			continue
+		}
+
+		// A oração não cabe: quebra entre as palavras, cortando as palavras maiores que o limite.
+		for _, palavra := range strings.Fields(oracao) {
+			for tamanho(palavra) > limite {
+				corte := indiceDaRuna(palavra, limite)
+				partes = append(partes, palavra[:corte])
+				palavra = palavra[corte:]
+			}
+			partes = append(partes, palavra)
+		}
+	}
+
+	// Junta as partes pequenas enquanto couberem no limite.
+	return divideEmBlocos(strings.Join(partes, "\n"), limite)
+}
+
+// Separa a frase nas vírgulas, ponto-e-vírgulas, dois-pontos e travessões seguidos de espaço.
+func divideOracoes(frase string) []string {
+	oracoes := make([]string, 0)
+	runas := []rune(frase)
+	inicio := 0
+
+	for i, c := range runas {
+		if strings.ContainsRune(",;:—", c) && i+1 < len(runas) && unicode.IsSpace(runas[i+1]) {
+			oracoes = append(oracoes, strings.TrimSpace(string(runas[inicio:i+1])))
+			inicio = i + 1
+		}
+	}
+
+	if resto := strings.TrimSpace(string(runas[inicio:])); resto != "" {
+		oracoes = append(oracoes, resto)
+	}
+	return oracoes
+}
+
+// Quantidade de caracteres (runas) do texto.
+func tamanho(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// Retorna a posição, em bytes, da n-ésima runa do texto.
+func indiceDaRuna(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(s)
+}
@@ -0,0 +1,106 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDivideEmBlocos(t *testing.T) {
+	testes := []struct {
+		nome   string
+		texto  string
+		limite int
+		blocos []string
+	}{
+		{"vazio", "", 20, []string{}},
+		{"cabe em um bloco", "Olá, mundo! Tudo bem?", 100, []string{"Olá, mundo! Tudo bem?"}},
+		{"agrupa frases", "Um. Dois. Três. Quatro.", 10, []string{"Um. Dois.", "Três.", "Quatro."}},
+		{"quebra nas vírgulas", "Primeiro item, segundo item, terceiro item.", 30, []string{"Primeiro item, segundo item,", "terceiro item."}},
+		{"quebra entre palavras", "palavra palavra palavra palavra", 16, []string{"palavra palavra", "palavra palavra"}},
+		{"corta palavra maior que o limite", "abcdefghijklmnop", 6, []string{"abcdef", "ghijkl", "mnop"}},
+		{"conta caracteres acentuados", "ção ção ção", 7, []string{"ção ção", "ção"}},
+		{"quebra de linha termina a frase", "Título\nTexto do item", 100, []string{"Título Texto do item"}},
+		{"ponto em número não termina a frase", "Pi vale 3.14 aproximadamente.", 12, []string{"Pi vale 3.14", "aproximadame", "nte."}},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			blocos := divideEmBlocos(tt.texto, tt.limite)
+			if !reflect.DeepEqual(blocos, tt.blocos) {
+				t.Errorf("divideEmBlocos(%q, %d) = %q, esperado %q", tt.texto, tt.limite, blocos, tt.blocos)
+			}
+			for _, b := range blocos {
+				if tamanho(b) > tt.limite {
+					t.Errorf("bloco %q maior que o limite %d", b, tt.limite)
+				}
+			}
+		})
+	}
+}
+
+// Os blocos de um texto longo respeitam o limite do TTS e mantêm todas as palavras, na ordem.
+func TestDivideEmBlocosTextoLongo(t *testing.T) {
+	texto := strings.Repeat("Esta é uma frase de tamanho médio, com uma vírgula no meio. ", 20) +
+		"https://exemplo.com/" + strings.Repeat("caminho/", 20)
+
+	blocos := divideEmBlocos(texto, TAMANHO_BLOCO)
+	for _, b := range blocos {
+		if tamanho(b) > TAMANHO_BLOCO {
+			t.Errorf("bloco com %d caracteres: %q", tamanho(b), b)
+		}
+	}
+	if got, esperado := strings.Join(strings.Fields(strings.Join(blocos, " ")), ""), strings.Join(strings.Fields(texto), ""); got != esperado {
+		t.Errorf("o texto dos blocos difere do original:\n%s\n%s", got, esperado)
+	}
+}
+
+func TestBlocosParaFala(t *testing.T) {
+	testes := []struct {
+		nome   string
+		idioma string
+		texto  string
+		blocos []string
+	}{
+		{"texto simples", "pt-BR", "Olá, mundo! Tudo bem?", []string{"Olá, mundo! Tudo bem?"}},
+		{"remove a formatação", "pt-BR", "**Negrito** e `código` com 3 itens.\n- Item um\n- Item dois", []string{"Negrito e código com três itens. Item um Item dois"}},
+		{"bloco de código", "pt-BR", "Veja:\n```go\nfmt.Println(1)\n```\nPronto.", []string{"Veja: Segue um trecho de código em go. Pronto."}},
+		{"URL", "pt-BR", "Visite https://golang.org/doc agora.", []string{"Visite link para golang.org agora."}},
+		{"abreviação e número", "pt-BR", "Dr. Silva tem 25 anos.", []string{"doutor Silva tem vinte e cinco anos."}},
+		{"número em inglês", "en-US", "I have 25 apples.", []string{"I have twenty-five apples."}},
+		{"somente código", "pt-BR", "```\nx := 1\n```", []string{"Segue um trecho de código."}},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			sessao := configuraTeste(t)
+			sessao.settings.IDIOMA = tt.idioma
+
+			if blocos := blocosParaFala(tt.texto, sessao.settings.IDIOMA); !reflect.DeepEqual(blocos, tt.blocos) {
+				t.Errorf("blocosParaFala(%q) = %q, esperado %q", tt.texto, blocos, tt.blocos)
+			}
+		})
+	}
+}
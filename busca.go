@@ -0,0 +1,394 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type (
+	// Turno de uma conversa gravada onde o termo foi encontrado.
+	Ocorrencia struct {
+		Arquivo string `json:"a"` // Arquivo da conversa na pasta ./conversas
+		Turno   int    `json:"t"` // Posição da mensagem na conversa.
+		Vezes   int    `json:"n"` // Quantidade de vezes que o termo aparece na mensagem.
+	}
+
+	// Índice invertido das conversas gravadas, mantido no arquivo ./conversas/indice.json
+	// para que a busca não precise ler todas as conversas.
+	IndiceBusca struct {
+		// Data de alteração (em nanosegundos) de cada arquivo indexado. Usada para
+		// reindexar os arquivos alterados ou removidos fora do aplicativo.
+		Arquivos map[string]int64 `json:"arquivos"`
+
+		// Ocorrências de cada termo. Os termos são gravados em minúsculas e sem acentos.
+		Termos map[string][]Ocorrencia `json:"termos"`
+	}
+
+	// Resultado da busca: um turno que contém todos os termos pesquisados.
+	ResultadoBusca struct {
+		Arquivo string
+		Turno   int
+		Pontos  int // Soma das ocorrências dos termos. Os resultados são ordenados por este campo.
+	}
+)
+
+const (
+	// Arquivo do índice de busca, dentro da pasta ./conversas
+	INDICE = "indice.json"
+
+	// Quantidade máxima de resultados exibidos pela busca.
+	MAX_RESULTADOS = 20
+
+	// Quantidade de caracteres exibidos antes e depois do termo encontrado.
+	TAMANHO_TRECHO = 60
+)
+
+// Tabela para remover os acentos dos termos indexados e pesquisados.
+var semAcentos = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+func init() {
+	registraComando(&Comando{
+		Nome:       "search",
+		Aliases:    []string{"busca"},
+		Argumentos: "<termos>",
+		Descricao:  "Procura os termos nas perguntas e respostas das conversas gravadas.",
+		MinArgs:    1,
+		MaxArgs:    -1,
+		Executa: func(sessao *Sessao, args []string) string {
+			sessao.buscaConversas(args)
+			return ""
+		},
+	})
+}
+
+// Procura os termos nas conversas gravadas e imprime os trechos encontrados, com os termos destacados.
+// Usado pelo comando "/search" e pelo subcomando "search" (ex: gpt search ponteiro golang).
+func (sessao *Sessao) buscaConversas(termos []string) {
+	indice, err := carregaIndice()
+	if err != nil {
+		sessao.tela.Erro(err)
+		return
+	}
+
+	termos = termosDoTexto(strings.Join(termos, " "))
+	if len(termos) == 0 {
+		sessao.tela.Println(traduz("\033[31mInforme ao menos um termo com 2 ou mais letras.\033[m"))
+		return
+	}
+
+	resultados := indice.Busca(termos)
+	if len(resultados) == 0 {
+		sessao.tela.Println(traduz("Nenhuma conversa encontrada."))
+		return
+	}
+
+	sessao.tela.Printf(traduz("%d resultado(s) encontrado(s)"), len(resultados))
+	if len(resultados) > MAX_RESULTADOS {
+		sessao.tela.Printf(traduz(". Exibindo os %d primeiros"), MAX_RESULTADOS)
+		resultados = resultados[:MAX_RESULTADOS]
+	}
+	sessao.tela.Println(":")
+
+	// Cada arquivo é lido uma única vez, mesmo que tenha mais de um resultado.
+	conversas := make(map[string][]Message)
+	for _, r := range resultados {
+		msgs, ok := conversas[r.Arquivo]
+		if !ok {
+			msgs, _ = leConversa(filepath.Join(CONVERSAS, r.Arquivo))
+			conversas[r.Arquivo] = msgs
+		}
+		if r.Turno >= len(msgs) {
+			continue
+		}
+
+		m := msgs[r.Turno]
+		sessao.tela.Printf("\r\n\033[94m%s\033[m \033[90m#%d %s\033[m\r\n", r.Arquivo, r.Turno+1, tituloDoTurno(m))
+		sessao.tela.Println(trechoDestacado(textoDaMensagem(m), termos))
+	}
+}
+
+// Retorna os turnos que contêm todos os termos. Um termo também é encontrado como prefixo
+// das palavras indexadas (ex: "program" encontra "programa" e "programação").
+func (indice *IndiceBusca) Busca(termos []string) []ResultadoBusca {
+	var pontos map[Ocorrencia]int
+
+	for _, termo := range termos {
+		encontrados := make(map[Ocorrencia]int)
+		for palavra, ocorrencias := range indice.Termos {
+			if !strings.HasPrefix(palavra, termo) {
+				continue
+			}
+			for _, o := range ocorrencias {
+				encontrados[Ocorrencia{Arquivo: o.Arquivo, Turno: o.Turno}] += o.Vezes
+			}
+		}
+
+		// Mantém apenas os turnos que contêm todos os termos.
+		if pontos == nil {
+			pontos = encontrados
+			continue
+		}
+		for o := range pontos {
+			if n, ok := encontrados[o]; ok {
+				pontos[o] += n
+			} else {
+				delete(pontos, o)
+			}
+		}
+	}
+
+	resultados := make([]ResultadoBusca, 0, len(pontos))
+	for o, n := range pontos {
+		resultados = append(resultados, ResultadoBusca{Arquivo: o.Arquivo, Turno: o.Turno, Pontos: n})
+	}
+
+	// Os mais relevantes primeiro. No empate, as conversas mais recentes (o nome do arquivo é a data).
+	sort.Slice(resultados, func(i, j int) bool {
+		a, b := resultados[i], resultados[j]
+		if a.Pontos != b.Pontos {
+			return a.Pontos > b.Pontos
+		}
+		if a.Arquivo != b.Arquivo {
+			return a.Arquivo > b.Arquivo
+		}
+		return a.Turno < b.Turno
+	})
+	return resultados
+}
+
+// Acrescenta (ou substitui) no índice os termos das mensagens da conversa gravada no arquivo.
+func (indice *IndiceBusca) Indexa(arquivo string, modificado int64, msgs []Message) {
+	indice.Remove(arquivo)
+	indice.Arquivos[arquivo] = modificado
+
+	for turno, m := range msgs {
+		contagem := make(map[string]int)
+		for _, termo := range termosDoTexto(textoDaMensagem(m)) {
+			contagem[termo]++
+		}
+		for termo, n := range contagem {
+			indice.Termos[termo] = append(indice.Termos[termo], Ocorrencia{Arquivo: arquivo, Turno: turno, Vezes: n})
+		}
+	}
+}
+
+// Remove do índice todas as ocorrências do arquivo.
+func (indice *IndiceBusca) Remove(arquivo string) {
+	if _, ok := indice.Arquivos[arquivo]; !ok {
+		return
+	}
+	delete(indice.Arquivos, arquivo)
+
+	for termo, ocorrencias := range indice.Termos {
+		restantes := ocorrencias[:0]
+		for _, o := range ocorrencias {
+			if o.Arquivo != arquivo {
+				restantes = append(restantes, o)
+			}
+		}
+		if len(restantes) == 0 {
+			delete(indice.Termos, termo)
+		} else {
+			indice.Termos[termo] = restantes
+		}
+	}
+}
+
+// Atualiza o índice com a conversa que acabou de ser gravada.
+func atualizaIndice(caminho string, msgs []Message) error {
+	indice, err := carregaIndice()
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(caminho)
+	if err != nil {
+		return err
+	}
+
+	indice.Indexa(filepath.Base(caminho), info.ModTime().UnixNano(), msgs)
+	return gravaIndice(indice)
+}
+
+// Lê o índice do arquivo ./conversas/indice.json e reindexa as conversas novas, alteradas
+// ou removidas desde a última atualização. Se houve alteração, grava o índice atualizado.
+func carregaIndice() (*IndiceBusca, error) {
+	indice := &IndiceBusca{}
+	if conteudo, err := os.ReadFile(filepath.Join(CONVERSAS, INDICE)); err == nil {
+		// Se o índice estiver corrompido, é recriado a partir das conversas.
+		json.Unmarshal(conteudo, indice)
+	}
+	if indice.Arquivos == nil || indice.Termos == nil {
+		indice.Arquivos = make(map[string]int64)
+		indice.Termos = make(map[string][]Ocorrencia)
+	}
+
+	arquivos, err := filepath.Glob(filepath.Join(CONVERSAS, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	alterou := false
+	existentes := make(map[string]bool)
+	for _, caminho := range arquivos {
+		nome := filepath.Base(caminho)
+		if nome == INDICE {
+			continue
+		}
+		existentes[nome] = true
+
+		info, err := os.Stat(caminho)
+		if err != nil || indice.Arquivos[nome] == info.ModTime().UnixNano() {
+			continue
+		}
+
+		msgs, err := leConversa(caminho)
+		if err != nil {
+			continue
+		}
+		indice.Indexa(nome, info.ModTime().UnixNano(), msgs)
+		alterou = true
+	}
+
+	for nome := range indice.Arquivos {
+		if !existentes[nome] {
+			indice.Remove(nome)
+			alterou = true
+		}
+	}
+
+	if alterou {
+		if err := gravaIndice(indice); err != nil {
+			return nil, err
+		}
+	}
+	return indice, nil
+}
+
+// Grava o índice no arquivo ./conversas/indice.json
+func gravaIndice(indice *IndiceBusca) error {
+	conteudo, err := json.Marshal(indice)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(CONVERSAS, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(CONVERSAS, INDICE), conteudo, 0600)
+}
+
+// Separa o texto em termos para indexação e busca: palavras com 2 ou mais caracteres,
+// em minúsculas e sem acentos.
+func termosDoTexto(s string) []string {
+	termos := make([]string, 0)
+	for _, palavra := range strings.FieldsFunc(s, separaPalavra) {
+		if termo := normalizaTermo(palavra); len([]rune(termo)) >= 2 {
+			termos = append(termos, termo)
+		}
+	}
+	return termos
+}
+
+// Indica se o caractere separa as palavras do texto (qualquer coisa que não seja letra ou número).
+func separaPalavra(c rune) bool {
+	return !unicode.IsLetter(c) && !unicode.IsNumber(c)
+}
+
+// Converte a palavra para minúsculas e remove os acentos.
+func normalizaTermo(palavra string) string {
+	return semAcentos.Replace(strings.ToLower(palavra))
+}
+
+// Retorna o trecho do texto em volta da primeira palavra encontrada, com as palavras
+// que começam com algum dos termos destacadas em amarelo.
+func trechoDestacado(texto string, termos []string) string {
+	runas := []rune(strings.Join(strings.Fields(texto), " "))
+
+	// Posições (inicio e fim) das palavras que começam com algum dos termos.
+	destaques := make([][2]int, 0)
+	for i := 0; i < len(runas); {
+		if separaPalavra(runas[i]) {
+			i++
+			continue
+		}
+		fim := i
+		for fim < len(runas) && !separaPalavra(runas[fim]) {
+			fim++
+		}
+		palavra := normalizaTermo(string(runas[i:fim]))
+		for _, termo := range termos {
+			if strings.HasPrefix(palavra, termo) {
+				destaques = append(destaques, [2]int{i, fim})
+				break
+			}
+		}
+		i = fim
+	}
+
+	inicio, fim := 0, len(runas)
+	if len(destaques) > 0 {
+		inicio, fim = destaques[0][0]-TAMANHO_TRECHO, destaques[0][1]+TAMANHO_TRECHO
+	} else {
+		fim = 2 * TAMANHO_TRECHO
+	}
+	if inicio < 0 {
+		inicio = 0
+	}
+	if fim > len(runas) {
+		fim = len(runas)
+	}
+
+	sb := &strings.Builder{}
+	if inicio > 0 {
+		sb.WriteString("...")
+	}
+
+	pos := inicio
+	for _, d := range destaques {
+		if d[0] < inicio || d[1] > fim {
+			continue
+		}
+		sb.WriteString(string(runas[pos:d[0]]))
+		sb.WriteString("\033[93m" + string(runas[d[0]:d[1]]) + "\033[m")
+		pos = d[1]
+	}
+	sb.WriteString(string(runas[pos:fim]))
+
+	if fim < len(runas) {
+		sb.WriteString("...")
+	}
+	return sb.String()
+}
@@ -0,0 +1,134 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIndiceBusca(t *testing.T) {
+	const (
+		GO     = "20240101-100000.json"
+		BRASIL = "20240202-100000.json"
+	)
+
+	indice := &IndiceBusca{Arquivos: make(map[string]int64), Termos: make(map[string][]Ocorrencia)}
+	indice.Indexa(GO, 1, []Message{
+		{Role: "user", Content: "Como programar em Go?"},
+		{Role: "assistant", Content: "Programação em Go usa goroutines. Go é simples."},
+	})
+	indice.Indexa(BRASIL, 1, []Message{
+		{Role: "user", Content: "Qual é a capital do Brasil? Seja simples."},
+		{Role: "assistant", Content: "A capital é Brasília. Brasília foi planejada."},
+	})
+	// Reindexar o mesmo arquivo substitui as ocorrências anteriores.
+	indice.Indexa(BRASIL, 2, []Message{
+		{Role: "user", Content: "Qual é a capital do Brasil? Seja simples."},
+		{Role: "assistant", Content: "A capital é Brasília. Brasília foi planejada."},
+	})
+
+	casos := []struct {
+		nome       string
+		termos     []string
+		resultados []ResultadoBusca
+	}{
+		{"prefixo", []string{"program"}, []ResultadoBusca{{GO, 0, 1}, {GO, 1, 1}}},
+		{"mais ocorrências primeiro", []string{"go"}, []ResultadoBusca{{GO, 1, 3}, {GO, 0, 1}}},
+		{"sem acentos", []string{"brasil"}, []ResultadoBusca{{BRASIL, 1, 2}, {BRASIL, 0, 1}}},
+		{"todos os termos", []string{"capital", "brasilia"}, []ResultadoBusca{{BRASIL, 1, 3}}},
+		{"empate, mais recente primeiro", []string{"simples"}, []ResultadoBusca{{BRASIL, 0, 1}, {GO, 1, 1}}},
+		{"termos em conversas diferentes", []string{"capital", "go"}, []ResultadoBusca{}},
+		{"não encontrado", []string{"rust"}, []ResultadoBusca{}},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if r := indice.Busca(c.termos); !reflect.DeepEqual(r, c.resultados) {
+				t.Errorf("Busca(%q) = %+v, esperado %+v", c.termos, r, c.resultados)
+			}
+		})
+	}
+
+	indice.Remove(GO)
+	if _, ok := indice.Arquivos[GO]; ok {
+		t.Errorf("%s continua no índice", GO)
+	}
+	for termo, ocorrencias := range indice.Termos {
+		for _, o := range ocorrencias {
+			if o.Arquivo == GO {
+				t.Errorf("termo %q continua apontando para %s", termo, GO)
+			}
+		}
+	}
+	if _, ok := indice.Termos["goroutines"]; ok {
+		t.Error("termo exclusivo do arquivo removido continua no índice")
+	}
+	if r := indice.Busca([]string{"simples"}); !reflect.DeepEqual(r, []ResultadoBusca{{BRASIL, 0, 1}}) {
+		t.Errorf("após remover %s, Busca = %+v", GO, r)
+	}
+	// Remover um arquivo que não está no índice não altera nada.
+	indice.Remove(GO)
+	if n := len(indice.Arquivos); n != 1 {
+		t.Errorf("%d arquivos no índice, esperado 1", n)
+	}
+}
+
+func TestTrechoDestacado(t *testing.T) {
+	const (
+		AMARELO = "\033[93m"
+		NORMAL  = "\033[m"
+	)
+	x := strings.Repeat("x", 200)
+	cedilhas := strings.Repeat("ç", 200)
+
+	casos := []struct {
+		nome     string
+		texto    string
+		termos   []string
+		esperado string
+	}{
+		{"texto curto", "A capital é Brasília.", []string{"brasil"}, "A capital é " + AMARELO + "Brasília" + NORMAL + "."},
+		{"espaços e quebras", "Go\n\n  é   simples", []string{"simp"}, "Go é " + AMARELO + "simples" + NORMAL},
+		{"vários destaques", "Go usa goroutines", []string{"go"}, AMARELO + "Go" + NORMAL + " usa " + AMARELO + "goroutines" + NORMAL},
+		{"só no início da palavra", "Algo sobre Go", []string{"go"}, "Algo sobre " + AMARELO + "Go" + NORMAL},
+		{"recorte dos dois lados", x + " alvo " + x, []string{"alvo"}, "..." + x[:59] + " " + AMARELO + "alvo" + NORMAL + " " + x[:59] + "..."},
+		{"recorte em runas", cedilhas + " alvo " + cedilhas, []string{"alvo"}, "..." + string([]rune(cedilhas)[:59]) + " " + AMARELO + "alvo" + NORMAL + " " + string([]rune(cedilhas)[:59]) + "..."},
+		{"destaque fora do trecho", "alvo " + x + " alvo", []string{"alvo"}, AMARELO + "alvo" + NORMAL + " " + x[:59] + "..."},
+		{"termo não encontrado", x, []string{"alvo"}, x[:2*TAMANHO_TRECHO] + "..."},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			trecho := trechoDestacado(c.texto, c.termos)
+			if trecho != c.esperado {
+				t.Errorf("trechoDestacado = %q, esperado %q", trecho, c.esperado)
+			}
+
+			visivel := strings.NewReplacer(AMARELO, "", NORMAL, "", "...", "").Replace(trecho)
+			if n := len([]rune(visivel)); n > 2*TAMANHO_TRECHO+len("alvo") {
+				t.Errorf("trecho com %d caracteres, esperado no máximo %d", n, 2*TAMANHO_TRECHO+len("alvo"))
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Pasta onde ficam os audios já baixados. O nome de cada arquivo é o hash do texto,
+	// do idioma, do motor de TTS e da voz, de modo que o mesmo texto nunca é baixado duas vezes.
+	CACHE_AUDIO = "./audio/cache"
+)
+
+// Impede que duas goroutines limpem o cache ao mesmo tempo.
+var mutexCache = &sync.Mutex{}
+
+// Indica se o cache de audios está ativo (parâmetro CACHE_TTS_MB maior que zero).
+func (sessao *Sessao) cacheAtivo() bool {
+	return sessao.configuracoes().CACHE_TTS_MB > 0
+}
+
+// Retorna o caminho do arquivo no cache para o texto, idioma, motor de TTS e voz informados.
+func arquivoCache(texto, idioma, motor, voz string) string {
+	hash := sha256.Sum256([]byte(motor + "\x00" + voz + "\x00" + strings.ToLower(idioma) + "\x00" + strings.TrimSpace(texto)))
+	return filepath.Join(CACHE_AUDIO, hex.EncodeToString(hash[:])+".mp3")
+}
+
+// Verifica se o audio está no cache. Se estiver, atualiza a data de alteração do arquivo,
+// que é usada para descartar primeiro os audios usados há mais tempo (LRU).
+func buscaNoCache(caminho string) bool {
+	if _, err := os.Stat(caminho); err != nil {
+		return false
+	}
+	agora := time.Now()
+	os.Chtimes(caminho, agora, agora)
+	return true
+}
+
+// Grava o conteúdo no cache. O conteúdo é gravado em arquivo temporário e depois renomeado,
+// para que outra goroutine nunca encontre um audio gravado pela metade.
+func gravaNoCache(caminho string, conteudo io.Reader) error {
+	if err := os.MkdirAll(CACHE_AUDIO, 0700); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(CACHE_AUDIO, "*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(temp, conteudo)
+	if e := temp.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	return os.Rename(temp.Name(), caminho)
+}
+
+// Descarta os audios usados há mais tempo até que o tamanho do cache fique dentro
+// do limite definido no parâmetro CACHE_TTS_MB.
+func (sessao *Sessao) limitaCache() {
+	defer mutexCache.Unlock()
+	mutexCache.Lock()
+
+	arquivos, err := os.ReadDir(CACHE_AUDIO)
+	if err != nil {
+		return
+	}
+
+	infos := make([]os.FileInfo, 0, len(arquivos))
+	total := int64(0)
+	for _, a := range arquivos {
+		info, err := a.Info()
+		if err != nil || info.IsDir() || filepath.Ext(a.Name()) != ".mp3" {
+			continue
+		}
+		infos = append(infos, info)
+		total += info.Size()
+	}
+
+	// Os usados há mais tempo primeiro.
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().Before(infos[j].ModTime())
+	})
+
+	limite := int64(sessao.configuracoes().CACHE_TTS_MB) * 1024 * 1024
+	for _, info := range infos {
+		if total <= limite {
+			break
+		}
+		if os.Remove(filepath.Join(CACHE_AUDIO, info.Name())) == nil {
+			total -= info.Size()
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Os audios usados há mais tempo são descartados primeiro, até o cache ficar dentro do limite.
+// Os arquivos que não são audios (como os temporários da gravação) não são considerados.
+func TestLimitaCache(t *testing.T) {
+	const TAMANHO = 400 * 1024 // 4 audios somam 1,6 MB; o limite é 1 MB.
+
+	casos := []struct {
+		nome      string
+		buscados  []string // Audios reaproveitados antes da limpeza.
+		restantes []string
+	}{
+		{"mais antigos primeiro", nil, []string{"c", "d"}},
+		{"reaproveitado volta ao fim da fila", []string{"a"}, []string{"a", "d"}},
+		{"dois reaproveitados", []string{"b", "a"}, []string{"a", "b"}},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			sessao := configuraTeste(t)
+			sessao.settings.CACHE_TTS_MB = 1
+
+			if err := os.MkdirAll(CACHE_AUDIO, 0700); err != nil {
+				t.Fatal(err)
+			}
+			inicio := time.Now().Add(-time.Hour)
+			for i, nome := range []string{"a", "b", "c", "d"} {
+				caminho := filepath.Join(CACHE_AUDIO, nome+".mp3")
+				if err := os.WriteFile(caminho, make([]byte, TAMANHO), 0600); err != nil {
+					t.Fatal(err)
+				}
+				modificado := inicio.Add(time.Duration(i) * time.Minute)
+				if err := os.Chtimes(caminho, modificado, modificado); err != nil {
+					t.Fatal(err)
+				}
+			}
+			temporario := filepath.Join(CACHE_AUDIO, "gravando.tmp")
+			if err := os.WriteFile(temporario, make([]byte, 2*TAMANHO), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, nome := range c.buscados {
+				caminho := filepath.Join(CACHE_AUDIO, nome+".mp3")
+				if !buscaNoCache(caminho) {
+					t.Fatalf("%s não encontrado no cache", caminho)
+				}
+				// Garante a ordem dos reaproveitados mesmo com relógio de baixa resolução.
+				modificado := time.Now().Add(time.Duration(i) * time.Minute)
+				os.Chtimes(caminho, modificado, modificado)
+			}
+
+			sessao.limitaCache()
+
+			arquivos, err := filepath.Glob(filepath.Join(CACHE_AUDIO, "*.mp3"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			restantes := make([]string, 0, len(arquivos))
+			for _, a := range arquivos {
+				restantes = append(restantes, filepath.Base(a[:len(a)-len(".mp3")]))
+			}
+			sort.Strings(restantes)
+			if !reflect.DeepEqual(restantes, c.restantes) {
+				t.Errorf("restaram %v, esperado %v", restantes, c.restantes)
+			}
+			if _, err := os.Stat(temporario); err != nil {
+				t.Errorf("o arquivo temporário não deveria ter sido apagado: %v", err)
+			}
+		})
+	}
+}
+
+// O nome do audio no cache muda com o motor, a voz, o idioma e o texto, mas não com
+// a caixa do idioma nem com os espaços em volta do texto.
+func TestArquivoCache(t *testing.T) {
+	base := arquivoCache("Olá, mundo.", "pt-BR", "google", "")
+
+	casos := []struct {
+		nome                      string
+		texto, idioma, motor, voz string
+		igual                     bool
+	}{
+		{"mesmos dados", "Olá, mundo.", "pt-BR", "google", "", true},
+		{"caixa do idioma", "Olá, mundo.", "PT-br", "google", "", true},
+		{"espaços em volta", "  Olá, mundo.\n", "pt-BR", "google", "", true},
+		{"outro texto", "Olá, mundo!", "pt-BR", "google", "", false},
+		{"outro idioma", "Olá, mundo.", "pt-PT", "google", "", false},
+		{"outro motor", "Olá, mundo.", "pt-BR", "openai", "", false},
+		{"outra voz", "Olá, mundo.", "pt-BR", "google", "alloy", false},
+		{"campos deslocados", "Olá, mundo.", "pt-BR", "googl", "e", false},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			caminho := arquivoCache(c.texto, c.idioma, c.motor, c.voz)
+			if filepath.Dir(caminho) != filepath.Clean(CACHE_AUDIO) || filepath.Ext(caminho) != ".mp3" {
+				t.Errorf("caminho %s fora da pasta do cache", caminho)
+			}
+			if (caminho == base) != c.igual {
+				t.Errorf("arquivoCache = %s, base = %s, esperado iguais = %v", caminho, base, c.igual)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"gpt-falador/falador"
+)
+
+type (
+	// Catálogo de mensagens da interface: a tradução de cada texto, indexada pelo texto em português
+	// (exatamente como está no código, inclusive as cores e os verbos de formatação, como "%s").
+	// O texto sem tradução no catálogo é exibido em português.
+	Catalogo map[string]string
+)
+
+const (
+	// Idioma da interface quando o parâmetro UI_LANG e as variáveis de ambiente não o definem.
+	// É o idioma dos textos no código, por isso o seu catálogo é vazio.
+	IDIOMA_INTERFACE_PADRAO = "pt-BR"
+	IDIOMA_INTERFACE_INGLES = "en-US"
+)
+
+var (
+	// Catálogos disponíveis, indexados pelo idioma.
+	catalogos = map[string]Catalogo{
+		IDIOMA_INTERFACE_PADRAO: {},
+		IDIOMA_INTERFACE_INGLES: catalogoIngles,
+	}
+
+	// Idioma da interface em uso. É lido pelas goroutines da narração, por isso é atômico.
+	idiomaInterfaceAtual atomic.Value
+)
+
+// Até o settings.json ser carregado (a apresentação é impressa antes), usa o idioma do ambiente.
+func init() {
+	defineIdiomaInterface("")
+}
+
+// Idioma da interface em uso (ex: "pt-BR").
+func idiomaInterface() string {
+	return idiomaInterfaceAtual.Load().(string)
+}
+
+// Define o idioma da interface a partir do parâmetro UI_LANG ou, se vazio, das variáveis de ambiente
+// LC_ALL, LC_MESSAGES e LANG, nesta ordem. Retorna o idioma escolhido.
+func defineIdiomaInterface(configurado string) string {
+	idioma := resolveIdiomaInterface(configurado, os.Getenv)
+	idiomaInterfaceAtual.Store(idioma)
+	return idioma
+}
+
+// Escolhe o catálogo do idioma configurado (ex: "en-us", "en_US.UTF-8", "pt"). Somente o idioma
+// é considerado, sem a região: qualquer variante do inglês usa o catálogo en-US. Os idiomas sem
+// catálogo (ex: LANG=de_DE.UTF-8) usam o inglês, pois o usuário provavelmente não fala português.
+func resolveIdiomaInterface(configurado string, ambiente func(string) string) string {
+	origem := strings.TrimSpace(configurado)
+	for _, variavel := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if origem != "" {
+			break
+		}
+		origem = strings.TrimSpace(ambiente(variavel))
+	}
+
+	// Sem idioma ou com o locale padrão do sistema ("C" ou "POSIX"): mantém o português.
+	if origem == "" || origem == "C" || origem == "POSIX" || strings.HasPrefix(origem, "C.") {
+		return IDIOMA_INTERFACE_PADRAO
+	}
+
+	// Remove a codificação (ex: ".UTF-8") e a região (ex: "_US", "-br").
+	partes := strings.FieldsFunc(origem, func(r rune) bool { return r == '_' || r == '-' || r == '.' || r == '@' })
+	if len(partes) == 0 {
+		return IDIOMA_INTERFACE_PADRAO
+	}
+	lingua := strings.ToLower(partes[0])
+	for idioma := range catalogos {
+		if strings.HasPrefix(strings.ToLower(idioma), lingua+"-") {
+			return idioma
+		}
+	}
+	return IDIOMA_INTERFACE_INGLES
+}
+
+// Traduz o texto para o idioma da interface. Se não houver tradução, retorna o próprio texto.
+func traduz(texto string) string {
+	if t, existe := catalogos[idiomaInterface()][texto]; existe {
+		return t
+	}
+	return texto
+}
+
+// Traduz o formato para o idioma da interface e o preenche com os argumentos, como fmt.Sprintf.
+func traduzf(formato string, args ...interface{}) string {
+	return fmt.Sprintf(traduz(formato), args...)
+}
+
+// Cria o erro com a mensagem traduzida para o idioma da interface. Aceita os mesmos verbos
+// de fmt.Errorf, inclusive "%w".
+func novoErro(formato string, args ...interface{}) error {
+	return fmt.Errorf(traduz(formato), args...)
+}
+
+// Descreve o erro no idioma da interface. Os erros do pacote falador têm as mensagens em português,
+// por isso são identificados pelo tipo; a mensagem de erro enviada pela API é exibida como veio.
+func descricaoDoErro(err error) string {
+	var erroAPI *falador.ErroAPI
+	var erroStatus *falador.ErroStatus
+	switch {
+	case errors.Is(err, falador.ErrTempoEsgotado):
+		return traduz("servidor demorou a responder")
+	case errors.As(err, &erroStatus):
+		return traduzf("a API respondeu com o status %d: %s", erroStatus.Status, erroStatus.Conteudo)
+	case errors.As(err, &erroAPI) && erroAPI.Mensagem == "":
+		return traduzf("a API respondeu com o status %d", erroAPI.Status)
+	}
+	return err.Error()
+}
@@ -0,0 +1,267 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+// Catálogo de mensagens em inglês (en-US), indexado pelo texto em português.
+// O teste TestCatalogosCompletos indica os textos do código sem tradução.
+var catalogoIngles = Catalogo{
+	// catalogo.go
+	"servidor demorou a responder":        "the server took too long to answer",
+	"a API respondeu com o status %d: %s": "the API answered with status %d: %s",
+	"a API respondeu com o status %d":     "the API answered with status %d",
+
+	// argumentos.go
+	"Imprime a resposta de uma só vez, sem delay.\nTecle \033[36mESC\033[m para interromper a impressão da resposta.\nTecle \033[36mESPAÇO\033[m para imprimir a resposta completa sem delay.\nDurante a narração: \033[36mF8\033[m pausa/continua, \033[36mseta para a direita\033[m pula o trecho,\n\033[36msetas para cima/baixo\033[m alteram o volume e \033[36mPage Up/Page Down\033[m a velocidade.": "Prints the answer all at once, without delay.\nPress \033[36mESC\033[m to stop printing the answer.\nPress \033[36mSPACE\033[m to print the rest of the answer without delay.\nWhile narrating: \033[36mF8\033[m pauses/resumes, \033[36mright arrow\033[m skips the chunk,\n\033[36mup/down arrows\033[m change the volume and \033[36mPage Up/Page Down\033[m the speed.",
+	"Imprime o conteúdo json retornado pelo servidor (payload).": "Prints the json content returned by the server (payload).",
+	"Imprime somente um objeto json com a resposta, o modelo, o motivo do fim,\nos tokens consumidos, o tempo de resposta e o erro (se houver). Não narra a resposta.": "Prints only a json object with the answer, the model, the finish reason,\nthe tokens used, the response time and the error (if any). Does not narrate the answer.",
+	"Executa no modo interativo, para manter o histórico da conversa (o mesmo que \033[36mgpt chat\033[m).":                                                            "Runs in interactive mode, keeping the conversation history (same as \033[36mgpt chat\033[m).",
+	"<modelo>": "<model>",
+	"Usa o modelo informado, em vez do parâmetro GPT_MODEL (ex: \033[36m--model gpt-4\033[m).": "Uses the given model instead of the GPT_MODEL setting (e.g. \033[36m--model gpt-4\033[m).",
+	"<0.0 a 2.0>": "<0.0 to 2.0>",
+	"Usa a temperatura informada, em vez do parâmetro TEMPERATURE.": "Uses the given temperature instead of the TEMPERATURE setting.",
+	"informe um número entre 0.0 e 2.0":                             "enter a number between 0.0 and 2.0",
+	"<idioma>":                                                      "<language>",
+	"Usa o idioma informado na resposta e na narração, em vez do parâmetro IDIOMA (ex: \033[36m--lang en-us\033[m).\nCom \033[36m--lang auto\033[m, responde e narra no idioma detectado na pergunta e na resposta.": "Uses the given language for the answer and the narration instead of the IDIOMA setting (e.g. \033[36m--lang en-us\033[m).\nWith \033[36m--lang auto\033[m, answers and narrates in the language detected in the question and in the answer.",
+	"Ativa (\033[36m--tts\033[m) ou desativa (\033[36m--tts=false\033[m) a narração, em vez do parâmetro TTS.":                                                                                                       "Enables (\033[36m--tts\033[m) or disables (\033[36m--tts=false\033[m) the narration instead of the TTS setting.",
+	"<segundos>": "<seconds>",
+	"Tempo máximo de espera pela resposta, em vez do parâmetro TIMEOUT.": "Maximum time to wait for the answer, instead of the TIMEOUT setting.",
+	"informe a quantidade de segundos (maior que zero)":                  "enter the number of seconds (greater than zero)",
+	"Grava a conversa no arquivo informado após cada resposta.\nO formato é definido pela extensão: .md, .html ou .json (ex: \033[36m--export conversa.md\033[m).": "Saves the conversation to the given file after each answer.\nThe format is defined by the extension: .md, .html or .json (e.g. \033[36m--export chat.md\033[m).",
+	"Grava a narração de cada resposta no arquivo informado (.mp3 ou .wav).":                                                                                       "Saves the narration of each answer to the given file (.mp3 or .wav).",
+	"Inicia a conversa com o histórico gravado no arquivo informado\n(array JSON de mensagens role/content ou transcrição em Markdown).":                           "Starts the conversation with the history saved in the given file\n(JSON array of role/content messages or Markdown transcript).",
+	"%d mensagens carregadas de \"%s\"\r\n": "%d messages loaded from \"%s\"\r\n",
+	"<nome>":                                "<name>",
+	"Monta a pergunta a partir de um template da pasta ./templates.\nOs demais argumentos no formato chave=valor preenchem as variáveis\n(ex: \033[36m--template traducao idioma=inglês Bom dia\033[m).": "Builds the question from a template in the ./templates folder.\nThe other arguments in the key=value format fill in the variables\n(e.g. \033[36m--template traducao idioma=english Good morning\033[m).",
+	"<pergunta>": "<question>",
+	"Faz uma única pergunta e encerra (padrão, se a pergunta for informada).": "Asks a single question and exits (default when a question is given).",
+	"informe a pergunta (ex: gpt ask \"Qual a capital do Brasil?\")":          "enter the question (e.g. gpt ask \"What is the capital of Brazil?\")",
+	"[pergunta]": "[question]",
+	"Inicia o modo interativo, que mantém o histórico da conversa (padrão, sem argumentos).": "Starts the interactive mode, which keeps the conversation history (default, without arguments).",
+	"[param=valor ...]": "[param=value ...]",
+	"Exibe as configurações ou altera e grava os parâmetros informados\nno arquivo settings.json (ex: \033[36mgpt config tts=false lang=en-us\033[m).": "Shows the saved settings, or changes the given parameters and saves them\nto the settings.json file (e.g. \033[36mgpt config tts=false lang=en-us\033[m).",
+	"parâmetro \"%s\" inválido. Use: gpt config param=valor":                                            "invalid parameter \"%s\". Use: gpt config param=value",
+	"o valor não pode ser vazio":                                                                        "the value cannot be empty",
+	"o parâmetro \"--%s\" só pode ser usado no subcomando \"%s\"":                                       "the \"--%s\" flag can only be used with the \"%s\" subcommand",
+	"valor \"%s\" inválido para o parâmetro \"--%s\": use true ou false":                                "invalid value \"%s\" for the \"--%s\" flag: use true or false",
+	"o parâmetro \"--%s\" requer um valor: --%s %s":                                                     "the \"--%s\" flag requires a value: --%s %s",
+	"parâmetro \"--%s\": %w":                                                                            "flag \"--%s\": %w",
+	"parâmetro \"%s\" desconhecido. Você quis dizer \"--%s\"?":                                          "unknown flag \"%s\". Did you mean \"--%s\"?",
+	"parâmetro \"%s\" desconhecido. Para enviar um texto iniciado por \"-\", use \"--\" antes do texto": "unknown flag \"%s\". To send a text starting with \"-\", put \"--\" before the text",
+	"Uso: \033[96mgpt [subcomando] [parâmetros] [texto]\033[m":                                          "Usage: \033[96mgpt [subcommand] [flags] [text]\033[m",
+	"\r\nSubcomandos:": "\r\nSubcommands:",
+	"\r\nParâmetros (use \033[36m--\033[m para encerrar os parâmetros e enviar o restante como texto):": "\r\nFlags (use \033[36m--\033[m to end the flags and send the rest as text):",
+	"(padrão: %s).": "(default: %s).",
+
+	// busca.go
+	"<termos>": "<terms>",
+	"Procura os termos nas perguntas e respostas das conversas gravadas.": "Searches for the terms in the questions and answers of the saved conversations.",
+	"\033[31mInforme ao menos um termo com 2 ou mais letras.\033[m":       "\033[31mEnter at least one term with 2 or more letters.\033[m",
+	"Nenhuma conversa encontrada.":                                        "No conversation found.",
+	"%d resultado(s) encontrado(s)":                                       "%d result(s) found",
+	". Exibindo os %d primeiros":                                          ". Showing the first %d",
+
+	// comandos.go
+	"Exibe estas informações de ajuda.": "Shows this help information.",
+	"Termina o modo interativo.":        "Ends the interactive mode.",
+	"Inicia nova conversa e recarrega as configurações do arquivo settings.json.": "Starts a new conversation and reloads the settings from the settings.json file.",
+	"Reset efetuado. O histórico e contexto da conversa foi perdido.":             "Reset done. The conversation history and context were lost.",
+	"Pronto para iniciar outra conversa.":                                         "Ready to start another conversation.",
+	"Limpa a tela (mantém o histórico da conversa).":                              "Clears the screen (keeps the conversation history).",
+	"[param=valor]": "[param=value]",
+	"Altera o valor de algum parâmetro. Sem argumentos, exibe as configurações atuais.\r\nExemplo: /set tts=false para desativar a fala\r\n         /set lang=en-us para alterar o idioma para Inglês dos EUA": "Changes the value of a parameter. Without arguments, shows the current settings.\r\nExample: /set tts=false to disable the speech\r\n         /set lang=en-us to change the language to US English",
+	"\r\n\033[31mInforme o comando após a \"%s\". Digite \033[36m/help\033[31m para ver os comandos.\033[m\r\n":                                                                                                "\r\n\033[31mEnter the command after the \"%s\". Type \033[36m/help\033[31m to see the commands.\033[m\r\n",
+	"\r\n\033[31mComando \"%s%s\" desconhecido. Digite \033[36m/help\033[31m para ver os comandos.\033[m\r\n":                                                                                                  "\r\n\033[31mCommand \"%s%s\" is unknown. Type \033[36m/help\033[31m to see the commands.\033[m\r\n",
+	"\r\n\033[31mArgumentos inválidos. Uso: %s\033[m\r\n":                                                                                                                                                      "\r\n\033[31mInvalid arguments. Usage: %s\033[m\r\n",
+	"\t    Também: %s%s\r\n": "\t    Also: %s%s\r\n",
+	"\tPara enviar à IA um texto que começa com \"%s\", digite \"%s%s\" no início.\r\n": "\tTo send the AI a text starting with \"%s\", type \"%s%s\" at the beginning.\r\n",
+
+	// controles.go
+	"a resposta não tem texto para narrar": "the answer has no text to narrate",
+
+	// conversas.go
+	"[termos]": "[terms]",
+	"Lista as conversas gravadas na pasta ./conversas ou, se informados os termos,\nprocura-os nas conversas (ex: \033[36mgpt sessions ponteiros go\033[m).": "Lists the conversations saved in the ./conversas folder or, if terms are given,\nsearches for them in the conversations (e.g. \033[36mgpt sessions go pointers\033[m).",
+	"\033[96m%s\033[m (%d mensagens) %s\r\n": "\033[96m%s\033[m (%d messages) %s\r\n",
+	"Nenhuma conversa gravada.":              "No saved conversations.",
+
+	// export.go
+	"[md|html|json] <arquivo>": "[md|html|json] <file>",
+	"Grava a conversa no arquivo informado.\r\nSe o formato for omitido, é deduzido pela extensão do arquivo.": "Saves the conversation to the given file.\r\nIf the format is omitted, it is deduced from the file extension.",
+	"Conversa exportada para \"%s\"":                              "Conversation exported to \"%s\"",
+	"não há mensagens na conversa para exportar":                  "there are no messages in the conversation to export",
+	"formato de exportação \"%s\" inválido. Use md, html ou json": "invalid export format \"%s\". Use md, html or json",
+	"Pergunta":               "Question",
+	"Resposta":               "Answer",
+	"Sistema":                "System",
+	"GPT-Falador - Conversa": "GPT-Falador - Conversation",
+	"Exportada em %s":        "Exported on %s",
+
+	// gpt.go
+	"Digite \033[96m/help\033[m para mais informações": "Type \033[96m/help\033[m for more information",
+	"Idioma:":                         "Language:",
+	"Salva Conversas:":                "Save Conversations:",
+	"Cache TTS (MB):":                 "TTS Cache (MB):",
+	"Velocidade:":                     "Speed:",
+	"Idioma da interface:":            "Interface language:",
+	"Faça a pergunta para o ChatGPT.": "Ask ChatGPT a question.",
+	"Exemplo: O que pesa mais: um quilo de pena ou um quilo de chumbo?":         "Example: What weighs more: a kilo of feathers or a kilo of lead?",
+	"\r\nComandos do modo interativo:":                                          "\r\nInteractive mode commands:",
+	"Use \033[96mgpt --help\033[m para ver os subcomandos e parâmetros.":        "Use \033[96mgpt --help\033[m to see the subcommands and flags.",
+	"\r\n\033[32mTraduzir\033[m (%s → %s): ":                                    "\r\n\033[32mTranslate\033[m (%s → %s): ",
+	"\r\n\033[32mPergunta\033[m: ":                                              "\r\n\033[32mQuestion\033[m: ",
+	"\r\n\033[31mComando \"%s\" inválido\033[m\r\n":                             "\r\n\033[31mInvalid command \"%s\"\033[m\r\n",
+	"GPT Model alterada para \"%s\"":                                            "GPT Model changed to \"%s\"",
+	"Idioma alterado para \"%s\"":                                               "Language changed to \"%s\"",
+	"Idioma da interface alterado para \"%s\"":                                  "Interface language changed to \"%s\"",
+	"\r\n\033[31mMotor de TTS \"%s\" inexistente. Use: %s\033[m\r\n":            "\r\n\033[31mTTS engine \"%s\" does not exist. Use: %s\033[m\r\n",
+	"Motor de TTS alterado para \"%s\"":                                         "TTS engine changed to \"%s\"",
+	"Voz alterada para \"%s\"":                                                  "Voice changed to \"%s\"",
+	"\r\n\033[31mMotor de STT \"%s\" inexistente\033[m\r\n":                     "\r\n\033[31mSTT engine \"%s\" does not exist\033[m\r\n",
+	"Motor de STT alterado para \"%s\"":                                         "STT engine changed to \"%s\"",
+	"\r\n\033[31mValor \"%s\" inválido\033[m\r\n":                               "\r\n\033[31mInvalid value \"%s\"\033[m\r\n",
+	"Delay máximo alterado para \"%s\"":                                         "Max delay changed to \"%s\"",
+	"Timeout alterado para \"%s\"":                                              "Timeout changed to \"%s\"",
+	"Temperature alterado para \"%.2f\"":                                        "Temperature changed to \"%.2f\"",
+	"Salva conversas alterado para \"%s\"":                                      "Save conversations changed to \"%s\"",
+	"Tamanho do cache de audios alterado para \"%s\" MB":                        "Audio cache size changed to \"%s\" MB",
+	"Volume alterado para \"%s\"":                                               "Volume changed to \"%s\"",
+	"TTS (Text-To-Speech) alterado para \"%s\"":                                 "TTS (Text-To-Speech) changed to \"%s\"",
+	"nenhum audio a reproduzir":                                                 "no audio to play",
+	"narração interrompida":                                                     "narration interrupted",
+	"\r\n\033[31m <interrompido>\033[m":                                         "\r\n\033[31m <interrupted>\033[m",
+	"\r\033[31mServidor demorou a responder. Envie a pergunta novamente.\033[m": "\r\033[31mThe server took too long to answer. Send the question again.\033[m",
+	"\r\nJSON retornado: ":                                                      "\r\nReturned JSON: ",
+	"Terminal não permite habilitar cores":                                      "The terminal does not allow enabling colors",
+	"versão":                                                                    "version",
+	"Desenvolvido por Hugo S. Novaes (\033[96mhnovaes@yahoo.com\033[m)":         "Developed by Hugo S. Novaes (\033[96mhnovaes@yahoo.com\033[m)",
+	"\r\033[94m        \rResposta\033[m: ":                                      "\r\033[94m        \rAnswer\033[m: ",
+
+	// gravaaudio.go
+	"replay | save <arquivo> | volume <0-100> | speed <0.5-2>": "replay | save <file> | volume <0-100> | speed <0.5-2>",
+	"Controla a narração da última resposta:\r\nreplay narra a resposta novamente; save grava a narração no arquivo (.mp3 ou .wav);\r\nvolume e speed alteram o volume e a velocidade da narração (a velocidade também altera o tom da voz).": "Controls the narration of the last answer:\r\nreplay narrates the answer again; save saves the narration to the file (.mp3 or .wav);\r\nvolume and speed change the volume and the speed of the narration (the speed also changes the pitch of the voice).",
+	"<texto>": "<text>",
+	"Narra o texto informado, sem enviá-lo à IA. Com o parâmetro \033[36m--save-audio\033[m,\ngrava a narração no arquivo (ex: \033[36mgpt speak --save-audio bomdia.mp3 Bom dia!\033[m).": "Narrates the given text without sending it to the AI. With the \033[36m--save-audio\033[m flag,\nsaves the narration to the file (e.g. \033[36mgpt speak --save-audio hello.mp3 Good morning!\033[m).",
+	"informe o texto a narrar (ex: gpt speak \"Bom dia!\")": "enter the text to narrate (e.g. gpt speak \"Good morning!\")",
+	"Narração gravada em \"%s\"\r\n":                        "Narration saved to \"%s\"\r\n",
+	"Narração gravada em \"%s\"":                            "Narration saved to \"%s\"",
+	"Volume alterado para \"%d\"":                           "Volume changed to \"%d\"",
+	"Velocidade alterada para \"%.2f\"":                     "Speed changed to \"%.2f\"",
+	"opção inválida. Uso: /speak %s":                        "invalid option. Usage: /speak %s",
+	"não há resposta na conversa para narrar":               "there is no answer in the conversation to narrate",
+	"formato \"%s\" inválido. Use .mp3 ou .wav":             "invalid format \"%s\". Use .mp3 or .wav",
+	"o texto não tem trechos para narrar":                   "the text has nothing to narrate",
+	"falha ao obter o audio do trecho \"%s\": %w":           "failed to get the audio of the chunk \"%s\": %w",
+
+	// import.go
+	"<arquivo>": "<file>",
+	"Acrescenta ao histórico a conversa gravada no arquivo (JSON ou Markdown).":  "Appends the conversation saved in the file (JSON or Markdown) to the history.",
+	"%d mensagens importadas de \"%s\"":                                          "%d messages imported from \"%s\"",
+	"histórico \"%s\" inválido: %w":                                              "invalid history \"%s\": %w",
+	"arquivo \"%s\" vazio":                                                       "file \"%s\" is empty",
+	"nenhuma mensagem encontrada. Use títulos \"## Pergunta\" e \"## Resposta\"": "no messages found. Use the headings \"## Pergunta\" and \"## Resposta\"",
+	"mensagem %d sem conteúdo":                                                   "message %d has no content",
+	"mensagem %d tem role \"%s\", mas era esperado \"%s\"":                       "message %d has role \"%s\", but \"%s\" was expected",
+	"a última mensagem deve ser uma resposta (\"assistant\")":                    "the last message must be an answer (\"assistant\")",
+
+	// microfone_other.go e microfone_windows.go
+	"a gravação pelo microfone só está disponível no Windows. Use /listen arquivo.wav": "recording from the microphone is only available on Windows. Use /listen file.wav",
+	"não foi possível abrir o microfone (erro %d)":                                     "could not open the microphone (error %d)",
+	"não foi possível iniciar a gravação (erro %d)":                                    "could not start recording (error %d)",
+
+	// saida_other.go
+	"a execução de audio só está disponível no Windows. Grave a narração com /speak save ou --save-audio, ou desative-a com /set tts=false": "audio playback is only available on Windows. Save the narration with /speak save or --save-audio, or turn it off with /set tts=false",
+
+	// saidajson.go
+	"nenhuma pergunta informada": "no question given",
+
+	// servidor.go
+	"Endereço do subcomando \033[36mserve\033[m": "Address of the \033[36mserve\033[m subcommand",
+	"<host:porta>": "<host:port>",
+	"Responde as perguntas recebidas via HTTP: POST /ask com {\"question\": \"...\"}\nretorna o mesmo objeto json do parâmetro \033[36m--json\033[m. Cada pergunta é independente (sem histórico).": "Answers the questions received via HTTP: POST /ask with {\"question\": \"...\"}\nreturns the same json object as the \033[36m--json\033[m flag. Each question is independent (no history).",
+	"o subcomando serve não aceita argumentos. Use --addr para informar o endereço":                                                                                                                 "the serve subcommand takes no arguments. Use --addr to set the address",
+	"Aguardando perguntas em \033[96mhttp://%s/ask\033[m (tecle Ctrl+C para encerrar)\r\n":                                                                                                          "Waiting for questions at \033[96mhttp://%s/ask\033[m (press Ctrl+C to stop)\r\n",
+
+	// stt.go
+	"[arquivo.wav | on | off]": "[file.wav | on | off]",
+	"Dita a pergunta pelo microfone (ENTER envia, ESC cancela) ou transcreve o arquivo WAV.\r\non/off liga ou desliga o modo de voz: todas as perguntas são ditadas.": "Dictates the question through the microphone (ENTER sends, ESC cancels) or transcribes the WAV file.\r\non/off turns the voice mode on or off: all questions are dictated.",
+	"Modo de voz ativado: as perguntas serão ditadas pelo microfone. Tecle ESC para voltar a digitar.":                                                                "Voice mode on: the questions will be dictated through the microphone. Press ESC to go back to typing.",
+	"\033[32mTranscrição\033[m: %s\r\n": "\033[32mTranscription\033[m: %s\r\n",
+	"\r\n\033[32mOuvindo\033[m (tecle \033[36mENTER\033[m para enviar ou \033[36mESC\033[m para cancelar)...": "\r\n\033[32mListening\033[m (press \033[36mENTER\033[m to send or \033[36mESC\033[m to cancel)...",
+	"Modo de voz desativado.":                  "Voice mode off.",
+	"\033[32mPergunta\033[m: %s\r\n":           "\033[32mQuestion\033[m: %s\r\n",
+	"motor de STT \"%s\" inexistente. Use: %s": "STT engine \"%s\" does not exist. Use: %s",
+	"nenhuma fala reconhecida no audio":        "no speech recognized in the audio",
+	"falha na transcrição: %s":                 "transcription failed: %s",
+	"informe os parâmetros WHISPER_CPP e WHISPER_CPP_MODEL no arquivo settings.json": "set the WHISPER_CPP and WHISPER_CPP_MODEL parameters in the settings.json file",
+	"falha no whisper.cpp: %s": "whisper.cpp failed: %s",
+
+	// templates.go
+	"[nome] [chave=valor...]": "[name] [key=value...]",
+	"Pergunta usando um template da pasta ./templates.\r\nSem argumentos, lista os templates disponíveis.": "Asks using a template from the ./templates folder.\r\nWithout arguments, lists the available templates.",
+	"template \"%s\": informe o valor de %s":       "template \"%s\": enter the value of %s",
+	"nome de template \"%s\" inválido":             "invalid template name \"%s\"",
+	"template \"%s\" não encontrado na pasta ./%s": "template \"%s\" not found in the ./%s folder",
+	"Nenhum template encontrado na pasta ./%s\r\n": "No templates found in the ./%s folder\r\n",
+	"Templates disponíveis:":                       "Available templates:",
+	"\t  Variáveis: %s\r\n":                        "\t  Variables: %s\r\n",
+
+	// traducao.go
+	"Idioma do texto do subcomando \033[36mtranslate\033[m (padrão: detectado pela IA).":                                                 "Language of the text of the \033[36mtranslate\033[m subcommand (default: detected by the AI).",
+	"Idioma da tradução do subcomando \033[36mtranslate\033[m (padrão: inglês para os textos\nem português e português para os demais).": "Target language of the \033[36mtranslate\033[m subcommand (default: English for texts\nin Portuguese and Portuguese for the others).",
+	"[texto]": "[text]",
+	"Traduz o texto e narra a tradução no idioma de destino. Sem o texto, inicia o modo\ninterativo de tradução (ex: \033[36mgpt translate --from pt-br --to en-us Bom dia!\033[m).": "Translates the text and narrates the translation in the target language. Without the text, starts\nthe interactive translation mode (e.g. \033[36mgpt translate --from pt-br --to en-us Bom dia!\033[m).",
+	"informe o texto a traduzir (ex: gpt translate --to en-us \"Bom dia!\")": "enter the text to translate (e.g. gpt translate --to pt-br \"Good morning!\")",
+	"[origem] <destino> | on | off":                                          "[source] <target> | on | off",
+	"Ativa o modo de tradução: os textos digitados são traduzidos, sem entrar no histórico,\r\ne a tradução é narrada no idioma de destino. off volta à conversa.\r\nExemplo: /translate pt-br en-us": "Turns on the translation mode: the typed texts are translated, without entering the history,\r\nand the translation is narrated in the target language. off goes back to the conversation.\r\nExample: /translate pt-br en-us",
+	"Modo de tradução desativado. Os textos voltam a ser enviados à conversa.":                        "Translation mode off. The texts are sent to the conversation again.",
+	"Modo de tradução ativado (%s → %s). Digite \033[36m/translate off\033[m para voltar à conversa.": "Translation mode on (%s → %s). Type \033[36m/translate off\033[m to go back to the conversation.",
+	"nenhum texto informado para traduzir":                                                            "no text given to translate",
+	"\r\033[94m        \rTradução\033[m (%s): ":                                                       "\r\033[94m        \rTranslation\033[m (%s): ",
+
+	// tts.go, ttsgoogle.go e ttsopenai.go
+	"Lista as vozes disponíveis no motor de TTS ativo (parâmetro tts_engine).":                                                         "Lists the voices available in the active TTS engine (tts_engine parameter).",
+	"motor de TTS \"%s\" inexistente. Use: %s":                                                                                         "TTS engine \"%s\" does not exist. Use: %s",
+	"Vozes do motor \033[96m%s\033[m (em uso: \033[96m%s\033[m):\r\n":                                                                  "Voices of the \033[96m%s\033[m engine (in use: \033[96m%s\033[m):\r\n",
+	"Use \033[96m/set voice=<voz>\033[m para escolher a voz, ou o parâmetro VOZES do settings.json para definir a voz de cada idioma.": "Use \033[96m/set voice=<voice>\033[m to choose the voice, or the VOZES parameter in settings.json to set the voice of each language.",
+	"falha ao obter o audio: %s":  "failed to get the audio: %s",
+	"Português do Brasil":         "Brazilian Portuguese",
+	"Português de Portugal":       "European Portuguese",
+	"Inglês dos Estados Unidos":   "US English",
+	"Inglês do Reino Unido":       "British English",
+	"Inglês da Austrália":         "Australian English",
+	"Inglês da Índia":             "Indian English",
+	"Espanhol da Espanha":         "Spanish (Spain)",
+	"Espanhol dos Estados Unidos": "Spanish (United States)",
+	"Francês da França":           "French (France)",
+	"Francês do Canadá":           "French (Canada)",
+	"Alemão":                      "German",
+	"Italiano":                    "Italian",
+	"Japonês":                     "Japanese",
+	"Chinês (Mandarim)":           "Chinese (Mandarin)",
+	"Neutra, equilibrada":         "Neutral, balanced",
+	"Masculina, calma":            "Male, calm",
+	"Britânica, expressiva":       "British, expressive",
+	"Masculina, grave":            "Male, deep",
+	"Feminina, jovem":             "Female, young",
+	"Feminina, suave":             "Female, soft",
+}
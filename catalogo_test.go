@@ -0,0 +1,295 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gpt-falador/falador"
+)
+
+// Pasta do código-fonte, pois os testes mudam a pasta atual (configuraTeste).
+var pastaCodigo, _ = filepath.Abs(".")
+
+// Verbos de formatação (ex: %s, %.2f, %w), que devem ser os mesmos no texto e na tradução.
+var verbo = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestResolveIdiomaInterface(t *testing.T) {
+	testes := []struct {
+		configurado string
+		ambiente    map[string]string
+		idioma      string
+	}{
+		{"", nil, "pt-BR"},
+		{"en-us", nil, "en-US"},
+		{"EN", nil, "en-US"},
+		{"pt-br", map[string]string{"LANG": "en_US.UTF-8"}, "pt-BR"},
+		{"pt_PT", nil, "pt-BR"},
+		{"", map[string]string{"LANG": "en_US.UTF-8"}, "en-US"},
+		{"", map[string]string{"LANG": "pt_BR.UTF-8"}, "pt-BR"},
+		{"", map[string]string{"LANG": "C.UTF-8"}, "pt-BR"},
+		{"", map[string]string{"LANG": "POSIX"}, "pt-BR"},
+		{"", map[string]string{"LANG": "de_DE.UTF-8"}, "en-US"},
+		{"", map[string]string{"LANG": "pt_BR.UTF-8", "LC_MESSAGES": "en_GB"}, "en-US"},
+		{"", map[string]string{"LC_ALL": "pt_BR", "LC_MESSAGES": "en_GB"}, "pt-BR"},
+	}
+
+	for _, tt := range testes {
+		ambiente := func(variavel string) string { return tt.ambiente[variavel] }
+		if idioma := resolveIdiomaInterface(tt.configurado, ambiente); idioma != tt.idioma {
+			t.Errorf("resolveIdiomaInterface(%q, %v) = %q, esperado %q", tt.configurado, tt.ambiente, idioma, tt.idioma)
+		}
+	}
+}
+
+// Todos os textos traduzíveis do código têm tradução em cada catálogo, com os mesmos verbos de
+// formatação, e os catálogos não têm textos que não existem mais no código.
+func TestCatalogosCompletos(t *testing.T) {
+	textos := textosTraduziveis(t)
+	if len(textos) < 100 {
+		t.Fatalf("apenas %d textos traduzíveis encontrados no código", len(textos))
+	}
+
+	for idioma, catalogo := range catalogos {
+		if idioma == IDIOMA_INTERFACE_PADRAO {
+			continue
+		}
+		faltando := make([]string, 0)
+		for texto, posicao := range textos {
+			traducao, existe := catalogo[texto]
+			if !existe {
+				faltando = append(faltando, posicao+": "+strconv.Quote(texto))
+				continue
+			}
+			if a, b := verbo.FindAllString(texto, -1), verbo.FindAllString(traducao, -1); strings.Join(a, " ") != strings.Join(b, " ") {
+				t.Errorf("%s: os verbos da tradução %v diferem dos do texto %v: %q", idioma, b, a, texto)
+			}
+		}
+		sort.Strings(faltando)
+		for _, f := range faltando {
+			t.Errorf("%s: sem tradução em %s", f, idioma)
+		}
+
+		for texto := range catalogo {
+			if _, existe := textos[texto]; !existe {
+				t.Errorf("%s: o texto %q não existe mais no código", idioma, texto)
+			}
+		}
+	}
+}
+
+// Textos traduzíveis do código, com a posição de cada um: o primeiro argumento de traduz, traduzf
+// e novoErro, as descrições e os argumentos de comandos, subcomandos e parâmetros e as descrições das vozes.
+func textosTraduziveis(t *testing.T) map[string]string {
+	t.Helper()
+
+	arquivos, err := filepath.Glob(filepath.Join(pastaCodigo, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	textos := make(map[string]string)
+	adiciona := func(expr ast.Expr) {
+		if texto, ok := textoConstante(expr); ok && texto != "" {
+			textos[texto] = fset.Position(expr.Pos()).String()
+		}
+	}
+
+	for _, arquivo := range arquivos {
+		if strings.HasSuffix(arquivo, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, arquivo, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				if id, ok := n.Fun.(*ast.Ident); ok && len(n.Args) > 0 && (id.Name == "traduz" || id.Name == "traduzf" || id.Name == "novoErro") {
+					// traduz também recebe as descrições, que são verificadas pelo caso abaixo.
+					if _, ok := textoConstante(n.Args[0]); !ok && id.Name != "traduz" {
+						t.Errorf("%s: %s deve receber um texto constante", fset.Position(n.Pos()), id.Name)
+					}
+					adiciona(n.Args[0])
+				}
+			case *ast.KeyValueExpr:
+				if id, ok := n.Key.(*ast.Ident); ok && (id.Name == "Descricao" || id.Name == "Argumentos" || id.Name == "Argumento") {
+					if _, ok := textoConstante(n.Value); !ok {
+						t.Errorf("%s: %s deve ser um texto constante", fset.Position(n.Pos()), id.Name)
+					}
+					adiciona(n.Value)
+				}
+			case *ast.CompositeLit:
+				if tipo, ok := n.Type.(*ast.ArrayType); ok {
+					if id, ok := tipo.Elt.(*ast.Ident); ok && id.Name == "Voz" {
+						for _, e := range n.Elts {
+							if voz, ok := e.(*ast.CompositeLit); ok && len(voz.Elts) == 2 {
+								adiciona(voz.Elts[1])
+							}
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+	return textos
+}
+
+// Valor do texto constante (literal ou concatenação de literais).
+func textoConstante(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		a, okA := textoConstante(e.X)
+		b, okB := textoConstante(e.Y)
+		return a + b, okA && okB && e.Op == token.ADD
+	case *ast.ParenExpr:
+		return textoConstante(e.X)
+	}
+	return "", false
+}
+
+// Com o catálogo em inglês, as mensagens, os erros e a ajuda são exibidos em inglês.
+func TestMensagensEmIngles(t *testing.T) {
+	sessao := configuraTeste(t)
+	defineIdiomaInterface("en-US")
+
+	saida := capturaSaida(t, sessao, func() {
+		sessao.executaComando("/inexistente")
+		sessao.printHelp()
+		sessao.trataComandoSet("tts=talvez")
+		sessao.trataComandoSet("ui_lang=pt-br")
+		sessao.tela.Println()
+		sessao.trataComandoSet("ui_lang=en")
+	})
+
+	for _, esperado := range []string{
+		`Command "/inexistente" is unknown.`,
+		"Interactive mode commands:",
+		"Changes the value of a parameter.",
+		`Invalid value "talvez"`,
+		`Idioma da interface alterado para "pt-BR"`,
+		`Interface language changed to "en-US"`,
+	} {
+		if !strings.Contains(saida, esperado) {
+			t.Errorf("saída não contém %q:\n%s", esperado, saida)
+		}
+	}
+
+	if _, err := sessao.interpretaLinhaDeComando([]string{"--modle", "x"}); err == nil || !strings.Contains(err.Error(), `Did you mean "--model"?`) {
+		t.Errorf("erro = %v", err)
+	}
+}
+
+// Os erros do pacote falador, que têm as mensagens em português, são exibidos no idioma
+// da interface na tela e no modo --json. A mensagem enviada pela API é exibida como veio.
+func TestErrosEmIngles(t *testing.T) {
+	sessao := configuraTeste(t)
+	defineIdiomaInterface("en-US")
+
+	testes := []struct {
+		nome     string
+		err      error
+		esperado string
+	}{
+		{"tempo esgotado", falador.ErrTempoEsgotado, "the server took too long to answer"},
+		{"tempo esgotado encapsulado", fmt.Errorf("pergunta: %w", falador.ErrTempoEsgotado), "the server took too long to answer"},
+		{"resposta sem json", &falador.ErroStatus{Status: 502, Conteudo: "Bad Gateway"}, "the API answered with status 502: Bad Gateway"},
+		{"erro da API sem mensagem", &falador.ErroAPI{Status: 500}, "the API answered with status 500"},
+		{"mensagem da API", &falador.ErroAPI{Status: 400, Mensagem: "The model does not exist"}, "The model does not exist"},
+		{"erro do aplicativo", novoErro("nenhuma pergunta informada"), "no question given"},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			if d := descricaoDoErro(tt.err); d != tt.esperado {
+				t.Errorf("descricaoDoErro = %q, esperado %q", d, tt.esperado)
+			}
+			if r := sessao.montaRespostaJson(nil, tt.err, 0); r.Error != tt.esperado {
+				t.Errorf("erro no json = %q, esperado %q", r.Error, tt.esperado)
+			}
+			if saida := capturaSaida(t, sessao, func() { sessao.tela.Erro(tt.err) }); !strings.Contains(saida, tt.esperado) {
+				t.Errorf("erro impresso = %q, esperado conter %q", saida, tt.esperado)
+			}
+		})
+	}
+}
+
+// A transcrição é exportada com os títulos no idioma da interface e pode ser importada
+// com a interface em outro idioma.
+func TestExportaEmIngles(t *testing.T) {
+	configuraTeste(t)
+	defineIdiomaInterface("en-US")
+	msgs := conversaExportavel()
+
+	md, pagina := &bytes.Buffer{}, &bytes.Buffer{}
+	exportaMarkdown(md, msgs)
+	exportaHTML(pagina, msgs)
+
+	for _, esperado := range []string{"# GPT-Falador - Conversation\n", "\nExported on ", "\n## System\n", "\n## Question (", "\n## Answer ("} {
+		if !strings.Contains(md.String(), esperado) {
+			t.Errorf("Markdown não contém %q:\n%s", esperado, md)
+		}
+	}
+	for _, esperado := range []string{"<title>GPT-Falador - Conversation</title>", "<h1>GPT-Falador - Conversation</h1>", "<p>Exported on ", "<h2>Question ("} {
+		if !strings.Contains(pagina.String(), esperado) {
+			t.Errorf("HTML não contém %q:\n%s", esperado, pagina)
+		}
+	}
+	if strings.Contains(md.String()+pagina.String(), "Pergunta") {
+		t.Error("a exportação contém títulos em português")
+	}
+
+	defineIdiomaInterface(IDIOMA_INTERFACE_PADRAO)
+	importadas, err := leConversaMarkdown(md.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(importadas) != len(msgs) {
+		t.Fatalf("%d mensagens importadas, esperado %d", len(importadas), len(msgs))
+	}
+	for i, m := range importadas {
+		if m.Role != msgs[i].Role || m.Content != textoDaMensagem(msgs[i]) {
+			t.Errorf("mensagem %d = %+v, esperado %+v", i+1, m, msgs[i])
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// Comando do modo interativo. Os comandos são digitados com o prefixo "/" (ex: /help),
+	// para não serem confundidos com as perguntas feitas à IA.
+	Comando struct {
+		Nome       string   // Nome do comando, sem a "/".
+		Aliases    []string // Nomes alternativos do comando.
+		Argumentos string   // Descrição dos argumentos exibida no help (ex: "<formato> <arquivo>").
+		Descricao  string   // Texto exibido no help.
+		MinArgs    int      // Quantidade mínima de argumentos.
+		MaxArgs    int      // Quantidade máxima de argumentos. Se for -1, não há limite.
+
+		// Executa o comando com os argumentos já separados (respeitando as aspas).
+		// Se retornar um texto, este é enviado à IA como pergunta.
+		Executa func(sessao *Sessao, args []string) string
+	}
+)
+
+const (
+	PREFIXO_COMANDO = "/"
+)
+
+var (
+	comandos        = make([]*Comando, 0)       // Comandos na ordem em que foram registrados (usada no help).
+	comandosPorNome = make(map[string]*Comando) // Comandos indexados pelo nome e pelos aliases.
+)
+
+// Registra os comandos básicos do modo interativo.
+// Os demais comandos são registrados na função init() do arquivo onde são implementados.
+func init() {
+	registraComando(&Comando{
+		Nome:      "help",
+		Aliases:   []string{"?", "ajuda"},
+		Descricao: "Exibe estas informações de ajuda.",
+		Executa: func(sessao *Sessao, args []string) string {
+			sessao.printHelp()
+			return ""
+		},
+	})
+
+	registraComando(&Comando{
+		Nome:      "quit",
+		Aliases:   []string{"exit", "sair"},
+		Descricao: "Termina o modo interativo.",
+		Executa: func(sessao *Sessao, args []string) string {
+			sessao.encerrada = true
+			return ""
+		},
+	})
+
+	registraComando(&Comando{
+		Nome:      "reset",
+		Descricao: "Inicia nova conversa e recarrega as configurações do arquivo settings.json.",
+		Executa: func(sessao *Sessao, args []string) string {
+			sessao.clearScreen()
+			sessao.carregaConfiguracoes()
+			sessao.tela.Println(traduz("Reset efetuado. O histórico e contexto da conversa foi perdido."))
+			sessao.tela.Println(traduz("Pronto para iniciar outra conversa."))
+			return ""
+		},
+	})
+
+	registraComando(&Comando{
+		Nome:      "cls",
+		Aliases:   []string{"clear", "limpa"},
+		Descricao: "Limpa a tela (mantém o histórico da conversa).",
+		Executa: func(sessao *Sessao, args []string) string {
+			sessao.clearScreen()
+			return ""
+		},
+	})
+
+	registraComando(&Comando{
+		Nome:       "set",
+		Argumentos: "[param=valor]",
+		Descricao: "Altera o valor de algum parâmetro. Sem argumentos, exibe as configurações atuais.\r\n" +
+			"Exemplo: /set tts=false para desativar a fala\r\n" +
+			"         /set lang=en-us para alterar o idioma para Inglês dos EUA",
+		MaxArgs: -1,
+		Executa: func(sessao *Sessao, args []string) string {
+			if len(args) == 0 {
+				sessao.printSettings()
+				return ""
+			}
+			if sessao.trataComandoSet(strings.Join(args, " ")) {
+				sessao.gravaSettings()
+			}
+			return ""
+		},
+	})
+}
+
+// Adiciona o comando ao registro. Aborta se o nome ou algum alias já estiver em uso,
+// pois trata-se de erro de programação.
+func registraComando(c *Comando) {
+	for _, nome := range append([]string{c.Nome}, c.Aliases...) {
+		if _, existe := comandosPorNome[nome]; existe {
+			panic(fmt.Sprintf("comando \"%s%s\" registrado em duplicidade", PREFIXO_COMANDO, nome))
+		}
+		comandosPorNome[nome] = c
+	}
+	comandos = append(comandos, c)
+}
+
+// Verifica se o texto digitado é um comando (começa com "/").
+// O texto iniciado por "//" não é comando: é enviado à IA sem a primeira "/".
+func ehComando(s string) bool {
+	return strings.HasPrefix(s, PREFIXO_COMANDO) && !strings.HasPrefix(s, PREFIXO_COMANDO+PREFIXO_COMANDO)
+}
+
+// Executa o comando digitado (ex: "/export md conversa.md").
+// Retorna a pergunta a ser enviada à IA, se o comando gerar alguma, ou "".
+func (sessao *Sessao) executaComando(linha string) string {
+	args := separaArgumentos(strings.TrimPrefix(linha, PREFIXO_COMANDO))
+	if len(args) == 0 {
+		sessao.tela.Printf(traduz("\r\n\033[31mInforme o comando após a \"%s\". Digite \033[36m/help\033[31m para ver os comandos.\033[m\r\n"), PREFIXO_COMANDO)
+		return ""
+	}
+
+	nome := strings.ToLower(args[0])
+	c, ok := comandosPorNome[nome]
+	if !ok {
+		sessao.tela.Printf(traduz("\r\n\033[31mComando \"%s%s\" desconhecido. Digite \033[36m/help\033[31m para ver os comandos.\033[m\r\n"), PREFIXO_COMANDO, nome)
+		return ""
+	}
+
+	args = args[1:]
+	if len(args) < c.MinArgs || (c.MaxArgs >= 0 && len(args) > c.MaxArgs) {
+		sessao.tela.Printf(traduz("\r\n\033[31mArgumentos inválidos. Uso: %s\033[m\r\n"), c.Uso())
+		return ""
+	}
+
+	return c.Executa(sessao, args)
+}
+
+// Retorna a forma de uso do comando (ex: "/export [formato] <arquivo>").
+func (c *Comando) Uso() string {
+	uso := PREFIXO_COMANDO + c.Nome
+	if c.Argumentos != "" {
+		uso += " " + traduz(c.Argumentos)
+	}
+	return uso
+}
+
+// Imprime a lista de comandos registrados com a descrição e os aliases de cada um.
+func (sessao *Sessao) printHelpComandos() {
+	for _, c := range comandos {
+		sessao.tela.Printf("\t\033[36m%s\033[m\r\n", c.Uso())
+		for _, linha := range strings.Split(traduz(c.Descricao), "\r\n") {
+			sessao.tela.Printf("\t    %s\r\n", linha)
+		}
+		if len(c.Aliases) > 0 {
+			sessao.tela.Printf(traduz("\t    Também: %s%s\r\n"), PREFIXO_COMANDO, strings.Join(c.Aliases, ", "+PREFIXO_COMANDO))
+		}
+	}
+	sessao.tela.Printf(traduz("\tPara enviar à IA um texto que começa com \"%s\", digite \"%s%s\" no início.\r\n"), PREFIXO_COMANDO, PREFIXO_COMANDO, PREFIXO_COMANDO)
+}
+
+// Separa o texto em argumentos pelos espaços, respeitando trechos entre aspas duplas ou simples.
+// As aspas só delimitam um trecho no início do argumento ou logo após o "=", para não
+// confundir apóstrofos (ex: "d'água", "it's") com o início de um trecho.
+// Exemplo: `t traduz idioma=inglês texto="bom dia"` --> [t traduz idioma=inglês texto=bom dia]
+func separaArgumentos(s string) []string {
+	args := make([]string, 0)
+	atual := &strings.Builder{}
+	aspas := rune(0)
+	anterior := rune(0)
+	temArgumento := false
+
+	for _, c := range s {
+		inicioDoValor := !temArgumento || anterior == '='
+		anterior = c
+
+		switch {
+		case aspas != 0 && c == aspas:
+			aspas = 0
+		case aspas == 0 && inicioDoValor && (c == '"' || c == '\''):
+			aspas = c
+			temArgumento = true
+		case aspas == 0 && (c == ' ' || c == '\t'):
+			if temArgumento {
+				args = append(args, atual.String())
+				atual.Reset()
+				temArgumento = false
+			}
+		default:
+			atual.WriteRune(c)
+			temArgumento = true
+		}
+	}
+
+	if temArgumento {
+		args = append(args, atual.String())
+	}
+	return args
+}
+
+// Verifica se a lista contém o texto informado.
+func contem(lista []string, s string) bool {
+	for _, item := range lista {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeparaArgumentos(t *testing.T) {
+	testes := []struct {
+		linha string
+		args  []string
+	}{
+		{"", []string{}},
+		{" \t ", []string{}},
+		{"export md conversa.md", []string{"export", "md", "conversa.md"}},
+		{"  set \t tts=false  ", []string{"set", "tts=false"}},
+		{`t traduz idioma=inglês texto="bom dia"`, []string{"t", "traduz", "idioma=inglês", "texto=bom dia"}},
+		{`"a b" 'c d'`, []string{"a b", "c d"}},
+		{`a "" b`, []string{"a", "", "b"}},
+		{`"it's ok" 'diz "oi"'`, []string{"it's ok", `diz "oi"`}},
+		{"copo d'água it's", []string{"copo", "d'água", "it's"}},
+		{`abc"def ghi"`, []string{`abc"def`, `ghi"`}},
+		{`"a"b x="a b"c`, []string{"ab", "x=a bc"}},
+		{`texto="bom dia`, []string{"texto=bom dia"}},
+		{`export 'sem fim.md`, []string{"export", "sem fim.md"}},
+		{`a=b="c d"`, []string{"a=b=c d"}},
+	}
+
+	for _, tt := range testes {
+		if args := separaArgumentos(tt.linha); !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("separaArgumentos(%q) = %q, esperado %q", tt.linha, args, tt.args)
+		}
+	}
+}
+
+func TestEhComando(t *testing.T) {
+	testes := []struct {
+		linha   string
+		comando bool
+	}{
+		{"/help", true},
+		{"/", true},
+		{"/set tts=false", true},
+		{"//etc/hosts é um arquivo?", false},
+		{"///", false},
+		{"Qual é a capital?", false},
+		{"", false},
+		{" /help", false},
+		{"a/b", false},
+	}
+
+	for _, tt := range testes {
+		if c := ehComando(tt.linha); c != tt.comando {
+			t.Errorf("ehComando(%q) = %v, esperado %v", tt.linha, c, tt.comando)
+		}
+	}
+}
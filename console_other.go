@@ -0,0 +1,58 @@
+//go:build !windows
+
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+// Fora do Windows, os terminais já interpretam os Escape Codes das cores e a leitura do estado
+// das teclas não está disponível: a resposta não pode ser interrompida pelas teclas ESC e ESPAÇO,
+// nem a narração controlada pelo teclado. Estas versões permitem compilar e testar o aplicativo
+// em outros sistemas.
+
+import (
+	"os"
+)
+
+type (
+	// Teclado sem leitura de estado: nenhuma tecla é considerada pressionada.
+	tecladoConsole struct{}
+)
+
+// Obtém as capacidades da saída: se for um terminal (dispositivo de caracteres), é interativa e
+// tem cores, exceto no terminal "dumb". A largura é obtida da variável de ambiente COLUMNS.
+func capacidadesDoTerminal(f *os.File) Capacidades {
+	c := Capacidades{}
+	if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		c.Interativo = true
+		c.Cores = os.Getenv("TERM") != "dumb"
+	}
+	return capacidadesDoAmbiente(c)
+}
+
+func (tecladoConsole) Pressionada(t int32) bool {
+	return false
+}
+
+func (tecladoConsole) Abaixada(t int32) bool {
+	return false
+}
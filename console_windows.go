@@ -0,0 +1,73 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+type (
+	// Teclado da console do Windows, lido pela função GetKeyState.
+	tecladoConsole struct{}
+)
+
+var (
+	// Para carregar e usar função GetKeyState da API user32.dll do Windows,
+	// que verifica o estado de uma tecla qualquer.
+	user32_dll  = windows.NewLazyDLL("user32.dll")
+	GetKeyState = user32_dll.NewProc("GetKeyState")
+)
+
+// Obtém as capacidades da saída: se for a console do Windows, é interativa, tem cores (se for
+// possível habilitá-las) e a largura da janela. Caso contrário (ex: saída redirecionada), não.
+// Para poder usar o Escape Code para colorir palavras na console, é necessário habilitar primeiro.
+func capacidadesDoTerminal(f *os.File) Capacidades {
+	c := Capacidades{}
+	console := windows.Handle(f.Fd())
+	var modo uint32
+	if windows.GetConsoleMode(console, &modo) == nil {
+		c.Interativo = true
+		c.Cores = windows.SetConsoleMode(console, modo|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+		var info windows.ConsoleScreenBufferInfo
+		if windows.GetConsoleScreenBufferInfo(console, &info) == nil {
+			c.Largura = int(info.Window.Right - info.Window.Left + 1)
+		}
+	}
+	return capacidadesDoAmbiente(c)
+}
+
+// Verifica se pressionou e liberou a tecla informada no parâmetro t.
+// Chama a função GetKeyState da user32.dll, que verifica o estado da tecla informada.
+// Recurso muito útil para varificar se uma tecla foi pressionada sem interromper o loop em que está.
+func (tecladoConsole) Pressionada(t int32) bool {
+	r, _, _ := GetKeyState.Call(uintptr(t))
+	return r == 65409 //Código "mágico" que indica que a tecla foi liberada (event KeyUp).
+}
+
+// Verifica se a tecla está pressionada neste momento (bit mais alto do retorno de GetKeyState).
+func (tecladoConsole) Abaixada(t int32) bool {
+	r, _, _ := GetKeyState.Call(uintptr(t))
+	return r&0x8000 != 0
+}
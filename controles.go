@@ -0,0 +1,256 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// Teclas que controlam a narração enquanto ela está em andamento.
+	// São usadas teclas que não produzem texto, para não atrapalhar a digitação da próxima pergunta.
+	TECLA_F8            = 0x77 // Pausa ou continua a narração.
+	TECLA_PLAY_PAUSE    = 0xB3 // Tecla multimídia Play/Pause: o mesmo que F8.
+	TECLA_DIREITA       = 0x27 // Pula para o próximo trecho.
+	TECLA_PROXIMA_FAIXA = 0xB0 // Tecla multimídia Próxima Faixa: o mesmo que a seta para a direita.
+	TECLA_CIMA          = 0x26 // Aumenta o volume.
+	TECLA_BAIXO         = 0x28 // Diminui o volume.
+	TECLA_PAGE_UP       = 0x21 // Aumenta a velocidade.
+	TECLA_PAGE_DOWN     = 0x22 // Diminui a velocidade.
+
+	VOLUME_MAXIMO     = 100
+	PASSO_VOLUME      = 10
+	VELOCIDADE_MINIMA = 0.5
+	VELOCIDADE_MAXIMA = 2.0
+	PASSO_VELOCIDADE  = 0.25
+)
+
+type (
+	// Controles da narração: interrupção, pausa, pulo de trecho, volume e velocidade.
+	// São alterados pelas teclas pressionadas durante a narração, pela impressão da resposta (ESC)
+	// e pelo comando "/speak", e lidos pela saída de audio enquanto executa cada trecho.
+	// O volume e a velocidade ficam aqui, e não em settings, pois são alterados pelas teclas
+	// em outra goroutine; os campos VOLUME e VELOCIDADE são atualizados ao gravar as configurações.
+	ControleNarracao struct {
+		mutex        sync.Mutex
+		interrompida bool
+		pausado      bool
+		pular        bool
+		volume       int
+		velocidade   float64
+	}
+)
+
+// Prepara os controles para uma nova narração: sem interrupção, sem pausa e sem pulo pendente.
+func (c *ControleNarracao) Inicia() {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	c.interrompida = false
+	c.pausado = false
+	c.pular = false
+}
+
+// Interrompe a narração (tecla ESC): os audios restantes não são baixados nem executados.
+func (c *ControleNarracao) Interrompe() {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	c.interrompida = true
+}
+
+// Indica se a narração foi interrompida.
+func (c *ControleNarracao) Interrompida() bool {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	return c.interrompida
+}
+
+// Pausa a narração, se estiver em andamento, ou continua, se estiver pausada.
+func (c *ControleNarracao) AlternaPausa() {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	c.pausado = !c.pausado
+}
+
+// Indica se a narração está pausada.
+func (c *ControleNarracao) Pausado() bool {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	return c.pausado
+}
+
+// Solicita que o trecho em execução seja interrompido, passando para o próximo.
+func (c *ControleNarracao) Pula() {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	c.pular = true
+}
+
+// Indica se foi solicitado o pulo do trecho em execução. A solicitação é atendida uma única vez.
+func (c *ControleNarracao) ConsomePulo() bool {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	pular := c.pular
+	c.pular = false
+	return pular
+}
+
+// Retorna o volume da narração, de 0 a 1, para a saída de audio.
+func (c *ControleNarracao) Volume() float64 {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	return float64(c.volume) / VOLUME_MAXIMO
+}
+
+// Retorna o volume da narração, de 0 a 100, como no parâmetro VOLUME.
+func (c *ControleNarracao) VolumeAtual() int {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	return c.volume
+}
+
+// Altera o volume da narração (de 0 a 100). Valores fora do intervalo são ajustados ao limite.
+// Retorna o volume efetivamente definido.
+func (c *ControleNarracao) DefineVolume(volume int) int {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	return c.defineVolume(volume)
+}
+
+// Aumenta (ou diminui, se negativo) o volume da narração. Retorna o novo volume.
+func (c *ControleNarracao) AjustaVolume(passo int) int {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	return c.defineVolume(c.volume + passo)
+}
+
+func (c *ControleNarracao) defineVolume(volume int) int {
+	if volume < 0 {
+		volume = 0
+	}
+	if volume > VOLUME_MAXIMO {
+		volume = VOLUME_MAXIMO
+	}
+	c.volume = volume
+	return volume
+}
+
+// Retorna a velocidade da narração (1 é a velocidade normal).
+func (c *ControleNarracao) Velocidade() float64 {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	return c.velocidade
+}
+
+// Altera a velocidade da narração. Valores fora do intervalo são ajustados ao limite.
+// Retorna a velocidade efetivamente definida.
+func (c *ControleNarracao) DefineVelocidade(velocidade float64) float64 {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	return c.defineVelocidade(velocidade)
+}
+
+// Aumenta (ou diminui, se negativo) a velocidade da narração. Retorna a nova velocidade.
+func (c *ControleNarracao) AjustaVelocidade(passo float64) float64 {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	return c.defineVelocidade(c.velocidade + passo)
+}
+
+func (c *ControleNarracao) defineVelocidade(velocidade float64) float64 {
+	if velocidade < VELOCIDADE_MINIMA {
+		velocidade = VELOCIDADE_MINIMA
+	}
+	if velocidade > VELOCIDADE_MAXIMA {
+		velocidade = VELOCIDADE_MAXIMA
+	}
+	c.velocidade = velocidade
+	return velocidade
+}
+
+// Acompanha as teclas pressionadas enquanto a narração está em andamento, até o canal "fim" ser fechado.
+// Cada tecla é tratada apenas uma vez por pressionamento, mesmo se for mantida pressionada.
+func (sessao *Sessao) monitoraTeclas(fim chan struct{}) {
+	abaixadas := make(map[int32]bool)
+
+	// Retorna true apenas no momento em que a tecla passa a ficar pressionada.
+	pressionou := func(teclas ...int32) bool {
+		resultado := false
+		for _, t := range teclas {
+			abaixada := teclaAbaixada(t)
+			if abaixada && !abaixadas[t] {
+				resultado = true
+			}
+			abaixadas[t] = abaixada
+		}
+		return resultado
+	}
+
+	for {
+		select {
+		case <-fim:
+			return
+		case <-time.After(time.Millisecond * 10):
+		}
+
+		if pressionou(TECLA_ESC) {
+			sessao.controle.Interrompe()
+		}
+		if pressionou(TECLA_F8, TECLA_PLAY_PAUSE) {
+			sessao.controle.AlternaPausa()
+		}
+		if pressionou(TECLA_DIREITA, TECLA_PROXIMA_FAIXA) {
+			sessao.controle.Pula()
+		}
+		if pressionou(TECLA_CIMA) {
+			sessao.controle.AjustaVolume(PASSO_VOLUME)
+		}
+		if pressionou(TECLA_BAIXO) {
+			sessao.controle.AjustaVolume(-PASSO_VOLUME)
+		}
+		if pressionou(TECLA_PAGE_UP) {
+			sessao.controle.AjustaVelocidade(PASSO_VELOCIDADE)
+		}
+		if pressionou(TECLA_PAGE_DOWN) {
+			sessao.controle.AjustaVelocidade(-PASSO_VELOCIDADE)
+		}
+	}
+}
+
+// Narra novamente a última resposta, aguardando o fim da narração.
+// Os controles de teclado valem também aqui (ESC interrompe).
+func (sessao *Sessao) repeteNarracao() error {
+	resposta, err := sessao.ultimaResposta()
+	if err != nil {
+		return err
+	}
+
+	blocos := blocosParaFala(resposta, sessao.settings.IDIOMA)
+	if len(blocos) == 0 {
+		return novoErro("a resposta não tem texto para narrar")
+	}
+
+	sessao.iniciaNarracao(blocos, sessao.settings.IDIOMA)
+	sessao.narracao.Aguarda()
+	return nil
+}
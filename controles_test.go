@@ -0,0 +1,72 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"testing"
+)
+
+// O volume e a velocidade fora dos limites são ajustados ao limite mais próximo.
+func TestControleNarracaoLimites(t *testing.T) {
+	testes := []struct {
+		nome       string
+		altera     func(c *ControleNarracao)
+		volume     int
+		velocidade float64
+	}{
+		{"valores iniciais", func(c *ControleNarracao) {}, VOLUME_MAXIMO, 1},
+		{"volume no intervalo", func(c *ControleNarracao) { c.DefineVolume(40) }, 40, 1},
+		{"volume acima do máximo", func(c *ControleNarracao) { c.DefineVolume(150) }, VOLUME_MAXIMO, 1},
+		{"volume negativo", func(c *ControleNarracao) { c.DefineVolume(-5) }, 0, 1},
+		{"aumenta volume no máximo", func(c *ControleNarracao) { c.AjustaVolume(PASSO_VOLUME) }, VOLUME_MAXIMO, 1},
+		{"diminui volume abaixo de zero", func(c *ControleNarracao) {
+			c.DefineVolume(5)
+			c.AjustaVolume(-PASSO_VOLUME)
+		}, 0, 1},
+		{"velocidade no intervalo", func(c *ControleNarracao) { c.DefineVelocidade(1.5) }, VOLUME_MAXIMO, 1.5},
+		{"velocidade acima da máxima", func(c *ControleNarracao) { c.DefineVelocidade(3) }, VOLUME_MAXIMO, VELOCIDADE_MAXIMA},
+		{"velocidade abaixo da mínima", func(c *ControleNarracao) { c.DefineVelocidade(0.1) }, VOLUME_MAXIMO, VELOCIDADE_MINIMA},
+		{"acelera na velocidade máxima", func(c *ControleNarracao) {
+			c.DefineVelocidade(VELOCIDADE_MAXIMA)
+			c.AjustaVelocidade(PASSO_VELOCIDADE)
+		}, VOLUME_MAXIMO, VELOCIDADE_MAXIMA},
+		{"desacelera na velocidade mínima", func(c *ControleNarracao) {
+			c.DefineVelocidade(0.6)
+			c.AjustaVelocidade(-PASSO_VELOCIDADE)
+		}, VOLUME_MAXIMO, VELOCIDADE_MINIMA},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			c := &ControleNarracao{volume: VOLUME_MAXIMO, velocidade: 1}
+			tt.altera(c)
+			if c.VolumeAtual() != tt.volume || c.Velocidade() != tt.velocidade {
+				t.Errorf("volume = %d, velocidade = %.2f; esperado %d e %.2f",
+					c.VolumeAtual(), c.Velocidade(), tt.volume, tt.velocidade)
+			}
+			if v := c.Volume(); v != float64(tt.volume)/VOLUME_MAXIMO {
+				t.Errorf("Volume() = %.2f, esperado %.2f", v, float64(tt.volume)/VOLUME_MAXIMO)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// Pasta onde cada conversa é gravada (um arquivo JSON por conversa, no formato do "/export json").
+	CONVERSAS = "conversas"
+)
+
+func init() {
+	registraSubcomando(&Subcomando{
+		Nome:       "sessions",
+		Aliases:    []string{"search"},
+		Argumentos: "[termos]",
+		Descricao: "Lista as conversas gravadas na pasta ./conversas ou, se informados os termos,\n" +
+			"procura-os nas conversas (ex: \033[36mgpt sessions ponteiros go\033[m).",
+		Executa: func(sessao *Sessao, args []string) (string, error) {
+			sessao.encerrada = true
+			if len(args) > 0 {
+				sessao.buscaConversas(args)
+				return "", nil
+			}
+			return "", sessao.listaConversas()
+		},
+	})
+}
+
+// Grava a conversa atual na pasta ./conversas e atualiza o índice de busca.
+// Só grava se o parâmetro SALVA_CONVERSAS do arquivo settings.json estiver ativo.
+func (sessao *Sessao) gravaConversa() error {
+	messages := sessao.conversa.Mensagens()
+	if !sessao.settings.SALVA_CONVERSAS || len(messages) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(CONVERSAS, 0700); err != nil {
+		return err
+	}
+
+	if sessao.arquivoConversa == "" {
+		arquivo, err := criaArquivoConversa()
+		if err != nil {
+			return err
+		}
+		sessao.arquivoConversa = arquivo
+	}
+
+	if err := sessao.exportaConversa(FORMATO_JSON, sessao.arquivoConversa); err != nil {
+		return err
+	}
+
+	return atualizaIndice(sessao.arquivoConversa, messages)
+}
+
+// Cria, vazio, o arquivo de uma nova conversa, com o nome formado pela data e hora até os microssegundos
+// (para ordenar as conversas pelo nome e não sobrescrever as iniciadas no mesmo segundo). Se o arquivo
+// já existir (ex: criado por outra execução no mesmo instante), tenta novamente com o horário seguinte.
+func criaArquivoConversa() (string, error) {
+	for {
+		arquivo := filepath.Join(CONVERSAS, time.Now().Format("20060102-150405.000000")+".json")
+		f, err := os.OpenFile(arquivo, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			return arquivo, f.Close()
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+	}
+}
+
+// Lista as conversas gravadas, da mais recente para a mais antiga, com a primeira pergunta de cada uma.
+func (sessao *Sessao) listaConversas() error {
+	arquivos, err := filepath.Glob(filepath.Join(CONVERSAS, "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(arquivos)))
+
+	quantidade := 0
+	for _, arquivo := range arquivos {
+		if filepath.Base(arquivo) == INDICE {
+			continue
+		}
+		messages, err := leConversa(arquivo)
+		if err != nil {
+			sessao.tela.Erro(err)
+			continue
+		}
+
+		pergunta := ""
+		for _, m := range messages {
+			if m.Role == "user" {
+				pergunta = strings.Join(strings.Fields(m.Content), " ")
+				break
+			}
+		}
+		if len([]rune(pergunta)) > TAMANHO_TRECHO {
+			pergunta = string([]rune(pergunta)[:TAMANHO_TRECHO]) + "..."
+		}
+
+		sessao.tela.Printf(traduz("\033[96m%s\033[m (%d mensagens) %s\r\n"), arquivo, len(messages), pergunta)
+		quantidade++
+	}
+
+	if quantidade == 0 {
+		sessao.tela.Println(traduz("Nenhuma conversa gravada."))
+	}
+	return nil
+}
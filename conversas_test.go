@@ -0,0 +1,64 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// As conversas iniciadas no mesmo segundo (ex: após o "/reset", ou em execuções seguidas do
+// "gpt ask") são gravadas em arquivos diferentes, sem sobrescrever as anteriores no índice de busca.
+func TestGravaConversaNomeUnico(t *testing.T) {
+	_, sessao := novoServidorFake(t)
+	sessao.settings.SALVA_CONVERSAS = true
+
+	perguntas := []string{"Primeira pergunta", "Segunda pergunta", "Terceira pergunta"}
+	capturaSaida(t, sessao, func() {
+		for _, p := range perguntas {
+			sessao.conversa.Reinicia()
+			sessao.arquivoConversa = ""
+			if !sessao.respondePergunta(p) {
+				t.Fatalf("pergunta %q não foi respondida", p)
+			}
+		}
+	})
+
+	arquivos, err := filepath.Glob(filepath.Join(CONVERSAS, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(arquivos); n != len(perguntas)+1 {
+		t.Fatalf("%d arquivos gravados (com o índice), esperado %d: %q", n, len(perguntas)+1, arquivos)
+	}
+
+	indice, err := carregaIndice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"primeira", "segunda", "terceira"} {
+		if r := indice.Busca([]string{p}); len(r) != 1 {
+			t.Errorf("busca por %q: %d resultados, esperado 1", p, len(r))
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type (
+	// Detalhes de cada turno gravados na exportação em JSON. Ficam separados do campo
+	// "messages" para que este continue compatível com o formato de mensagens da API.
+	TurnoExportado struct {
+		Index     int       `json:"index"`
+		Role      string    `json:"role"`
+		Timestamp time.Time `json:"timestamp"`
+		Model     string    `json:"model,omitempty"`
+		Usage     *Usage    `json:"usage,omitempty"`
+	}
+
+	// Estrutura gravada no arquivo quando o formato da exportação é "json".
+	ConversaExportada struct {
+		Model      string           `json:"model"`
+		ExportedAt time.Time        `json:"exported_at"`
+		Messages   []Message        `json:"messages"`
+		Turns      []TurnoExportado `json:"turns"`
+	}
+)
+
+const (
+	FORMATO_MARKDOWN = "md"
+	FORMATO_HTML     = "html"
+	FORMATO_JSON     = "json"
+
+	FORMATO_DATA = "2006-01-02 15:04:05"
+)
+
+// Instrução de idioma que falador.InstrucaoIdioma acrescenta a cada pergunta. É removida do texto
+// exportado em Markdown e HTML, por não fazer parte do que o usuário digitou.
+var instrucaoIdioma = regexp.MustCompile(` \(You must answer in "[^"]*"\)$`)
+
+func init() {
+	registraComando(&Comando{
+		Nome:       "export",
+		Argumentos: "[md|html|json] <arquivo>",
+		Descricao: "Grava a conversa no arquivo informado.\r\n" +
+			"Se o formato for omitido, é deduzido pela extensão do arquivo.",
+		MinArgs: 1,
+		MaxArgs: 2,
+		Executa: (*Sessao).trataComandoExport,
+	})
+}
+
+// Trata o comando "/export <formato> <arquivo>" digitado no modo interativo.
+// O formato pode ser omitido ("/export <arquivo>"); neste caso é obtido pela extensão do arquivo.
+func (sessao *Sessao) trataComandoExport(args []string) string {
+	formato, caminho := "", args[len(args)-1]
+	if len(args) == 2 {
+		formato = strings.ToLower(args[0])
+	}
+
+	if err := sessao.exportaConversa(formato, caminho); err != nil {
+		sessao.tela.Erro(err)
+		return ""
+	}
+	sessao.tela.Printf(traduz("Conversa exportada para \"%s\""), caminho)
+	return ""
+}
+
+// Grava o histórico de mensagens no arquivo informado, no formato solicitado.
+// Se o formato estiver vazio, é deduzido pela extensão do arquivo.
+func (sessao *Sessao) exportaConversa(formato, caminho string) error {
+	if formato == "" {
+		formato = formatoPorExtensao(caminho)
+	}
+
+	messages := sessao.conversa.Mensagens()
+	if len(messages) == 0 {
+		return novoErro("não há mensagens na conversa para exportar")
+	}
+
+	buf := &bytes.Buffer{}
+	switch formato {
+	case FORMATO_MARKDOWN, "markdown":
+		exportaMarkdown(buf, messages)
+	case FORMATO_HTML, "htm":
+		exportaHTML(buf, messages)
+	case FORMATO_JSON:
+		if err := sessao.exportaJSON(buf, messages); err != nil {
+			return err
+		}
+	default:
+		return novoErro("formato de exportação \"%s\" inválido. Use md, html ou json", formato)
+	}
+
+	return os.WriteFile(caminho, buf.Bytes(), 0600)
+}
+
+// Obtém o formato da exportação pela extensão do arquivo. Sem extensão conhecida, usa Markdown.
+func formatoPorExtensao(caminho string) string {
+	switch strings.ToLower(filepath.Ext(caminho)) {
+	case ".html", ".htm":
+		return FORMATO_HTML
+	case ".json":
+		return FORMATO_JSON
+	}
+	return FORMATO_MARKDOWN
+}
+
+// Retorna o conteúdo da mensagem sem a instrução de idioma acrescentada às perguntas.
+func textoDaMensagem(m Message) string {
+	if m.Role == "user" {
+		return instrucaoIdioma.ReplaceAllString(m.Content, "")
+	}
+	return m.Content
+}
+
+// Monta o título de cada turno: "Pergunta" ou "Resposta", no idioma da interface, com horário,
+// modelo e tokens consumidos.
+func tituloDoTurno(m Message) string {
+	titulo := traduz("Pergunta")
+	switch m.Role {
+	case "assistant":
+		titulo = traduz("Resposta")
+	case "system":
+		titulo = traduz("Sistema")
+	}
+
+	detalhes := make([]string, 0, 3)
+	if !m.Horario.IsZero() {
+		detalhes = append(detalhes, m.Horario.Format(FORMATO_DATA))
+	}
+	if m.Modelo != "" {
+		detalhes = append(detalhes, m.Modelo)
+	}
+	if m.Uso != nil {
+		detalhes = append(detalhes, fmt.Sprintf("%d+%d=%d tokens", m.Uso.PromptTokens, m.Uso.CompletionTokens, m.Uso.TotalTokens))
+	}
+
+	if len(detalhes) == 0 {
+		return titulo
+	}
+	return fmt.Sprintf("%s (%s)", titulo, strings.Join(detalhes, " · "))
+}
+
+// Exporta a conversa no formato Markdown. Cada turno é um título de nível 2 seguido do texto.
+func exportaMarkdown(w io.Writer, msgs []Message) {
+	fmt.Fprintf(w, "# %s\n\n", traduz("GPT-Falador - Conversa"))
+	fmt.Fprintf(w, "%s\n", traduzf("Exportada em %s", time.Now().Format(FORMATO_DATA)))
+
+	for _, m := range msgs {
+		fmt.Fprintf(w, "\n## %s\n\n%s\n", tituloDoTurno(m), strings.TrimSpace(textoDaMensagem(m)))
+	}
+}
+
+// Exporta a conversa como uma página HTML completa (sem dependências externas).
+func exportaHTML(w io.Writer, msgs []Message) {
+	titulo := html.EscapeString(traduz("GPT-Falador - Conversa"))
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 2em auto; color: #222; }
+.turno { border-radius: 6px; padding: 0.5em 1em; margin: 1em 0; }
+.user { background: #e8f5e9; }
+.assistant { background: #e3f2fd; }
+.system { background: #f5f5f5; }
+h2 { font-size: 0.9em; color: #555; }
+pre { background: #272822; color: #f8f8f2; padding: 0.8em; overflow-x: auto; }
+code { color: #00838f; }
+pre code { color: inherit; }
+</style>
+</head>
+<body>
+<h1>%s</h1>
+`, titulo, titulo)
+	fmt.Fprintf(w, "<p>%s</p>\n", html.EscapeString(traduzf("Exportada em %s", time.Now().Format(FORMATO_DATA))))
+
+	for _, m := range msgs {
+		fmt.Fprintf(w, "<div class=\"turno %s\">\n<h2>%s</h2>\n%s\n</div>\n",
+			html.EscapeString(m.Role), html.EscapeString(tituloDoTurno(m)), textoParaHTML(textoDaMensagem(m)))
+	}
+
+	fmt.Fprint(w, "</body>\n</html>\n")
+}
+
+// Converte os marcadores de código usados pelo ChatGPT ("```" e "`") para HTML.
+// O restante do texto é escapado e as quebras de linha são preservadas.
+func textoParaHTML(s string) string {
+	sb := &strings.Builder{}
+
+	// As posições pares são texto normal e as ímpares são blocos de código.
+	for i, trecho := range strings.Split(s, "```") {
+		if i%2 == 1 {
+			// A primeira linha do bloco pode conter a linguagem (ex: ```go). Ela não é impressa.
+			if n := strings.Index(trecho, "\n"); n >= 0 && !strings.ContainsAny(trecho[:n], " \t") {
+				trecho = trecho[n+1:]
+			}
+			sb.WriteString("<pre><code>" + html.EscapeString(strings.TrimRight(trecho, "\n")) + "</code></pre>")
+			continue
+		}
+
+		for j, parte := range strings.Split(trecho, "`") {
+			parte = html.EscapeString(parte)
+			if j%2 == 1 {
+				sb.WriteString("<code>" + parte + "</code>")
+				continue
+			}
+			sb.WriteString(strings.ReplaceAll(parte, "\n", "<br>\n"))
+		}
+	}
+
+	return sb.String()
+}
+
+// Exporta a conversa em JSON. O campo "messages" pode ser reenviado diretamente para a API.
+func (sessao *Sessao) exportaJSON(w io.Writer, msgs []Message) error {
+	conversa := ConversaExportada{
+		Model:      sessao.settings.GPT_MODEL,
+		ExportedAt: time.Now(),
+		Messages:   msgs,
+		Turns:      make([]TurnoExportado, 0, len(msgs)),
+	}
+
+	for i, m := range msgs {
+		conversa.Turns = append(conversa.Turns, TurnoExportado{
+			Index:     i,
+			Role:      m.Role,
+			Timestamp: m.Horario,
+			Model:     m.Modelo,
+			Usage:     m.Uso,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(conversa)
+}
@@ -0,0 +1,374 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	CHAVE_TESTE = "chave-de-teste"
+)
+
+type (
+	// Servidor falso que responde como a API de chat da OpenAI (/v1/chat/completions)
+	// e como o TTS do Google (/translate_tts), para testar o aplicativo sem acessar a internet.
+	servidorFake struct {
+		*httptest.Server
+
+		mutex       sync.Mutex
+		requisicoes []ChatGPTRequest // Requisições recebidas pela API de chat.
+		consultas   []url.Values     // Parâmetros recebidos pelo TTS.
+
+		// Respostas do servidor. Podem ser alteradas pelo teste antes das requisições.
+		Resposta  string        // Conteúdo da resposta da API de chat.
+		ErroChat  string        // Se informado, a API de chat responde com este erro.
+		CorpoChat string        // Se informado, a API de chat responde com este conteúdo (ex: json inválido).
+		Atraso    time.Duration // Tempo que a API de chat demora a responder.
+		StatusTTS int           // Status HTTP do TTS.
+		Audio     []byte        // Audio retornado pelo TTS.
+	}
+
+	// Teclado simulado: as teclas "pressionadas" pelo teste são informadas uma única vez
+	// às leituras do aplicativo, como ocorre ao pressionar e liberar a tecla.
+	tecladoFake struct {
+		mutex        sync.Mutex
+		pressionadas map[int32]bool
+	}
+
+	// Dispositivo de audio nulo: consome o audio no ritmo em que seria executado, sem reproduzi-lo.
+	// Assim, a narração (e a impressão da resposta que a acompanha) se comporta como com o
+	// dispositivo real, sem depender da placa de som nem do sistema operacional.
+	dispositivoNulo struct {
+		mutex    sync.Mutex
+		fonte    io.Reader
+		pausado  bool
+		volume   float64
+		pendente int // Bytes lidos da fonte e ainda não "executados".
+	}
+)
+
+// Cria o servidor falso e a sessão do teste, com os parâmetros URL_API e TTS_URL apontando para ele.
+func novoServidorFake(t *testing.T) (*servidorFake, *Sessao) {
+	t.Helper()
+
+	f := &servidorFake{
+		Resposta:  "Resposta do servidor falso.",
+		StatusTTS: http.StatusOK,
+		Audio:     []byte("audio falso"),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/chat/completions", f.chat)
+	mux.HandleFunc("/translate_tts", f.tts)
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+
+	sessao := configuraTeste(t)
+	sessao.settings.URL_API = f.URL + "/v1/chat/completions"
+	sessao.settings.TTS_URL = f.URL + "/translate_tts"
+	sessao.settings.API_KEY = CHAVE_TESTE
+	return f, sessao
+}
+
+// Cria um servidor que não responde às requisições (até o cliente desistir) e aponta
+// os parâmetros URL_API e TTS_URL para ele, com o parâmetro TIMEOUT de 1 segundo.
+func novoServidorLento(t *testing.T) *Sessao {
+	t.Helper()
+
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Lê a requisição, para o servidor perceber quando o cliente desiste da mesma.
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-time.After(time.Minute):
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(servidor.Close)
+
+	sessao := configuraTeste(t)
+	sessao.settings.URL_API = servidor.URL + "/v1/chat/completions"
+	sessao.settings.TTS_URL = servidor.URL + "/translate_tts"
+	sessao.settings.TIMEOUT = 1
+	return sessao
+}
+
+// Cria a sessão do teste, sem argumentos, com a entrada vazia, a saída descartada e o dispositivo de
+// audio nulo. O teste é executado em uma pasta temporária (as pastas ./audio e ./conversas são relativas).
+func configuraTeste(t *testing.T) *Sessao {
+	t.Helper()
+
+	idiomaOriginal := idiomaInterface()
+	pasta, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	sessao := novaSessao([]string{"gpt"}, strings.NewReader(""), io.Discard, io.Discard)
+	sessao.abreDispositivo = abreDispositivoNulo
+	*sessao.settings = Settings{
+		GPT_MODEL:  "gpt-teste",
+		TIMEOUT:    10,
+		IDIOMA:     "pt-BR",
+		MAX_DELAY:  1,
+		VOLUME:     VOLUME_MAXIMO,
+		VELOCIDADE: 1,
+		UI_LANG:    IDIOMA_INTERFACE_PADRAO,
+	}
+	sessao.carregadas = *sessao.settings
+	defineIdiomaInterface(IDIOMA_INTERFACE_PADRAO)
+
+	t.Cleanup(func() {
+		// Interrompe a narração iniciada pelo teste, que usa a pasta atual.
+		sessao.controle.Interrompe()
+		sessao.narracao.Aguarda()
+
+		idiomaInterfaceAtual.Store(idiomaOriginal)
+		os.Chdir(pasta)
+	})
+	return sessao
+}
+
+// Substitui a entrada do modo interativo pelas linhas informadas.
+func entradaTeste(sessao *Sessao, linhas ...string) {
+	sessao.entrada = bufio.NewReader(strings.NewReader(strings.Join(linhas, "\n") + "\n"))
+}
+
+// Executa a função, capturando e retornando o que ela imprimiu na tela, com as cores,
+// como se a saída fosse um terminal.
+func capturaSaida(t *testing.T, sessao *Sessao, f func()) string {
+	t.Helper()
+	return capturaSaidaCom(t, sessao, Capacidades{Cores: true, Largura: LARGURA_PADRAO, Interativo: true}, f)
+}
+
+// Executa a função, capturando e retornando o que ela imprimiu na tela com as capacidades informadas.
+func capturaSaidaCom(t *testing.T, sessao *Sessao, c Capacidades, f func()) string {
+	t.Helper()
+
+	original := sessao.tela
+	buf := &bytes.Buffer{}
+	sessao.tela = NovoRenderizador(buf, c)
+	defer func() { sessao.tela = original }()
+
+	f()
+
+	// A narração iniciada pela função também escreve na tela (ex: erros de download).
+	sessao.narracao.Aguarda()
+	return buf.String()
+}
+
+func (f *servidorFake) chat(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+CHAVE_TESTE {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"message": "chave inválida"}})
+		return
+	}
+
+	req := ChatGPTRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f.mutex.Lock()
+	f.requisicoes = append(f.requisicoes, req)
+	resposta, erro, corpo, atraso := f.Resposta, f.ErroChat, f.CorpoChat, f.Atraso
+	f.mutex.Unlock()
+
+	// Simula a demora da API. Se o cliente desistir (timeout), responde de imediato.
+	select {
+	case <-time.After(atraso):
+	case <-r.Context().Done():
+		return
+	}
+
+	if corpo != "" {
+		w.Write([]byte(corpo))
+		return
+	}
+
+	if erro != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"message": erro, "type": "invalid_request_error"}})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     "chatcmpl-teste",
+		"object": "chat.completion",
+		"model":  req.Model,
+		"usage":  map[string]int{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+		"choices": []map[string]interface{}{{
+			"index":         0,
+			"finish_reason": "stop",
+			"message":       map[string]string{"role": "assistant", "content": resposta},
+		}},
+	})
+}
+
+func (f *servidorFake) tts(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	f.consultas = append(f.consultas, r.URL.Query())
+	status, audio := f.StatusTTS, f.Audio
+	f.mutex.Unlock()
+
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "audio/mpeg")
+	w.Write(audio)
+}
+
+// Requisições recebidas pela API de chat.
+func (f *servidorFake) Requisicoes() []ChatGPTRequest {
+	defer f.mutex.Unlock()
+	f.mutex.Lock()
+	return append([]ChatGPTRequest(nil), f.requisicoes...)
+}
+
+// Parâmetros recebidos pelo TTS.
+func (f *servidorFake) Consultas() []url.Values {
+	defer f.mutex.Unlock()
+	f.mutex.Lock()
+	return append([]url.Values(nil), f.consultas...)
+}
+
+func abreDispositivoNulo(fonte io.Reader) (dispositivoAudio, error) {
+	d := &dispositivoNulo{fonte: fonte, pausado: true, volume: 1}
+	go d.executa()
+	return d, nil
+}
+
+// A cada 10 ms, completa o buffer com o audio da fonte e descarta 10 ms de audio.
+// Como no player do oto, a fonte é lida com a mutex do dispositivo bloqueada.
+func (d *dispositivoNulo) executa() {
+	buf := make([]byte, BUFFER_SAIDA)
+	for {
+		time.Sleep(time.Millisecond * 10)
+
+		d.mutex.Lock()
+		if !d.pausado {
+			n, _ := d.fonte.Read(buf[:BUFFER_SAIDA-d.pendente])
+			d.pendente += n
+
+			executados := TAXA_SAIDA * 4 / 100
+			if executados > d.pendente {
+				executados = d.pendente
+			}
+			d.pendente -= executados
+		}
+		d.mutex.Unlock()
+	}
+}
+
+func (d *dispositivoNulo) Pause() {
+	defer d.mutex.Unlock()
+	d.mutex.Lock()
+	d.pausado = true
+}
+
+func (d *dispositivoNulo) Play() {
+	defer d.mutex.Unlock()
+	d.mutex.Lock()
+	d.pausado = false
+}
+
+func (d *dispositivoNulo) Volume() float64 {
+	defer d.mutex.Unlock()
+	d.mutex.Lock()
+	return d.volume
+}
+
+func (d *dispositivoNulo) SetVolume(volume float64) {
+	defer d.mutex.Unlock()
+	d.mutex.Lock()
+	d.volume = volume
+}
+
+func (d *dispositivoNulo) UnplayedBufferSize() int {
+	defer d.mutex.Unlock()
+	d.mutex.Lock()
+	return d.pendente
+}
+
+// Gera um MP3 de silêncio com a quantidade de quadros informada (cada quadro tem 1152 amostras,
+// cerca de 26ms a 44100Hz). Os quadros só têm o cabeçalho (MPEG-1 Layer III, 128kbps, mono);
+// o restante zerado é decodificado como silêncio.
+func mp3Silencioso(quadros int) []byte {
+	quadro := make([]byte, 417)
+	copy(quadro, []byte{0xFF, 0xFB, 0x90, 0xC4})
+
+	audio := make([]byte, 0, len(quadro)*quadros)
+	for i := 0; i < quadros; i++ {
+		audio = append(audio, quadro...)
+	}
+	return audio
+}
+
+// Substitui o teclado pelo teclado simulado até o fim do teste, que narra na sessão informada.
+func novoTecladoFake(t *testing.T, sessao *Sessao) *tecladoFake {
+	original := teclado
+	f := &tecladoFake{pressionadas: make(map[int32]bool)}
+	teclado = f
+	t.Cleanup(func() {
+		// Aguarda a narração, que lê o teclado em outra goroutine, antes de restaurar o original.
+		sessao.controle.Interrompe()
+		sessao.narracao.Aguarda()
+		teclado = original
+	})
+	return f
+}
+
+// Simula o pressionamento da tecla: a próxima leitura da mesma indica que foi pressionada.
+func (f *tecladoFake) Pressiona(t int32) {
+	defer f.mutex.Unlock()
+	f.mutex.Lock()
+	f.pressionadas[t] = true
+}
+
+func (f *tecladoFake) Pressionada(t int32) bool {
+	defer f.mutex.Unlock()
+	f.mutex.Lock()
+	pressionada := f.pressionadas[t]
+	delete(f.pressionadas, t)
+	return pressionada
+}
+
+func (f *tecladoFake) Abaixada(t int32) bool {
+	return false
+}
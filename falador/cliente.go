@@ -0,0 +1,198 @@
+package falador
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type (
+	// Cliente da API de chat da OpenAI (ou de outro provedor compatível com a mesma API).
+	// Os campos podem ser alterados entre as requisições, mas não durante as mesmas.
+	Client struct {
+		URL         string        // Endereço da API de chat. Ex: "https://api.openai.com/v1/chat/completions"
+		APIKey      string        // Chave de acesso à API.
+		Modelo      string        // Modelo que responde às perguntas. Ex: "gpt-3.5-turbo"
+		Temperatura float32       // Valor na faixa de 0.0 a 2.0.
+		Timeout     time.Duration // Tempo máximo a aguardar pela resposta. Se 0, aguarda indefinidamente.
+
+		// Cliente HTTP usado nas requisições. Se nil, usa o http.DefaultClient.
+		HTTP *http.Client
+	}
+
+	// Resposta da API a uma requisição.
+	Resposta struct {
+		Mensagem     Message        // Mensagem do assistente, com o modelo e os tokens consumidos.
+		FinishReason string         // Motivo do fim da resposta (ex: "stop", "length").
+		Duracao      time.Duration  // Tempo entre o envio da requisição e a resposta da API.
+		Resultado    *ChatGPTResult // Estrutura completa retornada pela API.
+		JSON         []byte         // Conteúdo json retornado pela API (payload).
+	}
+)
+
+const (
+	URL_API_PADRAO = "https://api.openai.com/v1/chat/completions"
+
+	// Final do endereço da API de chat. Os demais recursos da API (ex: "audio/speech")
+	// ficam no mesmo endereço, trocando-se este final.
+	RECURSO_CHAT = "chat/completions"
+)
+
+// Retornado quando a API não responde dentro do tempo definido no campo Timeout.
+var ErrTempoEsgotado = errors.New("servidor demorou a responder")
+
+// Cria o cliente da API com o endereço e a chave de acesso informados.
+// Se o endereço estiver vazio, usa o da API da OpenAI.
+func NovoCliente(url, apiKey string) *Client {
+	if url == "" {
+		url = URL_API_PADRAO
+	}
+	return &Client{URL: url, APIKey: apiKey}
+}
+
+// Retorna o endereço de outro recurso da API (ex: "audio/speech", "audio/transcriptions").
+func (c *Client) Endereco(recurso string) string {
+	return strings.TrimSuffix(c.URL, RECURSO_CHAT) + recurso
+}
+
+// Acrescenta à requisição o cabeçalho com a chave de acesso à API.
+func (c *Client) Autoriza(req *http.Request) {
+	req.Header.Add("Authorization", "Bearer "+c.APIKey)
+}
+
+// Retorna o cliente HTTP para os demais recursos da API (ex: "audio/speech"), baseado no campo HTTP
+// e limitado pelo tempo do campo Timeout, que inclui a leitura da resposta.
+func (c *Client) ClienteHTTP() *http.Client {
+	httpClient := &http.Client{}
+	if c.HTTP != nil {
+		*httpClient = *c.HTTP
+	}
+	if c.Timeout > 0 {
+		httpClient.Timeout = c.Timeout
+	}
+	return httpClient
+}
+
+// Envia as mensagens para a API e aguarda a resposta.
+// Se a API responder com erro, retorna um *ErroAPI com os detalhes do mesmo; se a resposta
+// não for um json, retorna um *ErroStatus com o status HTTP e o conteúdo retornado.
+// Se o tempo definido no campo Timeout esgotar, retorna ErrTempoEsgotado.
+func (c *Client) Envia(ctx context.Context, mensagens []Message) (*Resposta, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	// Converte a estrutura do objeto para array de bytes no formato json
+	reqBytes, err := json.Marshal(&ChatGPTRequest{
+		Model:       c.Modelo,
+		Messages:    mensagens,
+		Temperature: c.Temperatura,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Envia a requisição com o método POST para a API do ChatGPT.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	c.Autoriza(req)
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	inicio := time.Now()
+	res, err := httpClient.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, ErrTempoEsgotado
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// Lê todo o conteúdo retornado pela API
+	retBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, ErrTempoEsgotado
+		}
+		return nil, err
+	}
+	duracao := time.Since(inicio)
+
+	retorno := &ChatGPTResult{}
+	if err = json.Unmarshal(retBody, retorno); err != nil {
+		if res.StatusCode != http.StatusOK {
+			return nil, &ErroStatus{Status: res.StatusCode, Conteudo: strings.TrimSpace(string(retBody))}
+		}
+		return nil, err
+	}
+
+	// Se retornou algum erro na estrutura normal de retorno, o campo Choices vem vazio.
+	// E o campo "error" contém os detalhes.
+	if len(retorno.Choices) == 0 {
+		return nil, decodificaErro(res.StatusCode, retBody)
+	}
+
+	escolha := retorno.Choices[0]
+	return &Resposta{
+		Mensagem: Message{
+			Role:    escolha.Message.Role,
+			Content: escolha.Message.Content,
+			Horario: time.Now(),
+			Modelo:  retorno.Model,
+			Uso:     &retorno.Usage,
+		},
+		FinishReason: escolha.FinishReason,
+		Duracao:      duracao,
+		Resultado:    retorno,
+		JSON:         retBody,
+	}, nil
+}
+
+// Obtém o erro do campo "error" retornado pela API. Se o conteúdo não tiver
+// esse campo, o próprio conteúdo é usado como mensagem do erro.
+func decodificaErro(status int, corpo []byte) error {
+	var erro struct {
+		Error *ErroAPI `json:"error"`
+	}
+	if err := json.Unmarshal(corpo, &erro); err != nil || erro.Error == nil {
+		return &ErroAPI{Status: status, Mensagem: strings.TrimSpace(string(corpo))}
+	}
+	erro.Error.Status = status
+	return erro.Error
+}
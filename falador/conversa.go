@@ -0,0 +1,105 @@
+package falador
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type (
+	// Conversa com a IA: guarda o histórico das mensagens trocadas, que é enviado à API
+	// a cada nova pergunta para que a IA mantenha o contexto da conversa.
+	// O acesso ao histórico é protegido por mutex, podendo ser lido enquanto a pergunta é enviada.
+	Conversation struct {
+		Cliente *Client // Cliente usado para enviar as perguntas.
+		Idioma  string  // Idioma em que a IA deve responder. Se vazio, não instrui o idioma.
+
+		mutex     sync.Mutex
+		mensagens []Message
+	}
+)
+
+// Cria uma conversa, sem histórico, que envia as perguntas pelo cliente informado.
+func NovaConversa(cliente *Client, idioma string) *Conversation {
+	return &Conversation{Cliente: cliente, Idioma: idioma, mensagens: make([]Message, 0)}
+}
+
+// Envia a pergunta à IA, junto com o histórico da conversa, e aguarda a resposta.
+// A pergunta e a resposta só são acrescentadas ao histórico se a API responder com sucesso;
+// assim, a pergunta que falhou (ex: por timeout) não é repetida nos próximos envios.
+func (c *Conversation) Pergunta(ctx context.Context, pergunta string) (*Resposta, error) {
+	if c.Cliente == nil {
+		return nil, errors.New("conversa sem cliente da API")
+	}
+
+	// Cria a mensagem com a pergunta do usuário. Instrui a IA a responder no idiomna selecionado.
+	msg := Message{
+		Role:    "user",
+		Content: InstrucaoIdioma(pergunta, c.Idioma),
+		Horario: time.Now(),
+	}
+
+	resposta, err := c.Cliente.Envia(ctx, append(c.Mensagens(), msg))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Adiciona(msg, resposta.Mensagem)
+	return resposta, nil
+}
+
+// Retorna uma cópia do histórico de mensagens da conversa.
+func (c *Conversation) Mensagens() []Message {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	return append(make([]Message, 0, len(c.mensagens)+1), c.mensagens...)
+}
+
+// Acrescenta as mensagens ao histórico da conversa (ex: histórico importado de um arquivo).
+func (c *Conversation) Adiciona(mensagens ...Message) {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	c.mensagens = append(c.mensagens, mensagens...)
+}
+
+// Apaga o histórico da conversa. A IA perde o contexto das perguntas anteriores.
+func (c *Conversation) Reinicia() {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	c.mensagens = c.mensagens[:0:0]
+}
+
+// Retorna a última resposta da IA na conversa.
+func (c *Conversation) UltimaResposta() (Message, bool) {
+	defer c.mutex.Unlock()
+	c.mutex.Lock()
+	for i := len(c.mensagens) - 1; i >= 0; i-- {
+		if c.mensagens[i].Role == "assistant" {
+			return c.mensagens[i], true
+		}
+	}
+	return Message{}, false
+}
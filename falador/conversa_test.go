@@ -0,0 +1,189 @@
+package falador
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Cria um servidor que responde como a API de chat, repassando as requisições recebidas
+// para a função informada, que retorna o status e o conteúdo da resposta.
+func servidorTeste(t *testing.T, responde func(req ChatGPTRequest) (int, interface{})) *Client {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer chave" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		req := ChatGPTRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		status, corpo := responde(req)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(corpo)
+	}))
+	t.Cleanup(s.Close)
+
+	cliente := NovoCliente(s.URL+"/v1/"+RECURSO_CHAT, "chave")
+	cliente.Modelo = "gpt-teste"
+	return cliente
+}
+
+// Conteúdo da resposta de sucesso da API.
+func respostaTeste(conteudo string) map[string]interface{} {
+	return map[string]interface{}{
+		"model": "gpt-teste",
+		"usage": map[string]int{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+		"choices": []map[string]interface{}{{
+			"finish_reason": "stop",
+			"message":       map[string]string{"role": "assistant", "content": conteudo},
+		}},
+	}
+}
+
+func TestConversaMantemHistorico(t *testing.T) {
+	recebidas := make([][]Message, 0)
+	cliente := servidorTeste(t, func(req ChatGPTRequest) (int, interface{}) {
+		recebidas = append(recebidas, req.Messages)
+		return http.StatusOK, respostaTeste("Resposta " + string(rune('A'+len(recebidas)-1)))
+	})
+
+	conversa := NovaConversa(cliente, "pt-BR")
+	for _, pergunta := range []string{"Primeira", "Segunda"} {
+		if _, err := conversa.Pergunta(context.Background(), pergunta); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(recebidas) != 2 || len(recebidas[1]) != 3 {
+		t.Fatalf("mensagens enviadas = %+v, esperado histórico com 3 mensagens no segundo envio", recebidas)
+	}
+	if got, esperado := recebidas[1][0].Content, `Primeira (You must answer in "pt-BR")`; got != esperado {
+		t.Errorf("pergunta enviada = %q, esperado %q", got, esperado)
+	}
+	if recebidas[1][1].Content != "Resposta A" {
+		t.Errorf("resposta no histórico enviado = %q, esperado %q", recebidas[1][1].Content, "Resposta A")
+	}
+
+	mensagens := conversa.Mensagens()
+	if len(mensagens) != 4 {
+		t.Fatalf("histórico com %d mensagens, esperado 4", len(mensagens))
+	}
+	ultima, ok := conversa.UltimaResposta()
+	if !ok || ultima.Content != "Resposta B" || ultima.Modelo != "gpt-teste" || ultima.Uso.TotalTokens != 15 {
+		t.Errorf("última resposta = %+v", ultima)
+	}
+
+	conversa.Reinicia()
+	if len(conversa.Mensagens()) != 0 {
+		t.Error("histórico não foi apagado")
+	}
+}
+
+func TestConversaErros(t *testing.T) {
+	testes := []struct {
+		nome      string
+		status    int
+		corpo     interface{}
+		atraso    time.Duration
+		verifica  func(err error) bool
+		descricao string
+	}{
+		{
+			nome:   "erro da API",
+			status: http.StatusBadRequest,
+			corpo:  map[string]interface{}{"error": map[string]string{"message": "modelo inexistente", "type": "invalid_request_error"}},
+			verifica: func(err error) bool {
+				var e *ErroAPI
+				return errors.As(err, &e) && e.Mensagem == "modelo inexistente" && e.Tipo == "invalid_request_error" && e.Status == http.StatusBadRequest
+			},
+			descricao: "*ErroAPI com a mensagem da API",
+		},
+		{
+			nome:   "resposta sem choices e sem erro",
+			status: http.StatusOK,
+			corpo:  map[string]interface{}{"id": "x"},
+			verifica: func(err error) bool {
+				var e *ErroAPI
+				return errors.As(err, &e) && strings.Contains(e.Mensagem, `"id"`)
+			},
+			descricao: "*ErroAPI com o conteúdo retornado",
+		},
+		{
+			nome:   "conteúdo inválido",
+			status: http.StatusBadGateway,
+			corpo:  "gateway",
+			verifica: func(err error) bool {
+				var e *ErroStatus
+				return errors.As(err, &e) && e.Status == http.StatusBadGateway && e.Conteudo == `"gateway"` && strings.Contains(err.Error(), "502")
+			},
+			descricao: "*ErroStatus com o status HTTP",
+		},
+		{
+			nome:      "timeout",
+			status:    http.StatusOK,
+			corpo:     respostaTeste("tarde demais"),
+			atraso:    300 * time.Millisecond,
+			verifica:  func(err error) bool { return errors.Is(err, ErrTempoEsgotado) },
+			descricao: "ErrTempoEsgotado",
+		},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			cliente := servidorTeste(t, func(ChatGPTRequest) (int, interface{}) {
+				time.Sleep(tt.atraso)
+				return tt.status, tt.corpo
+			})
+			cliente.Timeout = 100 * time.Millisecond
+
+			conversa := NovaConversa(cliente, "")
+			_, err := conversa.Pergunta(context.Background(), "Olá")
+			if !tt.verifica(err) {
+				t.Errorf("erro = %v, esperado %s", err, tt.descricao)
+			}
+
+			// A pergunta que falhou não fica no histórico.
+			if n := len(conversa.Mensagens()); n != 0 {
+				t.Errorf("histórico com %d mensagens, esperado 0", n)
+			}
+		})
+	}
+}
+
+func TestClienteEndereco(t *testing.T) {
+	cliente := NovoCliente("", "")
+	if got, esperado := cliente.Endereco("audio/speech"), "https://api.openai.com/v1/audio/speech"; got != esperado {
+		t.Errorf("endereço = %q, esperado %q", got, esperado)
+	}
+}
@@ -0,0 +1,119 @@
+// Package falador contém o cliente da API de chat da OpenAI e a conversa (histórico de mensagens)
+// usados pelo GPT-Falador. Pode ser importado por outros programas em Go para fazer perguntas à IA
+// com a mesma lógica do aplicativo, sem a interface de console e a narração.
+package falador
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// Estrutura da mensagem de requisição
+	Message struct {
+		Role    string `json:"role"`    // Pode ser "user", "assistant" ou "system" (instrução da tradução).
+		Content string `json:"content"` // Conteúdo da mensagem.
+
+		// Obs: Como se trata de um chat, o conteúdo do campo Role alterna-se entre "user" e "assistant"
+		//      ou seja, o usuário (user) envia e o assistente (assistant) responde.
+		//      Para que a IA mantenha o contexto da conversa, deve-se guardar a conversa
+		//      em um histórico e enviar à API, sempre que fizer nova pergunta.
+
+		// Metadados de cada turno da conversa. Não são enviados para a API, servem apenas
+		// para a exportação do histórico (comando "export" e parâmetro --export).
+		Horario time.Time `json:"-"` // Data e hora em que a mensagem foi enviada/recebida.
+		Modelo  string    `json:"-"` // Modelo que respondeu (somente nas respostas do "assistant").
+		Uso     *Usage    `json:"-"` // Tokens consumidos no turno (somente nas respostas do "assistant").
+	}
+
+	// Quantidade de tokens consumidos em uma requisição.
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	}
+
+	// Estrutura a ser enviada para a API do ChatGPT contendo as mensagens trocadas entre o usuário e a API
+	ChatGPTRequest struct {
+		Model       string    `json:"model"`       // Atualmente (2023) o model usado é o "gpt-3.5-turbo"
+		Messages    []Message `json:"messages"`    // Histórico das mensagens trocadas + nova mensagem.
+		Temperature float32   `json:"temperature"` // Valor na faixa de 0.0 a 2.0.
+		// Quanto maior o valor de Temperature, mais aleatória é a resposta.
+		// Quanto menor, mais determinística.
+	}
+
+	// Estrutura retornada pela API do ChatGPT (se responder com sucesso).
+	// Mais detalhes no link https://platform.openai.com/docs/api-reference/chat/create
+	ChatGPTResult struct {
+		ID      string `json:"id"`
+		Object  string `json:"object"`
+		Created int64  `json:"created"`
+		Model   string `json:"model"`
+		Usage   Usage  `json:"usage"`
+		Choices []struct {
+			Message struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+			Index        int    `json:"index"`
+		}
+	}
+
+	// Erro retornado pela API no campo "error" (ex: chave inválida, modelo inexistente).
+	ErroAPI struct {
+		Status   int    `json:"-"` // Status HTTP da resposta.
+		Mensagem string `json:"message"`
+		Tipo     string `json:"type"`
+		Param    string `json:"param"`
+		Codigo   string `json:"code"`
+	}
+
+	// Erro retornado quando a resposta da API não é um json (ex: página de erro de um proxy).
+	ErroStatus struct {
+		Status   int    // Status HTTP da resposta.
+		Conteudo string // Conteúdo retornado.
+	}
+)
+
+func (e *ErroAPI) Error() string {
+	if e.Mensagem == "" {
+		return fmt.Sprintf("a API respondeu com o status %d", e.Status)
+	}
+	return e.Mensagem
+}
+
+func (e *ErroStatus) Error() string {
+	return fmt.Sprintf("a API respondeu com o status %d: %s", e.Status, e.Conteudo)
+}
+
+// Acrescenta à pergunta a instrução para a IA responder no idioma informado.
+// Se o idioma estiver vazio, a pergunta é enviada sem a instrução.
+func InstrucaoIdioma(pergunta, idioma string) string {
+	if idioma == "" {
+		return pergunta
+	}
+	return fmt.Sprintf("%s (You must answer in \"%s\")", pergunta, idioma)
+}
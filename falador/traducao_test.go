@@ -0,0 +1,75 @@
+package falador
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Cada tradução envia somente a instrução de sistema e o texto, sem o histórico das anteriores.
+func TestClienteTraduz(t *testing.T) {
+	recebidas := make([][]Message, 0)
+	cliente := servidorTeste(t, func(req ChatGPTRequest) (int, interface{}) {
+		recebidas = append(recebidas, req.Messages)
+		return http.StatusOK, respostaTeste("Good morning")
+	})
+
+	testes := []struct {
+		origem, destino string
+		instrucao       string
+	}{
+		{"pt-BR", "en-US", `from "pt-BR" to "en-US"`},
+		{"", "en-US", `from the language it is written in to "en-US"`},
+	}
+	for i, tt := range testes {
+		resposta, err := cliente.Traduz(context.Background(), "Bom dia", tt.origem, tt.destino)
+		if err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+		if resposta.Mensagem.Content != "Good morning" {
+			t.Errorf("tradução = %q", resposta.Mensagem.Content)
+		}
+
+		mensagens := recebidas[i]
+		if len(mensagens) != 2 || mensagens[0].Role != "system" || mensagens[1].Role != "user" {
+			t.Fatalf("mensagens enviadas = %+v, esperado sistema e usuário", mensagens)
+		}
+		if !strings.Contains(mensagens[0].Content, tt.instrucao) {
+			t.Errorf("instrução = %q, esperado conter %q", mensagens[0].Content, tt.instrucao)
+		}
+		// O texto é enviado sem a instrução de idioma das perguntas.
+		if mensagens[1].Content != "Bom dia" {
+			t.Errorf("texto enviado = %q, esperado %q", mensagens[1].Content, "Bom dia")
+		}
+	}
+
+	if _, err := cliente.Traduz(context.Background(), "Bom dia", "pt-BR", ""); err == nil {
+		t.Error("esperado erro sem o idioma de destino")
+	}
+	if len(recebidas) != len(testes) {
+		t.Errorf("%d requisições, esperado %d", len(recebidas), len(testes))
+	}
+}
@@ -23,16 +23,13 @@ hnovaes@yahoo.com
 ==============================================================================*/
 
 import (
-	"bufio"
-	"context"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"math/rand"
-	"net/http"
-	"net/url"
 	"os"
 	"os/exec"
 	"strconv"
@@ -40,52 +37,17 @@ import (
 	"sync"
 	"time"
 
-	"golang.org/x/sys/windows"
+	"gpt-falador/falador"
 )
 
+// Tipos da conversa com a API, definidos no pacote falador.
 type (
-	// Estrutura da mensagem de requisição
-	Message struct {
-		Role    string `json:"role"`    // Pode ser "user" ou "assistant".
-		Content string `json:"content"` // Conteúdo da mensagem.
-
-		// Obs: Como se trata de um chat, o conteúdo do campo Role alterna-se entre "user" e "assistant"
-		//      ou seja, o usuário (user) envia e o assistente (assistant) responde.
-		//      Para que a IA mantenha o contexto da conversa, deve-se guardar a conversa
-		//      em um histórico e enviar à API, sempre que fizer nova pergunta.
-	}
-
-	// Estrutura a ser enviada para a API do ChatGPT contendo as mensagens trocadas entre o usuário e a API
-	ChatGPTRequest struct {
-		Model       string    `json:"model"`       // Atualmente (2023) o model usado é o "gpt-3.5-turbo"
-		Messages    []Message `json:"messages"`    // Histórico das mensagens trocadas + nova mensagem.
-		Temperature float32   `json:"temperature"` // Valor na faixa de 0.0 a 2.0.
-		// Quanto maior o valor de Temperature, mais aleatória é a resposta.
-		// Quanto menor, mais determinística.
-	}
-
-	// Estrutura retornada pela API do ChatGPT (se responder com sucesso).
-	// Mais detalhes no link https://platform.openai.com/docs/api-reference/chat/create
-	ChatGPTResult struct {
-		ID      string `json:"id"`
-		Object  string `json:"object"`
-		Created int64  `json:"created"`
-		Model   string `json:"model"`
-		Usage   struct {
-			PromptTokens     int `json:"prompt_tokens"`
-			CompletionTokens int `json:"completion_tokens"`
-			TotalTokens      int `json:"total_tokens"`
-		} `json:"usage"`
-		Choices []struct {
-			Message struct {
-				Role    string `json:"role"`
-				Content string `json:"content"`
-			} `json:"message"`
-			FinishReason string `json:"finish_reason"`
-			Index        int    `json:"index"`
-		}
-	}
+	Message        = falador.Message
+	Usage          = falador.Usage
+	ChatGPTRequest = falador.ChatGPTRequest
+)
 
+type (
 	// Estrutura das configurações lidas do arquivo settings.json
 	Settings struct {
 		URL_API     string  // URL --> "https://api.openai.com/v1/chat/completions"
@@ -94,16 +56,49 @@ type (
 		TIMEOUT     int     // Tempo máximo a aguardar por resposta.
 		TEMPERATURE float32 // Campo temperature
 		TTS         bool    // Se true, fala o texto retornado pela API. Se false, não fala.
-		IDIOMA      string  // Idioma do Falador (narrador do texto)
-
-		// Delay máximo para imprimir as palavras na tela. Dependendo do idioma,
-		// a pronúncia pode ser mais rápida ou mais lenta. Quando narra números, demora
-		// mais para narrá-los do que para imprimir na tela.
-		// A narração do idioma em português é mais lenta que em inglês, por isso,
-		// deve-se ajustar o valor desse parâmetro para tentar sincronizar a impressão
-		// da resposta com a narração dela. Exemplo: para en-us, valor entre 120 e 150 está ok.
-		// Para pt-br, o valor entre 165 e 200 está ok.
+		IDIOMA      string  // Idioma do Falador (narrador do texto). Se "auto", é detectado em cada pergunta e trecho narrado.
+
+		// Motor de TTS usado na narração: "google" (padrão) ou "openai" (endpoint /v1/audio/speech).
+		TTS_ENGINE string
+
+		// Endereço do TTS do Google. Se vazio, usa "https://translate.google.com/translate_tts".
+		TTS_URL string
+
+		// Voz da narração. Se vazio, usa a voz mapeada para o idioma no parâmetro VOZES
+		// ou, se não houver, a voz padrão do motor. O comando "/voices" lista as vozes disponíveis.
+		VOICE string
+
+		// Voz de cada idioma, por motor de TTS. Ex: {"openai": {"pt-br": "nova", "en": "onyx"}}.
+		VOZES map[string]map[string]string
+
+		// Se true, grava cada conversa na pasta ./conversas, o que permite pesquisá-las
+		// depois com o comando "/search".
+		SALVA_CONVERSAS bool
+
+		// Tamanho máximo, em MB, da pasta ./audio/cache, onde são guardados os audios já baixados.
+		// Ao ultrapassar esse tamanho, os audios usados há mais tempo são descartados.
+		// Se for 0, o cache é desativado e os audios são apagados depois de narrados.
+		CACHE_TTS_MB int
+
+		// Delay máximo para imprimir as palavras na tela.
+		// Usado apenas quando o TTS está inativo ou a narração falha (ex: sem conexão):
+		// com a narração ativa, a impressão acompanha o progresso do audio.
 		MAX_DELAY int
+
+		// Motor de STT (Speech-To-Text) usado no comando "/listen": "openai" (padrão, API Whisper)
+		// ou "whispercpp" (programa local, informado em WHISPER_CPP, com o modelo WHISPER_CPP_MODEL).
+		STT_ENGINE        string
+		WHISPER_CPP       string
+		WHISPER_CPP_MODEL string
+
+		// Volume (de 0 a 100) e velocidade (de 0.5 a 2) da narração. Também podem ser
+		// alterados durante a narração pelas setas para cima/baixo e Page Up/Page Down.
+		VOLUME     int
+		VELOCIDADE float64
+
+		// Idioma das mensagens do aplicativo: "pt-BR" ou "en-US". Se vazio, usa o idioma das
+		// variáveis de ambiente LC_ALL, LC_MESSAGES ou LANG. Não altera o idioma das respostas (IDIOMA).
+		UI_LANG string
 	}
 )
 
@@ -113,284 +108,412 @@ const (
 	TECLA_ESC    = 0x1B
 	TECLA_ESPACO = 0x20
 
+	// Quantidade máxima de blocos de audio baixados ao mesmo tempo.
+	MAX_DOWNLOADS = 4
+
 	// Nome do arquivo de configurações:
 	SETTINGS = "settings.json"
 )
 
-var (
-	mutex            = &sync.Mutex{}      // Mutex para controlar a impressão da frase "ChatGPT está pensando..."
-	terminouDePensar = false              // O acesso a essa variável é protegido por esta mutex
-	pressionouESC    = false              // Usado para interromper o audio caso esteja narrando o texto retornado.
-	noSleep          = false              // Se true, imprime o texto sem pausas.
-	printJson        = false              // Se true, imprime o payload retornado pela API.
-	interativo       = false              // Se true, executa o GPT no modo interativo (para manter histórico das conversas)
-	messages         = make([]Message, 0) // Histórico das mensagens trocadas entre o usuário e a AI
-	settings         = &Settings{}        // Armazena as configurações carregadas do arquivo settings.json
-
-	// Para carregar e usar função GetKeyState da API user32.dll do Windows,
-	// que verifica o estado de uma tecla qualquer.
-	user32_dll  = windows.NewLazyDLL("user32.dll")
-	GetKeyState = user32_dll.NewProc("GetKeyState")
-)
+// Retorna uma cópia das configurações, para uso fora da goroutine principal.
+func (sessao *Sessao) configuracoes() Settings {
+	defer sessao.mutexSettings.RUnlock()
+	sessao.mutexSettings.RLock()
+	return *sessao.settings
+}
 
 // Carrega as configurações do arquivo settings.json
-func carregaConfiguracoes() {
+func (sessao *Sessao) carregaConfiguracoes() {
 	s, e := os.ReadFile(SETTINGS)
 	if e != nil {
 		panic(e)
 	}
 
-	e = json.Unmarshal(s, settings)
+	// As configurações são lidas para uma nova estrutura, pois a atual pode estar em uso por outra
+	// goroutine (ex: os mapas de VOZES, que seriam alterados pelo json.Unmarshal).
+	novas := Settings{VOLUME: VOLUME_MAXIMO, VELOCIDADE: 1}
+	e = json.Unmarshal(s, &novas)
 	if e != nil {
 		panic(e)
 	}
 
-	messages = messages[:0]
-	printSettings()
-	fmt.Println("Digite \033[96mhelp\033[m para mais informações")
-}
+	// Mantém o volume e a velocidade dentro dos limites (ex: arquivo antigo, sem esses campos).
+	novas.VOLUME = sessao.controle.DefineVolume(novas.VOLUME)
+	if novas.VELOCIDADE == 0 {
+		novas.VELOCIDADE = 1
+	}
+	novas.VELOCIDADE = sessao.controle.DefineVelocidade(novas.VELOCIDADE)
 
-// Grava as configurações no arquivo settings.json
-func gravaSettings() {
-	bytes, _ := json.MarshalIndent(settings, "", "    ")
-	if err := os.WriteFile(SETTINGS, bytes, 0700); err != nil {
-		fmt.Println("\033[31m", err.Error(), "\033[m")
+	// Reaplica as alterações feitas pelos parâmetros da linha de comando (ex: --model).
+	for _, f := range sessao.sobreposicoes {
+		f(&novas)
 	}
-}
+	sessao.carregadas = novas
 
-func printSettings() {
-	fmt.Println("GPT Model:\033[96m", settings.GPT_MODEL, "\033[m")
-	fmt.Println("Timeout:\033[96m", settings.TIMEOUT, "\033[m")
-	fmt.Println("TTS:\033[96m", settings.TTS, "\033[m")
-	fmt.Println("Idioma:\033[96m", settings.IDIOMA, "\033[m")
-	fmt.Println("Max Delay:\033[96m", settings.MAX_DELAY, "\033[m")
-	fmt.Println("Temperature:\033[96m", settings.TEMPERATURE, "\033[m")
-}
+	sessao.mutexSettings.Lock()
+	*sessao.settings = novas
+	sessao.mutexSettings.Unlock()
+	defineIdiomaInterface(novas.UI_LANG)
 
-// Para poder usar o Escape Code para colorir palavras na console, é necessário habilitar primeiro.
-func setConsoleColors() error {
-	console := windows.Stdout
-	var consoleMode uint32
-	windows.GetConsoleMode(console, &consoleMode)
-	consoleMode |= windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING
-	return windows.SetConsoleMode(console, consoleMode)
+	sessao.conversa.Reinicia()
+	sessao.arquivoConversa = ""
+	if sessao.saidaJson {
+		return
+	}
+	sessao.printSettings()
+	sessao.tela.Println(traduz("Digite \033[96m/help\033[m para mais informações"))
 }
 
-// Função para alternar o valor da variável terminouDePensar, para interromper
-// a goroutine que imprime a frase "ChatGPT está pensando..."
-func setTerminouDePensar(b bool) {
-	defer mutex.Unlock()
-	mutex.Lock()
-	terminouDePensar = b
+// Atualiza os campos VOLUME e VELOCIDADE com os valores dos controles da narração,
+// que podem ter sido alterados pelas teclas durante a narração.
+func (sessao *Sessao) sincronizaControles() {
+	defer sessao.mutexSettings.Unlock()
+	sessao.mutexSettings.Lock()
+	sessao.settings.VOLUME = sessao.controle.VolumeAtual()
+	sessao.settings.VELOCIDADE = sessao.controle.Velocidade()
 }
 
-// Retorna o valor da variável terminouDePensar. O acesso a essa variável tem que ser
-// controloado por mutex, pois, a mesma é usada dentro de uma goroutine.
-func getTerminouDePensar() bool {
-	defer mutex.Unlock()
-	mutex.Lock()
-	return terminouDePensar
-}
+// Grava no arquivo settings.json as alterações feitas pelos comandos (ex: "/set" e "gpt config").
+// As alterações feitas pelos parâmetros da linha de comando (ex: --model) valem somente nesta execução:
+// o arquivo é lido novamente e recebe apenas os campos que diferem das configurações carregadas.
+func (sessao *Sessao) gravaSettings() {
+	sessao.sincronizaControles()
+	alterados, err := camposAlterados(sessao.carregadas, *sessao.settings)
+	if err != nil {
+		sessao.tela.Erro(err)
+		return
+	}
 
-// Imprime o help na tela
-func printHelp() {
-	fmt.Println("Faça a pergunta para o ChatGPT.")
-	fmt.Println("Exemplo: O que pesa mais: um quilo de pena ou um quilo de chumbo?")
-	fmt.Println("\r\nTambém pode-se usar os seguintes parâmetros via linha de comando:")
-	fmt.Println("\t\033[36m--help\033[m        Exibe estas informações de ajuda.")
-	fmt.Println("\t\033[36m--nosleep\033[m     Imprime a resposta de uma só vez, sem delay.")
-	fmt.Println("\t              Tecle \033[36mESC\033[m para interromper a impressão da resposta.")
-	fmt.Println("\t              Tecle \033[36mESPAÇO\033[m para imprimir a resposta completa sem delay.")
-	fmt.Println("\t\033[36m--printjson\033[m   Imprime o conteúdo json retornado pelo servidor (payload)")
-	fmt.Println("\t\033[36m--interativo\033[m  Executa este aplicativo no modo interativo, para manter")
-	fmt.Println("\t              o histórico da conversa, o que facilita para a IA")
-	fmt.Println("\t              contextualizar as próximas perguntas.")
-	fmt.Println("\t              Digite \033[36mhelp\033[m para exibir estas informações")
-	fmt.Println("\t              Digite \033[36mquit\033[m para terminar o modo interativo")
-	fmt.Println("\t              Digite \033[36mreset\033[m para iniciar nova conversa e recarregar")
-	fmt.Println("\t              as configurações no arquivo settings.json")
-	fmt.Println("\t              Digite \033[36mcls\033[m para limpar a tela (mantém o histórico da conversa)")
-	fmt.Println("\t              Digite \033[36mset param=valor\033[m para alterar o valor de algum parâmetro")
-	fmt.Println("\t              Exemplo: \033[36mset tts=false\033[m para desativar a fala")
-	fmt.Println("\t                       \033[36mset lang=en-us\033[m para alterar o idioma para Inglês dos EUA")
-}
+	// Se o arquivo não existir mais, parte das configurações carregadas.
+	atual, err := os.ReadFile(SETTINGS)
+	if os.IsNotExist(err) {
+		atual, err = json.Marshal(sessao.carregadas)
+	}
+	if err != nil {
+		sessao.tela.Erro(err)
+		return
+	}
 
-// Obtem parâmetros passados via linha de comando ou entra no modo interativo para obter
-// as perguntas digitadas pelo usuário na console.
-func getPrompt() string {
+	novas := Settings{VOLUME: VOLUME_MAXIMO, VELOCIDADE: 1}
+	if err = json.Unmarshal(atual, &novas); err == nil {
+		err = json.Unmarshal(alterados, &novas)
+	}
+	if err != nil {
+		sessao.tela.Erro(err)
+		return
+	}
 
-	if len(os.Args) < 2 {
-		interativo = true
+	bytes, _ := json.MarshalIndent(novas, "", "    ")
+	if err := os.WriteFile(SETTINGS, bytes, 0700); err != nil {
+		sessao.tela.Erro(err)
+		return
 	}
 
-	result := ""
-	for i := 1; i < len(os.Args); i++ {
+	// As configurações gravadas passam a ser as carregadas (com as alterações dos parâmetros).
+	for _, f := range sessao.sobreposicoes {
+		f(&novas)
+	}
+	sessao.carregadas = novas
+}
 
-		if os.Args[i] == "--help" {
-			printHelp()
-			continue
-		}
-		// Verifica se passou o parâmetro --nospeep.
-		if os.Args[i] == "--nosleep" {
-			noSleep = true
-			continue
+// Retorna, em json, os campos das configurações que diferem entre as duas versões.
+func camposAlterados(antes, depois Settings) ([]byte, error) {
+	campos := func(s Settings) (map[string]json.RawMessage, error) {
+		m := make(map[string]json.RawMessage)
+		b, err := json.Marshal(s)
+		if err == nil {
+			err = json.Unmarshal(b, &m)
 		}
+		return m, err
+	}
 
-		// Verifica se passou o parâmetro --printjson.
-		if os.Args[i] == "--printjson" {
-			printJson = true
-			continue
+	camposAntes, err := campos(antes)
+	if err != nil {
+		return nil, err
+	}
+	camposDepois, err := campos(depois)
+	if err != nil {
+		return nil, err
+	}
+	for nome, valor := range camposDepois {
+		if bytes.Equal(valor, camposAntes[nome]) {
+			delete(camposDepois, nome)
 		}
+	}
+	return json.Marshal(camposDepois)
+}
 
-		// Verifica se passou o parâmetro --interativo.
-		if os.Args[i] == "--interativo" {
-			interativo = true
-			continue
-		}
+func (sessao *Sessao) printSettings() {
+	sessao.sincronizaControles()
+	sessao.tela.Println("GPT Model:\033[96m", sessao.settings.GPT_MODEL, "\033[m")
+	sessao.tela.Println("Timeout:\033[96m", sessao.settings.TIMEOUT, "\033[m")
+	sessao.tela.Println("TTS:\033[96m", sessao.settings.TTS, "\033[m")
+	sessao.tela.Println(traduz("Idioma:")+"\033[96m", sessao.settings.IDIOMA, "\033[m")
+	sessao.tela.Println("TTS Engine:\033[96m", sessao.settings.TTS_ENGINE, "\033[m")
+	sessao.tela.Println("Voice:\033[96m", sessao.settings.VOICE, "\033[m")
+	sessao.tela.Println("STT Engine:\033[96m", sessao.settings.STT_ENGINE, "\033[m")
+	sessao.tela.Println("Max Delay:\033[96m", sessao.settings.MAX_DELAY, "\033[m")
+	sessao.tela.Println("Temperature:\033[96m", sessao.settings.TEMPERATURE, "\033[m")
+	sessao.tela.Println(traduz("Salva Conversas:")+"\033[96m", sessao.settings.SALVA_CONVERSAS, "\033[m")
+	sessao.tela.Println(traduz("Cache TTS (MB):")+"\033[96m", sessao.settings.CACHE_TTS_MB, "\033[m")
+	sessao.tela.Println("Volume:\033[96m", sessao.settings.VOLUME, "\033[m")
+	sessao.tela.Println(traduz("Velocidade:")+"\033[96m", sessao.settings.VELOCIDADE, "\033[m")
+	sessao.tela.Println(traduz("Idioma da interface:")+"\033[96m", idiomaInterface(), "\033[m")
+}
+
+// Imprime o help na tela
+func (sessao *Sessao) printHelp() {
+	sessao.tela.Println(traduz("Faça a pergunta para o ChatGPT."))
+	sessao.tela.Println(traduz("Exemplo: O que pesa mais: um quilo de pena ou um quilo de chumbo?"))
+	sessao.tela.Println()
+	sessao.printHelpLinhaDeComando()
+	sessao.tela.Println(traduz("\r\nComandos do modo interativo:"))
+	sessao.printHelpComandos()
+}
 
-		//Caso não seja nenhum dos parâmetros acima, concatena o argumento à variável result.
-		result += os.Args[i] + " "
+// Obtem a pergunta da linha de comando (na primeira chamada) ou entra no modo interativo
+// para obter as perguntas digitadas pelo usuário na console.
+func (sessao *Sessao) getPrompt() string {
+	if len(sessao.argumentos) == 0 {
+		sessao.interativo = true
+		return sessao.getPromptFromConsole()
 	}
 
 	// Limpa os argumentos para evitar tratamento dos mesmos novamente.
-	os.Args = os.Args[:0]
-	if interativo && len(result) > 0 {
-		return result
-	}
+	args := sessao.argumentos[1:]
+	sessao.argumentos = sessao.argumentos[:0]
 
-	if interativo {
-		return getPromptFromConsole()
+	pergunta, err := sessao.interpretaLinhaDeComando(args)
+	if err != nil {
+		sessao.tela.Erro(err)
+		sessao.tela.Println(traduz("Use \033[96mgpt --help\033[m para ver os subcomandos e parâmetros."))
+		sessao.encerrada = true
+		sessao.codigoSaida = SAIDA_USO_INVALIDO
+		return ""
 	}
 
-	return strings.Trim(result, " ")
+	// O modo --json responde uma única pergunta, informada na linha de comando.
+	if pergunta != "" || !sessao.interativo || sessao.encerrada || sessao.saidaJson {
+		return pergunta
+	}
+	return sessao.getPromptFromConsole()
 }
 
 // Modo interativo: aguarda o usuário digitar a frase e teclar enter.
-// Também, verifica se o usuário digitou "quit" ou "reset".
-// Se digitar "quit", encerra o programa.
-// Se digitar "reset", apaga o histórico da conversa - isso faz com que a IA perca o contexto da conversa.
-func getPromptFromConsole() string {
+// Também, verifica se o usuário digitou algum comando (iniciado por "/"), como "/quit" ou "/reset".
+// Se digitar "/quit", encerra o programa.
+// Se digitar "/reset", apaga o histórico da conversa - isso faz com que a IA perca o contexto da conversa.
+func (sessao *Sessao) getPromptFromConsole() string {
+
+	for !sessao.encerrada {
+		// No modo de voz (comando "/listen on"), a pergunta é ditada pelo microfone.
+		if sessao.modoVoz {
+			if pergunta := sessao.ouvePergunta(); pergunta != "" {
+				return pergunta
+			}
+			continue
+		}
 
-	for {
-		fmt.Print("\r\n\033[32mPergunta\033[m: ")
-		reader := bufio.NewReader(os.Stdin)
-		pergunta, err := reader.ReadString('\n')
-		if err != nil {
+		if sessao.modoTraducao {
+			sessao.tela.Printf(traduz("\r\n\033[32mTraduzir\033[m (%s → %s): "), descreveIdiomaTraducao(sessao.idiomaOrigem), descreveIdiomaTraducao(sessao.idiomaDestino))
+		} else {
+			sessao.tela.Print(traduz("\r\n\033[32mPergunta\033[m: "))
+		}
+		pergunta, err := sessao.entrada.ReadString('\n')
+		if err == io.EOF && pergunta == "" {
+			// Fim da entrada (ex: perguntas redirecionadas de um arquivo): encerra o modo interativo.
+			sessao.encerrada = true
+			sessao.tela.Println()
+			break
+		}
+		if err != nil && err != io.EOF {
 			log.Fatal(err)
 		}
 
 		pergunta = strings.Trim(pergunta, "\r\n\t ")
-		comando := strings.ToLower(pergunta)
 
-		switch comando {
-		case "cls":
-			clearScreen()
-			continue
-		case "help":
-			printHelp()
-			return ""
-		case "quit":
-			os.Exit(0)
-		case "reset":
-			clearScreen()
-			carregaConfiguracoes()
-			fmt.Println("Reset efetuado. O histórico e contexto da conversa foi perdido.")
-			fmt.Println("Pronto para iniciar outra conversa.")
-			continue
-		case "set":
-			printSettings()
-			continue
-		}
-
-		// Se o texto digitado contém a palavra "set " (seguido de espaço), aciona o tratamento...
-		if strings.HasPrefix(comando, "set ") {
-			if trataComandoSet(comando) {
-				gravaSettings()
+		// Se o texto digitado começa com "/", executa o comando correspondente.
+		// Alguns comandos (como o /t) geram a pergunta a ser enviada à IA.
+		if ehComando(pergunta) {
+			if pergunta = sessao.executaComando(pergunta); pergunta == "" {
 				continue
 			}
+			return pergunta
 		}
 
+		// O texto iniciado por "//" é enviado à IA sem a primeira "/".
+		pergunta = strings.TrimPrefix(pergunta, PREFIXO_COMANDO)
+
 		if len(pergunta) > 0 {
 			return pergunta
 		}
 	}
+	return ""
 }
 
-// Esta função trata os parâmetros do comando "set".
+// Esta função trata os parâmetros do comando "/set", no formato "param=valor".
 // Se o parâmetro existir e o valor do mesmo for válido, retorna true.
 // Caso contrário, retorna false.
-func trataComandoSet(comando string) bool {
+func (sessao *Sessao) trataComandoSet(comando string) bool {
+	defer sessao.mutexSettings.Unlock()
+	sessao.mutexSettings.Lock()
 
-	// Obtém o parâmetro e o valor após a 4a posição na string "comando", após o "set ".
-	tokens := strings.Split(comando[3:], "=")
+	// Obtém o parâmetro e o valor separados pelo "=".
+	comando = strings.ToLower(comando)
+	tokens := strings.Split(comando, "=")
 
 	// Se não existir um "=" no comando, a quantidade de tokens será menor que 2.
 	if len(tokens) < 2 {
-		fmt.Printf("\r\n\033[31mComando \"%s\" inválido\033[m\r\n", comando)
-		return true
+		sessao.tela.Printf(traduz("\r\n\033[31mComando \"%s\" inválido\033[m\r\n"), comando)
+		return false
 	}
 
 	// Obtém o nome do parâmetro (posição 0) e o valor do parâmetro (posição 1)
 	param := strings.Trim(tokens[0], " ")
 	valor := strings.Trim(tokens[1], " ")
 
-	// Tratamento para o comando "set model=<modelo>"
-	if param == "model" && settings.GPT_MODEL != valor {
-		settings.GPT_MODEL = valor
-		fmt.Printf("GPT Model alterada para \"%s\"", valor)
+	// Tratamento para o comando "/set model=<modelo>"
+	if param == "model" && sessao.settings.GPT_MODEL != valor {
+		sessao.settings.GPT_MODEL = valor
+		sessao.tela.Printf(traduz("GPT Model alterada para \"%s\""), valor)
+		return true
+	}
+
+	// Tratamento para o comando "/set lang=<idioma>"
+	if param == "lang" && sessao.settings.IDIOMA != valor {
+		sessao.settings.IDIOMA = valor
+		sessao.tela.Printf(traduz("Idioma alterado para \"%s\""), valor)
 		return true
 	}
 
-	// Tratamento para o comando "set lang=<idioma>"
-	if param == "lang" && settings.IDIOMA != valor {
-		settings.IDIOMA = valor
-		fmt.Printf("Idioma alterado para \"%s\"", valor)
+	// Tratamento para o comando "/set ui_lang=<idioma>". Se vazio, usa o idioma do ambiente (LANG).
+	if param == "ui_lang" && sessao.settings.UI_LANG != valor {
+		sessao.settings.UI_LANG = valor
+		idioma := defineIdiomaInterface(valor)
+		sessao.tela.Printf(traduz("Idioma da interface alterado para \"%s\""), idioma)
+		return true
+	}
+
+	// Tratamento para o comando "/set tts_engine=<motor>"
+	if param == "tts_engine" && sessao.settings.TTS_ENGINE != valor {
+		if _, existe := motoresTTS[valor]; !existe {
+			sessao.tela.Printf(traduz("\r\n\033[31mMotor de TTS \"%s\" inexistente. Use: %s\033[m\r\n"), valor, strings.Join(nomesMotores(), ", "))
+			return false
+		}
+		sessao.settings.TTS_ENGINE = valor
+		sessao.tela.Printf(traduz("Motor de TTS alterado para \"%s\""), valor)
+		return true
+	}
+
+	// Tratamento para o comando "/set voice=<voz>". Se vazio, volta a usar a voz do idioma.
+	if param == "voice" && sessao.settings.VOICE != valor {
+		sessao.settings.VOICE = valor
+		sessao.tela.Printf(traduz("Voz alterada para \"%s\""), valor)
+		return true
+	}
+
+	// Tratamento para o comando "/set stt_engine=<motor>"
+	if param == "stt_engine" && sessao.settings.STT_ENGINE != valor {
+		if _, existe := motoresSTT[valor]; !existe {
+			sessao.tela.Printf(traduz("\r\n\033[31mMotor de STT \"%s\" inexistente\033[m\r\n"), valor)
+			return false
+		}
+		sessao.settings.STT_ENGINE = valor
+		sessao.tela.Printf(traduz("Motor de STT alterado para \"%s\""), valor)
 		return true
 	}
 
-	// Tratamento para o comando "set max_delay=<valor>"
+	// Tratamento para o comando "/set max_delay=<valor>"
 	if param == "max_delay" {
 		if m, err := strconv.Atoi(valor); err != nil {
-			fmt.Printf("\r\n\033[31mValor \"%s\" inválido\033[m\r\n", valor)
-		} else if settings.MAX_DELAY != m {
-			settings.MAX_DELAY = m
-			fmt.Printf("Delay máximo alterado para \"%s\"", valor)
+			sessao.tela.Printf(traduz("\r\n\033[31mValor \"%s\" inválido\033[m\r\n"), valor)
+		} else if sessao.settings.MAX_DELAY != m {
+			sessao.settings.MAX_DELAY = m
+			sessao.tela.Printf(traduz("Delay máximo alterado para \"%s\""), valor)
 			return true
 		}
 		return false
 	}
 
-	// Tratamento para o comando "set timeout=<valor>"
+	// Tratamento para o comando "/set timeout=<valor>"
 	if param == "timeout" {
 		if m, err := strconv.Atoi(valor); err != nil {
-			fmt.Printf("\r\n\033[31mValor \"%s\" inválido\033[m\r\n", valor)
-		} else if settings.TIMEOUT != m {
-			settings.TIMEOUT = m
-			fmt.Printf("Timeout alterado para \"%s\"", valor)
+			sessao.tela.Printf(traduz("\r\n\033[31mValor \"%s\" inválido\033[m\r\n"), valor)
+		} else if sessao.settings.TIMEOUT != m {
+			sessao.settings.TIMEOUT = m
+			sessao.tela.Printf(traduz("Timeout alterado para \"%s\""), valor)
 			return true
 		}
 		return false
 	}
 
-	// Tratamento para o comando "set temperature=<valor>"
+	// Tratamento para o comando "/set temperature=<valor>"
 	if param == "temperature" {
 		if m, err := strconv.ParseFloat(valor, 32); err != nil {
-			fmt.Printf("\r\n\033[31mValor \"%s\" inválido\033[m\r\n", valor)
-		} else if settings.TEMPERATURE != float32(m) {
-			settings.TEMPERATURE = float32(m)
-			fmt.Printf("Temperature alterado para \"%.2f\"", m)
+			sessao.tela.Printf(traduz("\r\n\033[31mValor \"%s\" inválido\033[m\r\n"), valor)
+		} else if sessao.settings.TEMPERATURE != float32(m) {
+			sessao.settings.TEMPERATURE = float32(m)
+			sessao.tela.Printf(traduz("Temperature alterado para \"%.2f\""), m)
 			return true
 		}
 		return false
 	}
 
-	// Tratamento para o comando "set tts=<valor>"
+	// Tratamento para o comando "/set salva_conversas=<valor>"
+	if param == "salva_conversas" {
+		if b, err := strconv.ParseBool(valor); err != nil {
+			sessao.tela.Printf(traduz("\r\n\033[31mValor \"%s\" inválido\033[m\r\n"), valor)
+		} else if sessao.settings.SALVA_CONVERSAS != b {
+			sessao.settings.SALVA_CONVERSAS = b
+			sessao.tela.Printf(traduz("Salva conversas alterado para \"%s\""), valor)
+			return true
+		}
+		return false
+	}
+
+	// Tratamento para o comando "/set cache_tts_mb=<valor>"
+	if param == "cache_tts_mb" {
+		if m, err := strconv.Atoi(valor); err != nil || m < 0 {
+			sessao.tela.Printf(traduz("\r\n\033[31mValor \"%s\" inválido\033[m\r\n"), valor)
+		} else if sessao.settings.CACHE_TTS_MB != m {
+			sessao.settings.CACHE_TTS_MB = m
+			sessao.tela.Printf(traduz("Tamanho do cache de audios alterado para \"%s\" MB"), valor)
+			return true
+		}
+		return false
+	}
+
+	// Tratamento para o comando "/set volume=<valor>"
+	if param == "volume" {
+		if m, err := strconv.Atoi(valor); err != nil || m < 0 || m > VOLUME_MAXIMO {
+			sessao.tela.Printf(traduz("\r\n\033[31mValor \"%s\" inválido\033[m\r\n"), valor)
+		} else if sessao.controle.VolumeAtual() != m {
+			sessao.settings.VOLUME = sessao.controle.DefineVolume(m)
+			sessao.tela.Printf(traduz("Volume alterado para \"%s\""), valor)
+			return true
+		}
+		return false
+	}
+
+	// Tratamento para o comando "/set speed=<valor>"
+	if param == "speed" {
+		if m, err := strconv.ParseFloat(valor, 64); err != nil || m < VELOCIDADE_MINIMA || m > VELOCIDADE_MAXIMA {
+			sessao.tela.Printf(traduz("\r\n\033[31mValor \"%s\" inválido\033[m\r\n"), valor)
+		} else if sessao.controle.Velocidade() != m {
+			sessao.settings.VELOCIDADE = sessao.controle.DefineVelocidade(m)
+			sessao.tela.Printf(traduz("Velocidade alterada para \"%.2f\""), m)
+			return true
+		}
+		return false
+	}
+
+	// Tratamento para o comando "/set tts=<valor>"
 	if param == "tts" {
 		if b, err := strconv.ParseBool(valor); err != nil {
-			fmt.Printf("\r\n\033[31mValor \"%s\" inválido\033[m\r\n", valor)
-		} else if settings.TTS != b {
-			settings.TTS = b
-			fmt.Printf("TTS (Text-To-Speech) alterado para \"%s\"", valor)
+			sessao.tela.Printf(traduz("\r\n\033[31mValor \"%s\" inválido\033[m\r\n"), valor)
+		} else if sessao.settings.TTS != b {
+			sessao.settings.TTS = b
+			sessao.tela.Printf(traduz("TTS (Text-To-Speech) alterado para \"%s\""), valor)
 			return true
 		}
 		return false
@@ -399,205 +522,290 @@ func trataComandoSet(comando string) bool {
 }
 
 // Limpa a tela quando o usuário digita o comando "cls".
-func clearScreen() {
+// Se a saída não for um terminal (ex: redirecionada para um arquivo), não faz nada.
+func (sessao *Sessao) clearScreen() {
+	if !sessao.tela.Interativo {
+		return
+	}
 	cmd := exec.Command("cmd", "/c", "cls")
 	cmd.Stdout = os.Stdout
 	cmd.Run()
 }
 
-// Esta função tem que ser acionada pelo comando "go".
-// Caso contrário, coloca o processo principal em loop infinito.
-// Imprime na tela um indicador de atividade mostrando que está aguardando resposta da API do ChatGPT.
-func pensando() {
-	setTerminouDePensar(false)
-	timeout := settings.TIMEOUT
+// Inicia a goroutine que imprime na tela um indicador de atividade, mostrando que está aguardando
+// resposta da API do ChatGPT. Retorna a função que encerra o indicador: ela só retorna depois que
+// a goroutine terminou, para que nada mais seja impresso pelo indicador depois disso.
+// Se a saída não for um terminal, o indicador não é exibido.
+func (sessao *Sessao) iniciaPensando() func() {
+	if !sessao.tela.Interativo {
+		return func() {}
+	}
+
+	fim := make(chan struct{})
+	terminou := make(chan struct{})
+	go sessao.pensando(sessao.settings.TIMEOUT, fim, terminou)
+
+	return func() {
+		close(fim)
+		<-terminou
+	}
+}
+
+// Imprime o indicador de atividade até o canal "fim" ser fechado ou o timeout zerar.
+// Ao terminar, fecha o canal "terminou".
+func (sessao *Sessao) pensando(timeout int, fim, terminou chan struct{}) {
+	defer close(terminou)
 	for contador := 0; ; contador++ {
 
-		fmt.Print("\033[93m")
+		sessao.tela.Print("\033[93m")
 		// A cada múltiplo de 4, limpa a linha e imprime o contador de timeout.
 		if contador%4 == 0 {
-			fmt.Printf("\r         \r%d", timeout)
+			sessao.tela.Printf("\r         \r%d", timeout)
 			timeout--
 		}
 
-		// Aguarda 1/4 de segundo
-		time.Sleep(time.Millisecond * 250)
+		// Aguarda 1/4 de segundo ou o fim do "pensamento".
+		select {
+		case <-fim:
+			timeout = 0
+		case <-time.After(time.Millisecond * 250):
+		}
 
 		// Verifica se terminou de "pensar"
-		if timeout == 0 || getTerminouDePensar() {
+		if timeout == 0 {
 			break
 		}
 
 		// Imprime "." de forma consecutiva para formar, no máximo, os três pontos: "..."
-		fmt.Printf("\033[%dm.", 91+rand.Intn(6))
-	}
-	fmt.Print("\033[m")
-}
-
-// Cria uma estrutura de nova requisição e armazena no histórico de mensagens.
-func newChatGPTRequest(question string) *ChatGPTRequest {
-
-	// Cria a mensagem com a pergunta do usuário. Instrui a IA a responder no idiomna selecionado.
-	msg := Message{
-		Role:    "user",
-		Content: fmt.Sprintf("%s (You must answer in \"%s\")", question, settings.IDIOMA),
-	}
-
-	// Adiciona a mensagem ao histórico de mensagens enviadas/recebidas.
-	messages = append(messages, msg)
-
-	return &ChatGPTRequest{
-		Model:       settings.GPT_MODEL,
-		Messages:    messages,
-		Temperature: settings.TEMPERATURE,
+		sessao.tela.Printf("\033[%dm.", 91+rand.Intn(6))
 	}
+	sessao.tela.Print("\033[m")
 }
 
-// Verifica se pressionou e liberou a tecla informada no parâmetro t.
-// Chama a função GetKeyState da user32.dll, que verifica o estado da tecla informada.
-// Recurso muito útil para varificar se uma tecla foi pressionada sem interromper o loop em que está.
-func teclaPressionada(t int32) bool {
-	r, _, _ := GetKeyState.Call(uintptr(t))
-	return r == 65409 //Código "mágico" que indica que a tecla foi liberada (event KeyUp).
-}
-
-// Fala o texto (via audio).
+// Prepara o texto para ser narrado no idioma informado (ex: o parâmetro IDIOMA).
 // Se o texto tiver mais que 100 caracteres, o audio é truncado e gera erro.
 // Por isso, tem que quebrar em pequenos blocos de no máximo 100 caracteres.
-func fala(s string) {
-
-	// Como o ChatGPT responde com marcadores de texto para usar na formatação na tela,
-	// alguns desses formatadores são por "acento grave". Esses caracteres são removidos apenas
-	// antes de enviar para o narrador. Não afeta na tela (este é formatado antes de imprimir)
-	textoSemFormatacao := strings.ReplaceAll(s, "`", "")
+func blocosParaFala(s, idioma string) []string {
+
+	// Como o ChatGPT responde com marcadores de texto para usar na formatação na tela (Markdown),
+	// esses marcadores, os blocos de código, as URLs e os emojis são removidos apenas antes de
+	// enviar para o narrador, e as abreviações e números são escritos por extenso.
+	// Não afeta na tela (este é formatado antes de imprimir)
+	// No modo automático (IDIOMA "auto"), o texto é normalizado no idioma predominante.
+	textoSemFormatacao := normalizaParaFala(s, idiomaDoTexto(s, idioma))
+
+	// Quebra o texto em blocos de até 100 caracteres, respeitando o fim das frases
+	// e as pausas naturais (vírgulas, ponto-e-vírgulas), para a narração soar natural.
+	return divideEmBlocos(textoSemFormatacao, TAMANHO_BLOCO)
+}
 
-	// Transforma o texto em array de palavras para poder somar o tamanho delas e quebrar em
-	// blocos de até 100 caracteres em cada bloco, sem quebrar a última palavra.
-	palavras := strings.Split(textoSemFormatacao, " ")
-	paragrafos := make([]string, 1)
-	somaCaracteres := 0
+// Inicia a narração dos blocos de texto em outra goroutine. Se houver uma narração em andamento
+// (ex: a da resposta anterior), ela é interrompida antes, pois as duas usariam os mesmos arquivos
+// de audio e o mesmo progresso. O fim da narração pode ser aguardado com narracao.Aguarda().
+// Os blocos são falados no idioma informado ou, no modo automático, no idioma de cada bloco.
+func (sessao *Sessao) iniciaNarracao(blocos []string, idioma string) {
+	if sessao.narracao.EmAndamento() {
+		sessao.controle.Interrompe()
+		sessao.narracao.Aguarda()
+	}
 
-	blocos := 0
+	sessao.controle.Inicia()
+	sessao.narracao.Inicia(blocos)
+	go sessao.fala(blocos, idioma)
+}
 
-	for _, palavra := range palavras {
+// Fala os blocos de texto (via audio), informando o progresso da narração.
+// Enquanto narra, as teclas de controle (pausa, pulo, volume e velocidade) são monitoradas.
+// Só termina depois que os downloads também terminaram, mesmo se a narração foi interrompida.
+func (sessao *Sessao) fala(blocos []string, idioma string) {
+	defer sessao.narracao.Termina()
 
-		// Se o total de aracteres mais o tamanho da palavra atual ultrapassar 100 caracteres,
-		// cria novo bloco para as próximas palavras.
-		if somaCaracteres+len(palavra) >= 100 {
-			paragrafos = append(paragrafos, "")
-			blocos++
-			somaCaracteres = 0
-		}
-		paragrafos[blocos] += palavra + " "
-		somaCaracteres += len(palavra)
-	}
+	// Ao terminar, encerra o monitoramento das teclas e aguarda a goroutine do mesmo terminar.
+	fim := make(chan struct{})
+	monitorando := make(chan struct{})
+	go func() {
+		defer close(monitorando)
+		sessao.monitoraTeclas(fim)
+	}()
+	defer func() {
+		close(fim)
+		<-monitorando
+	}()
 
 	// Aciona o download dos audios...
-	audios := downloadAudios(paragrafos)
+	audios, baixados := sessao.downloadAudios(blocos, idioma)
 	// ... e executa os audios.
-	playAudios(audios)
+	sessao.playAudios(audios)
+	<-baixados
+
+	// Descarta os audios mais antigos do cache, se ultrapassou o tamanho máximo.
+	// Só depois da narração, para não apagar os audios desta resposta antes de serem lidos.
+	if sessao.cacheAtivo() {
+		sessao.limitaCache()
+	}
 }
 
 // Executa os audios na sequência que foram criados, para manter fluidez e não ser perceptível a troca de
 // audios - executa-os sem interrupção.
-func playAudios(audios []*DownloadedAudio) error {
+// Cada audio é colocado na fila da saída de audio assim que termina de ser baixado, sem esperar pelos
+// blocos seguintes; a saída executa os audios da fila um após o outro, sem pausa entre eles.
+// Enquanto isso, os controles da narração (ESC, pausa, pulo e volume) são repassados para a saída.
+func (sessao *Sessao) playAudios(audios []*DownloadedAudio) error {
 	if len(audios) == 0 {
-		return errors.New("nenhum audio a reproduzir")
+		return novoErro("nenhum audio a reproduzir")
+	}
+
+	saida, err := sessao.saidaDeAudio()
+	if err != nil {
+		sessao.tela.Erro(err)
+		return err
 	}
 
-	for _, audio := range audios {
+	proximo := 0
+	var ultimo *TrechoAudio
+	for {
+		// A interrupção ocorre quando o usuário pressiona ESC durante a narração.
+		if sessao.controle.Interrompida() {
+			saida.Interrompe()
+			saida.Pausa(false)
+			return nil
+		}
+
+		if sessao.controle.ConsomePulo() {
+			saida.Pula()
+		}
+		saida.Pausa(sessao.controle.Pausado())
+		saida.DefineVolume(sessao.controle.Volume())
+
+		// Coloca na fila, na ordem, os audios que já terminaram de ser baixados.
+		for proximo < len(audios) && downloadConcluido(audios[proximo]) {
+			audio := audios[proximo]
+			proximo++
+
+			// Se o download deste bloco falhou, passa para o próximo.
+			if audio.Erro != nil {
+				continue
+			}
 
-		// Cria o objeto e adiciona os detalhes de execução do mesmo.
-		player := Player{
-			AudioToPlay:     audio,
-			DeleteAfterPlay: true,
+			trecho, err := sessao.carregaTrecho(audio)
+			if err != nil {
+				sessao.tela.Erro(err)
+				continue
+			}
+			saida.Enfileira(trecho)
+			ultimo = trecho
 		}
 
-		// Executa o audio passando função anônima que irá testar se o mesmo foi interrompido.
-		// A interrupção ocorre quando o usuário pressiona ESC durante a impressão da resposta na tela.
-		player.Play(func() bool { return pressionouESC })
+		// Termina quando o último audio da fila foi executado.
+		if proximo == len(audios) && (ultimo == nil || ultimo.Terminou()) {
+			return nil
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+// Lê o audio baixado e prepara o trecho para a saída de audio.
+// O progresso do trecho é repassado para a impressão da resposta acompanhar a narração.
+// Os audios do cache são mantidos para serem reaproveitados; os demais são apagados.
+func (sessao *Sessao) carregaTrecho(audio *DownloadedAudio) (*TrechoAudio, error) {
+	conteudo, err := os.ReadFile(audio.Path)
+	if err != nil {
+		return nil, err
 	}
+	if !sessao.cacheAtivo() {
+		os.Remove(audio.Path)
+	}
+
+	return NovoTrecho(audio.Sequencia, conteudo, sessao.controle, func(fracao float64) {
+		sessao.narracao.Avanca(audio.Sequencia, fracao)
+	})
+}
 
-	return nil
+// Indica se o download do audio terminou (com ou sem erro).
+func downloadConcluido(audio *DownloadedAudio) bool {
+	select {
+	case <-audio.Pronto:
+		return true
+	default:
+		return false
+	}
 }
 
 // Cria goroutines para baixar os audios para cada bloco de texto de forma concorrente.
-func downloadAudios(textos []string) []*DownloadedAudio {
-	wg := &sync.WaitGroup{}
-
+// Retorna imediatamente: os downloads são iniciados na ordem dos blocos, com no máximo
+// MAX_DOWNLOADS simultâneos, e o campo Pronto de cada audio é fechado quando o mesmo termina.
+// Assim, o primeiro bloco pode ser narrado enquanto os seguintes ainda estão sendo baixados.
+// O canal retornado é fechado quando todos os downloads terminaram.
+func (sessao *Sessao) downloadAudios(textos []string, idioma string) ([]*DownloadedAudio, <-chan struct{}) {
 	result := make([]*DownloadedAudio, 0)
+	idiomas := idiomasDosBlocos(textos, idioma)
 	for i, s := range textos {
-		wg.Add(1)
 
 		// Cria estrutura com os dados de cada arquivo de audio que será baixado para a pasta ./audio
-		downloadedAudio := &DownloadedAudio{
+		result = append(result, &DownloadedAudio{
 			Sequencia: i,
 			Path:      fmt.Sprintf("./audio/%d.mp3", i),
 			Texto:     s,
-		}
-
-		result = append(result, downloadedAudio)
-		// Dispara a goroutine de download.
-		go downloadFromGoogle(wg, downloadedAudio)
-	}
-	// Aguarda todas as goroutines terminarem de baixar os audios.
-	wg.Wait()
-	return result
-}
-
-// Envia o bloco de texto para o Google Translate para converter em audio
-// Lembrando que o limite de tamanho do texto é de 100 caracteres.
-// O parâmetro da QueryString "q" é o texto a ser narrado.
-// O parâmetro "tl" (To Language) é o idioma em que o audio será gerado.
-func downloadFromGoogle(wg *sync.WaitGroup, downloadedAudio *DownloadedAudio) {
-	defer wg.Done()
-
-	// Cria a pasta de destino dos audios baixados.
-	dir, err := os.Open("./audio")
-	if os.IsNotExist(err) {
-		os.MkdirAll("./audio", 0700)
+			Idioma:    idiomas[i],
+			Pronto:    make(chan struct{}),
+		})
 	}
 
-	dir.Close()
+	baixados := make(chan struct{})
+	go func() {
+		defer close(baixados)
+		wg := &sync.WaitGroup{}
+		vagas := make(chan struct{}, MAX_DOWNLOADS)
+
+		for _, downloadedAudio := range result {
+			// Se a narração foi interrompida, não inicia os downloads restantes.
+			if sessao.controle.Interrompida() {
+				downloadedAudio.Erro = novoErro("narração interrompida")
+				close(downloadedAudio.Pronto)
+				continue
+			}
 
-	// Transforma o texto em padrão de URL
-	txt := url.QueryEscape(downloadedAudio.Texto)
-	url := fmt.Sprintf("http://translate.google.com/translate_tts?ie=UTF-8&client=tw-ob&q=%s&tl=%s", txt, settings.IDIOMA)
+			// Aguarda uma vaga para iniciar o próximo download.
+			vagas <- struct{}{}
+			wg.Add(1)
 
-	// Estabelece a conexão com o site.
-	response, err := http.Get(url)
-	if err != nil {
-		fmt.Println("\033[31m", err.Error(), "\033[m")
-		return
-	}
-	defer response.Body.Close()
+			// Dispara a goroutine de download.
+			go func(a *DownloadedAudio) {
+				defer func() { <-vagas }()
+				sessao.baixaAudio(wg, a)
+			}(downloadedAudio)
+		}
 
-	// Cria o arquivo de destino do audio baixado.
-	output, err := os.Create(downloadedAudio.Path)
-	if err != nil {
-		fmt.Println("\033[31m", err.Error(), "\033[m")
-		return
-	}
+		// Aguarda todas as goroutines terminarem de baixar os audios.
+		wg.Wait()
+	}()
 
-	// Copia o conteúdo baixado para o arquivo de destino.
-	_, err = io.Copy(output, response.Body)
-	if err != nil {
-		fmt.Println("\033[31m", err.Error(), "\033[m")
-	}
+	return result, baixados
 }
 
 // Imprime a resposta na tela.
 // Se o parâmetro "--nosleep" for passado, não dá pausas (imprime o texto completo de uma só vez)
-func imprimeResposta(s string) {
+// Se a narração estiver ativa, o texto é impresso no ritmo em que é narrado, no idioma informado.
+func (sessao *Sessao) imprimeResposta(s, idioma string) {
 
 	// Se o parâmetro TTS (Text-To-Speech) estiver ativo, narra o texto
-	if settings.TTS {
-		go fala(s)
+	if sessao.settings.TTS {
+		sessao.iniciaNarracao(blocosParaFala(s, idioma), idioma)
 	}
 
+	// Os blocos de código não são narrados: a posição na resposta é calculada apenas
+	// sobre os caracteres que ficam fora deles.
+	totalNarrado := tamanho(blocoDeCodigo.ReplaceAllString(s, ""))
+	impressos := 0
+
+	// Tecla pressionada enquanto aguardava a narração.
+	tecla := int32(0)
+
 	// Inicia a variável "acelera" com o valor do parâmetro "--nospeep".
-	// Se for "true", imprime os caracteres de forma "lenta", simulando streaming dos mesmos.
-	acelera := noSleep
+	// Se for "false", imprime os caracteres de forma "lenta", simulando streaming dos mesmos.
+	// Se a saída não for um terminal (ex: redirecionada para um arquivo), não há pausas.
+	acelera := sessao.noSleep || !sessao.tela.Interativo
 
 	// Variável imprimiuBlocoCodigo alterna entre true/false quando encontra o marcador "```"
 	imprimiuBlocoCodigo := false
@@ -605,9 +813,6 @@ func imprimeResposta(s string) {
 	// Variável imprimiuAcentoGrave alterna entre true/false quando encontra o marcador "`"
 	imprimiuAcentoGrave := false
 
-	// pressionouESC terá o valor alterado para true se o usuário pressionar ESC durante a impressão da resposta.
-	pressionouESC = false
-
 	// countAcentoGrave conterá a quantidade de "`" seguidos. Se for 3, é um marcador de código fonte.
 	// Se for 1, é apenas uma referência a um item de código fonte.
 	countAcentoGrave := 0
@@ -622,16 +827,16 @@ func imprimeResposta(s string) {
 			// Alterna a cor para amarelo (cor 33), se já iniciou o bloco de código fonte.
 			// ou volta ao normal, se não iniciou.
 			if !imprimiuBlocoCodigo {
-				fmt.Print("\033[33m")
+				sessao.tela.Print("\033[33m")
 			} else {
-				fmt.Print("\033[m")
+				sessao.tela.Print("\033[m")
 			}
 
 			// Alterna entre true e false
 			imprimiuBlocoCodigo = !imprimiuBlocoCodigo
 			countAcentoGrave = 0
 			// Imprime o último caractere antes de retornar para o loop for.
-			fmt.Printf("%c", char)
+			sessao.tela.Printf("%c", char)
 			continue
 		}
 
@@ -640,210 +845,204 @@ func imprimeResposta(s string) {
 			// Alterna a cor para ciano (cor 96), se já iniciou a impressão de trecho entre "`"
 			// ou volta ao normal, se não iniciou.
 			if !imprimiuAcentoGrave {
-				fmt.Print("\033[96m")
+				sessao.tela.Print("\033[96m")
 			} else {
-				fmt.Print("\033[m")
+				sessao.tela.Print("\033[m")
 			}
 			imprimiuAcentoGrave = !imprimiuAcentoGrave
 			countAcentoGrave = 0
 			// Imprime o último caractere antes de retornar para o loop for.
-			fmt.Printf("%c", char)
+			sessao.tela.Printf("%c", char)
 			continue
 		}
 
 		// Zera o contador de acentos-graves, para não entrar em nenhum dos if's acima.
 		countAcentoGrave = 0
 		// Cada caractere da string é um rune. Tem que usar %c para converter para caractere.
-		fmt.Printf("%c", char)
+		sessao.tela.Printf("%c", char)
+
+		if !imprimiuBlocoCodigo {
+			impressos++
+		}
 
 		if !acelera {
-			// Gera uma pausa alearória entre 0 e MAX_DELAY milisegundos entre
-			// a impressão da cada caractere para simular streaming das respostas,
-			// apesar de ter um parâmetro na estrutura de requisição para tal.
-			// Mas, preferi simular.
-			tempoPausa := rand.Intn(settings.MAX_DELAY)
-			time.Sleep(time.Millisecond * time.Duration(tempoPausa))
+			if sessao.settings.TTS && sessao.narracao.Ativa() {
+				// Aguarda a narração alcançar o caractere impresso.
+				// O código fonte não é narrado, por isso é impresso sem pausas.
+				if !imprimiuBlocoCodigo && totalNarrado > 0 {
+					tecla = sessao.aguardaNarracao(float64(impressos) / float64(totalNarrado))
+				}
+			} else {
+				// Gera uma pausa alearória entre 0 e MAX_DELAY milisegundos entre
+				// a impressão da cada caractere para simular streaming das respostas,
+				// apesar de ter um parâmetro na estrutura de requisição para tal.
+				// Mas, preferi simular.
+				tempoPausa := rand.Intn(sessao.settings.MAX_DELAY)
+				time.Sleep(time.Millisecond * time.Duration(tempoPausa))
+			}
 		}
 
 		// Verifica se pressionou a tecla ESC, para interromper a impressão do texto
-		if teclaPressionada(TECLA_ESC) {
-			fmt.Print("\r\n\033[31m <interrompido>\033[m")
-			pressionouESC = true
+		if tecla == TECLA_ESC || teclaPressionada(TECLA_ESC) {
+			sessao.tela.Print(traduz("\r\n\033[31m <interrompido>\033[m"))
+			sessao.controle.Interrompe()
 			break
 		}
 
 		// Verifica se pressionou a tecla Barra de Espaço, para desativar o delay e imprimir o restante do texto.
-		if teclaPressionada(TECLA_ESPACO) {
+		if tecla == TECLA_ESPACO || teclaPressionada(TECLA_ESPACO) {
 			acelera = true
 		}
+		tecla = 0
 	}
 }
 
-// Prepara a requisição para enviar à API.
-// Retorna um ponteiro para um objeto do tipo http.Request.
-func sendRequest(pergunta, tipo string) (*http.Request, context.CancelFunc) {
-
-	chatGPTRequest := newChatGPTRequest(pergunta)
-
-	// Converte a estrutura do objeto para array de bytes no formato json
-	reqBytes, _ := json.Marshal(chatGPTRequest)
-	reqBody := strings.NewReader(string(reqBytes))
-
-	// Envia a requisição com o método POST para a API do ChatGPT.
-	req, _ := http.NewRequest(http.MethodPost, settings.URL_API, reqBody)
-
-	// O resultado tem que ser do tipo application/json
-	req.Header.Add("Content-Type", "application/json")
-
-	// Neste ponto que devemos usar a nossa API_KEY
-	req.Header.Add("Authorization", "Bearer "+settings.API_KEY)
-
-	// Retorna contexto e ponteiro para função de cancelamento.
-	ctx, cancel := context.WithCancel(context.Background())
-	req = req.WithContext(ctx)
-
-	return req, cancel
+// Cria o cliente da API com as configurações informadas.
+// É criado a cada uso, pois os parâmetros podem ser alterados pelo comando "/set".
+// Também é usado pelo motor de TTS da OpenAI, durante a narração (fora da goroutine principal).
+func novoCliente(cfg Settings) *falador.Client {
+	cliente := falador.NovoCliente(cfg.URL_API, cfg.API_KEY)
+	cliente.Modelo = cfg.GPT_MODEL
+	cliente.Temperatura = cfg.TEMPERATURE
+	cliente.Timeout = time.Duration(cfg.TIMEOUT) * time.Second
+	return cliente
 }
 
-// Evia a requisição para a API e aguarda a resposta.
-func obtemResposta(req *http.Request, c chan *ChatGPTResult, cf context.CancelFunc) {
-
-	// Cria goroutine para gerenciar o tempo de resposta da requisição com o timeout informado no
-	// parâmetro TIMEOUT do arquivo settings.json.
-	// Se o timeout zerar, aciona a função de cancelamento (a mesma retornada na requisição).
-	go func() {
-		timeout := settings.TIMEOUT
-		for {
-			if timeout <= 0 {
-				// Chama a função de cancelamento.
-				cf()
-				return
-			}
-			// Aguarda 1 segundo para decrementar o timeout
-			time.Sleep(time.Second)
-			timeout--
-		}
-	}()
+// Envia a pergunta à IA, na conversa atual, e aguarda a resposta.
+// Se houver erro, imprime o mesmo na tela e retorna nil.
+func (sessao *Sessao) obtemResposta(pergunta string) *falador.Resposta {
+	return sessao.trataResposta(sessao.perguntaAoServidor(pergunta))
+}
 
-	// Executa a resuisição e aguarda o retorno da mesma.
-	res, err := http.DefaultClient.Do(req)
+// Trata o retorno da API: imprime o erro, se houver, e retorna nil; senão, retorna a resposta,
+// imprimindo o json retornado se o parâmetro "--printjson" foi informado.
+func (sessao *Sessao) trataResposta(resposta *falador.Resposta, err error) *falador.Resposta {
 	if err != nil {
-		// Testa se o erro foi o acionamento da função de cancelamento (por timeout).
-		// Se cancelou por timeout, o texto do erro é "context canceled"
-		if strings.Contains(err.Error(), "context canceled") {
-			fmt.Printf("\r\033[31mServidor demorou a responder. Envie a pergunta novamente.\033[m")
-
-			// Remove a mensagem do histórico para não repetir a mesma nos próximos envios.
-			messages = messages[:len(messages)-1]
-
-		} else {
-			// Caso o erro não seja o de cancelamento, imprime na tela o erro retornado pela API.
-			fmt.Println("\r\n\033[31m", err.Error(), "\033[m")
+		var erroAPI *falador.ErroAPI
+		var erroStatus *falador.ErroStatus
+		switch {
+		case errors.Is(err, falador.ErrTempoEsgotado):
+			// A pergunta não fica no histórico, para não repetir a mesma nos próximos envios.
+			sessao.tela.Print(traduz("\r\033[31mServidor demorou a responder. Envie a pergunta novamente.\033[m"))
+		case errors.As(err, &erroAPI), errors.As(err, &erroStatus):
+			sessao.tela.Printf("\r\033[31m%s\033[m\r\n", descricaoDoErro(err))
+		default:
+			sessao.tela.Println("\r\n\033[31m", descricaoDoErro(err), "\033[m")
 		}
-		// Envia "nil" para o canal, indicando que houve erro.
-		c <- nil
-		return
+		return nil
 	}
-	defer res.Body.Close()
 
-	// Lê todo o conteúdo retornado pela API
-	retBody, err := io.ReadAll(res.Body)
-	if err != nil {
-		fmt.Println("\r\n\033[31m", err.Error(), "\033[m")
-		c <- nil
-		return
+	// Se o parâmetro "--printjson" for informado, imprime o json retornado na tela.
+	if sessao.printJson {
+		sessao.tela.Print(traduz("\r\nJSON retornado: "))
+		sessao.tela.Println(string(resposta.JSON))
 	}
+	return resposta
+}
 
-	retorno := &ChatGPTResult{}
-	err = json.Unmarshal(retBody, retorno)
-	if err != nil {
-		// Se ocorrer erro ao converter o json para a estrutura de retorno,
-		// pode ser porque retornou uma estrutura de erro, como abaixo.
-		fmt.Println("\033[31m", err.Error(), "\033[m")
-		c <- nil
+// Apresenta o aplicativo e carrega o conteúdo do arquivo settings.json.
+func (sessao *Sessao) iniciaSessao() {
+	// No modo --json, a saída padrão tem somente a resposta (sem a apresentação e as configurações).
+	if sessao.pediuSaidaJson() {
+		sessao.ativaSaidaJson()
+		sessao.carregaConfiguracoes()
 		return
 	}
 
-	// Se o parâmetro "--printjason" for informado, imprime o json retornado na tela.
-	if printJson {
-		fmt.Print("\r\nJSON retornado: ")
-		fmt.Println(string(retBody))
+	if sessao.tela.Interativo && !sessao.tela.Cores && os.Getenv("NO_COLOR") == "" {
+		sessao.tela.Println(traduz("Terminal não permite habilitar cores"))
 	}
 
-	// Se retornou algum erro na estrutura normal de retorno, o campo Choices vem vazio.
-	// E o campo "error" contém os detalhes.
-	if len(retorno.Choices) == 0 {
-		var erro struct {
-			Error struct {
-				Message string `json:"message"`
-				Type    string `json:"type"`
-				Param   string `json:"param"`
-				Code    string `json:"code"`
-			} `json:"error"`
-		}
-		err = json.Unmarshal(retBody, &erro)
-		if err != nil {
-			fmt.Printf("\r\033[31m%s\033[m\r\n", string(retBody))
-		} else {
-			fmt.Printf("\r\033[31m%s\033[m\r\n", erro.Error.Message)
-		}
-		c <- nil
-		return
+	sessao.clearScreen()
+	sessao.tela.Println("\033[92mGPT-Falador\033[m", traduz("versão")+"\033[96m", VERSAO, "\033[m")
+	sessao.tela.Println(traduz("Desenvolvido por Hugo S. Novaes (\033[96mhnovaes@yahoo.com\033[m)"))
+	sessao.tela.Separador(51)
+	sessao.carregaConfiguracoes()
+}
+
+// Envia a pergunta à IA, imprime (e narra) a resposta e grava a conversa.
+// Retorna false se não obteve a resposta.
+func (sessao *Sessao) respondePergunta(pergunta string) bool {
+	terminaPensando := sessao.iniciaPensando()
+	resposta := sessao.obtemResposta(pergunta)
+	terminaPensando()
+	if resposta == nil {
+		return false
 	}
+	sessao.tela.Print(traduz("\r\033[94m        \rResposta\033[m: "))
 
-	// Armazena o retorno no histórico de mensagens, para manter o contexto da conversa.
-	messages = append(messages, Message{
-		Role:    retorno.Choices[0].Message.Role,
-		Content: retorno.Choices[0].Message.Content,
-	})
+	sessao.imprimeResposta(resposta.Mensagem.Content, sessao.settings.IDIOMA)
 
-	// Envia o conteúdo retornado para o canal
-	c <- retorno
-}
+	sessao.tela.Println()
+
+	// Grava a conversa na pasta ./conversas (se SALVA_CONVERSAS estiver ativo).
+	if err := sessao.gravaConversa(); err != nil {
+		sessao.tela.Erro(err)
+	}
 
-// A função init() é executada antes da função main().
-// Neste momento, carrega o conteúdo do arquivo settings.json.
-func init() {
-	if err := setConsoleColors(); err != nil {
-		fmt.Println("Terminal não permite habilitar cores")
+	// Se o parâmetro --export foi informado, atualiza o arquivo com a conversa até aqui.
+	if sessao.arquivoExport != "" {
+		if err := sessao.exportaConversa("", sessao.arquivoExport); err != nil {
+			sessao.tela.Erro(err)
+		}
 	}
 
-	clearScreen()
-	fmt.Println("\033[92mGPT-Falador\033[m versão\033[96m", VERSAO, "\033[m")
-	fmt.Println("Desenvolvido por Hugo S. Novaes (\033[96mhnovaes@yahoo.com\033[m)")
-	fmt.Println("---------------------------------------------------")
-	carregaConfiguracoes()
+	// Se o parâmetro --save-audio foi informado, grava a narração da resposta no arquivo.
+	if sessao.arquivoAudio != "" {
+		if err := sessao.gravaNarracao(sessao.arquivoAudio); err != nil {
+			sessao.tela.Erro(err)
+		}
+	}
+	return true
 }
 
-func main() {
+// Obtém as perguntas e as responde, até o fim do modo interativo ou, fora dele, até a primeira resposta.
+func (sessao *Sessao) executaSessao() {
 	for {
-		pergunta := getPrompt()
+		pergunta := sessao.getPrompt()
+		if sessao.encerrada {
+			return
+		}
+
+		if sessao.saidaJson {
+			if !sessao.respondeEmJson(pergunta) {
+				sessao.codigoSaida = 1
+			}
+			return
+		}
 
 		if len(pergunta) == 0 {
-			interativo = true
+			sessao.interativo = true
 			continue
 		}
 
-		req, cancel := sendRequest(pergunta, "user")
-		go pensando()
-
-		c := make(chan *ChatGPTResult)
-		go obtemResposta(req, c, cancel)
-		retorno := <-c
-		setTerminouDePensar(true)
-		if retorno == nil {
+		// No modo de tradução, o texto é traduzido em vez de enviado à conversa.
+		responde := sessao.respondePergunta
+		if sessao.modoTraducao {
+			responde = sessao.respondeTraducao
+		}
+		if !responde(pergunta) {
+			// Fora do modo interativo, a falha encerra o programa com erro, sem pedir outra pergunta.
+			if !sessao.interativo {
+				sessao.codigoSaida = 1
+				return
+			}
 			continue
 		}
-		fmt.Print("\r\033[94m        \rResposta\033[m: ")
 
-		for _, b := range retorno.Choices {
-			imprimeResposta(b.Message.Content)
+		if !sessao.interativo {
+			// Aguarda o fim da narração antes de encerrar o programa.
+			sessao.narracao.Aguarda()
+			return
 		}
+	}
+}
 
-		fmt.Println()
-
-		if !interativo {
-			break
-		}
+func main() {
+	sessao := novaSessao(os.Args, os.Stdin, os.Stdout, os.Stderr)
+	sessao.iniciaSessao()
+	sessao.executaSessao()
+	if sessao.codigoSaida != 0 {
+		os.Exit(sessao.codigoSaida)
 	}
 }
@@ -0,0 +1,487 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gpt-falador/falador"
+)
+
+func TestObtemRespostaDoServidorFake(t *testing.T) {
+	f, sessao := novoServidorFake(t)
+	f.Resposta = "Brasília."
+
+	retorno := sessao.obtemResposta("Qual é a capital do Brasil?")
+	if retorno == nil {
+		t.Fatal("esperado retorno da API")
+	}
+	if got := retorno.Mensagem.Content; got != "Brasília." {
+		t.Errorf("resposta = %q, esperado %q", got, "Brasília.")
+	}
+
+	requisicoes := f.Requisicoes()
+	if len(requisicoes) != 1 {
+		t.Fatalf("API acionada %d vezes, esperado 1", len(requisicoes))
+	}
+	req := requisicoes[0]
+	if req.Model != "gpt-teste" {
+		t.Errorf("model = %q, esperado %q", req.Model, "gpt-teste")
+	}
+	if len(req.Messages) != 1 || !strings.HasPrefix(req.Messages[0].Content, "Qual é a capital do Brasil?") {
+		t.Errorf("mensagens enviadas = %+v", req.Messages)
+	}
+
+	// A pergunta e a resposta ficam no histórico, para manter o contexto da conversa.
+	messages := sessao.conversa.Mensagens()
+	if len(messages) != 2 || messages[1].Role != "assistant" || messages[1].Content != "Brasília." {
+		t.Errorf("histórico = %+v", messages)
+	}
+	if messages[1].Uso == nil || messages[1].Uso.TotalTokens != 15 {
+		t.Errorf("tokens consumidos = %+v, esperado 15", messages[1].Uso)
+	}
+}
+
+func TestObtemRespostaErroDaAPI(t *testing.T) {
+	f, sessao := novoServidorFake(t)
+	f.ErroChat = "modelo inexistente"
+
+	if retorno := sessao.obtemResposta("Olá"); retorno != nil {
+		t.Errorf("esperado retorno nil quando a API responde com erro, obtido %+v", retorno)
+	}
+
+	// A pergunta que falhou não fica no histórico, para não ser repetida nos próximos envios.
+	if messages := sessao.conversa.Mensagens(); len(messages) != 0 {
+		t.Errorf("histórico = %+v, esperado vazio", messages)
+	}
+}
+
+// Executa o ciclo completo de perguntas e respostas com a narração ativa, enquanto outra goroutine
+// lê o histórico e a goroutine principal altera as configurações durante a narração.
+// Deve ser executado com "go test -race" para detectar acessos concorrentes sem sincronização.
+func TestRespostaNarradaSemCorridas(t *testing.T) {
+	f, sessao := novoServidorFake(t)
+	f.Resposta = "Olá! Esta resposta é narrada em alguns trechos. Cada trecho vira um audio separado."
+	f.Audio = mp3Silencioso(4)
+	sessao.settings.TTS = true
+	sessao.settings.CACHE_TTS_MB = 1
+
+	fim := make(chan struct{})
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-fim:
+				return
+			default:
+				sessao.conversa.Mensagens()
+				sessao.controle.AjustaVolume(-PASSO_VOLUME)
+			}
+		}
+	}()
+
+	for _, pergunta := range []string{"Primeira pergunta", "Segunda pergunta"} {
+		if !sessao.respondePergunta(pergunta) {
+			t.Fatalf("sem resposta para %q", pergunta)
+		}
+		// A narração pode não ter terminado: as configurações são alteradas enquanto isso.
+		sessao.executaComando("/set lang=pt-br")
+		sessao.executaComando("/set speed=1.25")
+	}
+	sessao.narracao.Aguarda()
+	close(fim)
+	wg.Wait()
+
+	if n := len(sessao.conversa.Mensagens()); n != 4 {
+		t.Errorf("histórico com %d mensagens, esperado 4", n)
+	}
+	if sessao.narracao.Posicao() != 1 {
+		t.Errorf("posição da narração = %v, esperado 1", sessao.narracao.Posicao())
+	}
+	if len(f.Consultas()) == 0 {
+		t.Error("o TTS não foi acionado")
+	}
+}
+
+func TestTrataComandoSet(t *testing.T) {
+	testes := []struct {
+		comando  string
+		alterou  bool
+		verifica func(sessao *Sessao) bool
+	}{
+		{"model=gpt-4", true, func(sessao *Sessao) bool { return sessao.settings.GPT_MODEL == "gpt-4" }},
+		{"model=gpt-teste", false, nil},
+		{"lang=EN-US", true, func(sessao *Sessao) bool { return sessao.settings.IDIOMA == "en-us" }},
+		{"tts_engine=openai", true, func(sessao *Sessao) bool { return sessao.settings.TTS_ENGINE == MOTOR_OPENAI }},
+		{"tts_engine=inexistente", false, func(sessao *Sessao) bool { return sessao.settings.TTS_ENGINE == "" }},
+		{"voice=nova", true, func(sessao *Sessao) bool { return sessao.settings.VOICE == "nova" }},
+		{"stt_engine=whispercpp", true, func(sessao *Sessao) bool { return sessao.settings.STT_ENGINE == STT_WHISPERCPP }},
+		{"stt_engine=inexistente", false, func(sessao *Sessao) bool { return sessao.settings.STT_ENGINE == "" }},
+		{"max_delay=50", true, func(sessao *Sessao) bool { return sessao.settings.MAX_DELAY == 50 }},
+		{"max_delay=abc", false, func(sessao *Sessao) bool { return sessao.settings.MAX_DELAY == 1 }},
+		{"timeout=30", true, func(sessao *Sessao) bool { return sessao.settings.TIMEOUT == 30 }},
+		{"timeout=10", false, nil},
+		{"temperature=0.7", true, func(sessao *Sessao) bool { return sessao.settings.TEMPERATURE == 0.7 }},
+		{"temperature=quente", false, nil},
+		{"salva_conversas=true", true, func(sessao *Sessao) bool { return sessao.settings.SALVA_CONVERSAS }},
+		{"salva_conversas=talvez", false, func(sessao *Sessao) bool { return !sessao.settings.SALVA_CONVERSAS }},
+		{"cache_tts_mb=10", true, func(sessao *Sessao) bool { return sessao.settings.CACHE_TTS_MB == 10 }},
+		{"cache_tts_mb=-1", false, func(sessao *Sessao) bool { return sessao.settings.CACHE_TTS_MB == 0 }},
+		{"volume=50", true, func(sessao *Sessao) bool { return sessao.settings.VOLUME == 50 && sessao.controle.VolumeAtual() == 50 }},
+		{"volume=150", false, func(sessao *Sessao) bool { return sessao.controle.VolumeAtual() == VOLUME_MAXIMO }},
+		{"speed=1.5", true, func(sessao *Sessao) bool {
+			return sessao.settings.VELOCIDADE == 1.5 && sessao.controle.Velocidade() == 1.5
+		}},
+		{"speed=3", false, func(sessao *Sessao) bool { return sessao.controle.Velocidade() == 1 }},
+		{"tts=true", true, func(sessao *Sessao) bool { return sessao.settings.TTS }},
+		{"tts=false", false, nil},
+		{" tts = true ", true, func(sessao *Sessao) bool { return sessao.settings.TTS }},
+		{"inexistente=1", false, nil},
+		{"model", false, func(sessao *Sessao) bool { return sessao.settings.GPT_MODEL == "gpt-teste" }},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.comando, func(t *testing.T) {
+			sessao := configuraTeste(t)
+
+			var alterou bool
+			capturaSaida(t, sessao, func() { alterou = sessao.trataComandoSet(tt.comando) })
+			if alterou != tt.alterou {
+				t.Errorf("trataComandoSet(%q) = %v, esperado %v", tt.comando, alterou, tt.alterou)
+			}
+			if tt.verifica != nil && !tt.verifica(sessao) {
+				t.Errorf("configurações após \"/set %s\": %+v", tt.comando, *sessao.settings)
+			}
+		})
+	}
+}
+
+func TestGetPromptArgumentos(t *testing.T) {
+	testes := []struct {
+		nome       string
+		args       []string
+		entrada    []string
+		pergunta   string
+		verifica   func(sessao *Sessao) bool
+		mensagens  int
+		interativo bool
+	}{
+		{nome: "pergunta", args: []string{"Qual", "é", "a", "capital?"}, pergunta: "Qual é a capital?"},
+		{nome: "nosleep", args: []string{"--nosleep", "Olá"}, pergunta: "Olá", verifica: func(sessao *Sessao) bool { return sessao.noSleep }},
+		{nome: "printjson", args: []string{"Olá", "--printjson"}, pergunta: "Olá", verifica: func(sessao *Sessao) bool { return sessao.printJson }},
+		{nome: "export", args: []string{"--export", "conversa.md", "Olá"}, pergunta: "Olá", verifica: func(sessao *Sessao) bool { return sessao.arquivoExport == "conversa.md" }},
+		{nome: "export com =", args: []string{"--export=conversa.html", "Olá"}, pergunta: "Olá", verifica: func(sessao *Sessao) bool { return sessao.arquivoExport == "conversa.html" }},
+		{nome: "save-audio", args: []string{"--save-audio", "resposta.wav", "Olá"}, pergunta: "Olá", verifica: func(sessao *Sessao) bool { return sessao.arquivoAudio == "resposta.wav" }},
+		{nome: "save-audio com =", args: []string{"--save-audio=resposta.mp3", "Olá"}, pergunta: "Olá", verifica: func(sessao *Sessao) bool { return sessao.arquivoAudio == "resposta.mp3" }},
+		{nome: "history", args: []string{"--history", "historico.json", "E", "agora?"}, pergunta: "E agora?", mensagens: 2},
+		{nome: "interativo com pergunta", args: []string{"--interativo", "Olá"}, pergunta: "Olá", interativo: true},
+		{nome: "sem argumentos", entrada: []string{"", "Pergunta digitada"}, pergunta: "Pergunta digitada", interativo: true},
+		{nome: "comando antes da pergunta", entrada: []string{"/set tts=true", "//set não é comando"}, pergunta: "/set não é comando", interativo: true,
+			verifica: func(sessao *Sessao) bool { return sessao.settings.TTS }},
+		{nome: "fim da entrada", args: []string{"--interativo"}, pergunta: "", interativo: true, verifica: func(sessao *Sessao) bool { return sessao.encerrada }},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			sessao := configuraTeste(t)
+			historico, _ := json.Marshal([]Message{{Role: "user", Content: "Oi"}, {Role: "assistant", Content: "Olá!"}})
+			if err := os.WriteFile("historico.json", historico, 0600); err != nil {
+				t.Fatal(err)
+			}
+			sessao.argumentos = append([]string{"gpt"}, tt.args...)
+			entradaTeste(sessao, tt.entrada...)
+
+			var pergunta string
+			capturaSaida(t, sessao, func() { pergunta = sessao.getPrompt() })
+			if pergunta != tt.pergunta {
+				t.Errorf("pergunta = %q, esperado %q", pergunta, tt.pergunta)
+			}
+			if sessao.interativo != tt.interativo {
+				t.Errorf("interativo = %v, esperado %v", sessao.interativo, tt.interativo)
+			}
+			if tt.verifica != nil && !tt.verifica(sessao) {
+				t.Error("parâmetro não foi aplicado")
+			}
+			if n := len(sessao.conversa.Mensagens()); n != tt.mensagens {
+				t.Errorf("histórico com %d mensagens, esperado %d", n, tt.mensagens)
+			}
+			if len(sessao.argumentos) != 0 {
+				t.Errorf("argumentos não foram limpos: %q", sessao.argumentos)
+			}
+		})
+	}
+}
+
+func TestImprimeResposta(t *testing.T) {
+	testes := []struct {
+		nome     string
+		resposta string
+		tecla    int32
+		saida    string
+	}{
+		{nome: "texto simples", resposta: "Olá, mundo!", saida: "Olá, mundo!"},
+		{nome: "código na linha", resposta: "Use `go test` agora.", saida: "Use \033[96mgo test\033[m agora."},
+		{nome: "bloco de código", resposta: "Veja:\n```go\nx := 1\n```\nPronto.", saida: "Veja:\n\033[33mgo\nx := 1\n\033[m\nPronto."},
+		{nome: "ESC interrompe", resposta: "Olá, mundo!", tecla: TECLA_ESC, saida: "O\r\n\033[31m <interrompido>\033[m"},
+		{nome: "ESPAÇO imprime sem pausas", resposta: "Resposta que levaria muito tempo para imprimir.", tecla: TECLA_ESPACO,
+			saida: "Resposta que levaria muito tempo para imprimir."},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			sessao := configuraTeste(t)
+			teclado := novoTecladoFake(t, sessao)
+			sessao.controle.Inicia()
+
+			// Sem tecla pressionada, imprime sem pausas. Com a tecla, as pausas seriam longas:
+			// a tecla é lida depois da pausa do primeiro caractere.
+			if tt.tecla == 0 {
+				sessao.noSleep = true
+			} else {
+				sessao.settings.MAX_DELAY = 300
+				teclado.Pressiona(tt.tecla)
+			}
+
+			inicio := time.Now()
+			saida := capturaSaida(t, sessao, func() { sessao.imprimeResposta(tt.resposta, sessao.settings.IDIOMA) })
+			if saida != tt.saida {
+				t.Errorf("saída = %q, esperado %q", saida, tt.saida)
+			}
+			if time.Since(inicio) > time.Second {
+				t.Errorf("impressão demorou %v", time.Since(inicio))
+			}
+			if interrompida := sessao.controle.Interrompida(); interrompida != (tt.tecla == TECLA_ESC) {
+				t.Errorf("narração interrompida = %v", interrompida)
+			}
+		})
+	}
+}
+
+func TestObtemRespostaErros(t *testing.T) {
+	testes := []struct {
+		nome    string
+		prepara func(f *servidorFake, sessao *Sessao)
+		saida   string
+		erro    func(err error) bool // Se informada, confere o erro retornado pela API.
+	}{
+		{"erro da API", func(f *servidorFake, sessao *Sessao) { f.ErroChat = "modelo inexistente" }, "modelo inexistente", nil},
+		{"chave inválida", func(f *servidorFake, sessao *Sessao) { sessao.settings.API_KEY = "outra" }, "chave inválida", nil},
+		{"json inválido", func(f *servidorFake, sessao *Sessao) { f.CorpoChat = "{inválido" }, "invalid character", nil},
+		{"resposta sem choices", func(f *servidorFake, sessao *Sessao) { f.CorpoChat = `{"id":"x"}` }, `{"id":"x"}`, nil},
+		{"timeout", func(f *servidorFake, sessao *Sessao) {
+			sessao.settings.TIMEOUT = 1
+			f.Atraso = time.Minute
+		}, "Servidor demorou a responder", nil},
+		// A mensagem do erro de conexão depende do sistema operacional.
+		{"servidor fora do ar", func(f *servidorFake, sessao *Sessao) { f.Close() }, "", func(err error) bool {
+			return errors.As(err, new(*net.OpError))
+		}},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			f, sessao := novoServidorFake(t)
+			tt.prepara(f, sessao)
+
+			var resposta interface{}
+			var err error
+			saida := capturaSaida(t, sessao, func() {
+				var r *falador.Resposta
+				r, err = sessao.perguntaAoServidor("Olá")
+				if r = sessao.trataResposta(r, err); r != nil {
+					resposta = r
+				}
+			})
+			if resposta != nil {
+				t.Errorf("esperado retorno nil, obtido %+v", resposta)
+			}
+			if !strings.Contains(saida, tt.saida) {
+				t.Errorf("saída = %q, esperado conter %q", saida, tt.saida)
+			}
+			if tt.erro != nil && !tt.erro(err) {
+				t.Errorf("erro = %#v (%v), não é o esperado", err, err)
+			}
+			if n := len(sessao.conversa.Mensagens()); n != 0 {
+				t.Errorf("histórico com %d mensagens, esperado 0", n)
+			}
+		})
+	}
+}
+
+// Sessão completa no modo interativo: as perguntas e comandos são lidos da entrada simulada,
+// respondidos pelo servidor falso e narrados pela saída de audio nula.
+func TestSessaoInterativa(t *testing.T) {
+	f, sessao := novoServidorFake(t)
+	f.Resposta = "Brasília."
+	f.Audio = mp3Silencioso(4)
+	sessao.settings.TTS = true
+	sessao.settings.SALVA_CONVERSAS = true
+	sessao.settings.CACHE_TTS_MB = 1
+
+	sessao.argumentos = []string{"gpt"}
+	entradaTeste(sessao,
+		"Qual é a capital do Brasil?",
+		"/set tts=false",
+		"/export conversa.md",
+		"E a da Argentina?",
+		"/quit",
+		"Esta linha não deve ser lida",
+	)
+
+	terminou := make(chan string)
+	go func() {
+		terminou <- capturaSaida(t, sessao, sessao.executaSessao)
+	}()
+
+	var saida string
+	select {
+	case saida = <-terminou:
+	case <-time.After(20 * time.Second):
+		t.Fatal("a sessão não terminou")
+	}
+
+	requisicoes := f.Requisicoes()
+	if len(requisicoes) != 2 {
+		t.Fatalf("API acionada %d vezes, esperado 2", len(requisicoes))
+	}
+	// A segunda pergunta é enviada com o histórico da primeira.
+	if n := len(requisicoes[1].Messages); n != 3 {
+		t.Errorf("segunda requisição com %d mensagens, esperado 3", n)
+	}
+
+	// Somente a primeira resposta foi narrada (o TTS foi desativado antes da segunda).
+	if len(f.Consultas()) == 0 {
+		t.Error("a primeira resposta não foi narrada")
+	}
+	if sessao.settings.TTS {
+		t.Error("o comando \"/set tts=false\" não foi aplicado")
+	}
+	gravadas, err := os.ReadFile(SETTINGS)
+	if err != nil || !strings.Contains(string(gravadas), `"TTS": false`) {
+		t.Errorf("settings.json não foi gravado com o TTS desativado: %s %v", gravadas, err)
+	}
+
+	exportada, err := os.ReadFile("conversa.md")
+	if err != nil || !strings.Contains(string(exportada), "Qual é a capital do Brasil?") {
+		t.Errorf("conversa exportada: %s %v", exportada, err)
+	}
+	if conversas, _ := filepath.Glob(filepath.Join(CONVERSAS, "2*.json")); len(conversas) != 1 {
+		t.Errorf("conversas gravadas: %q, esperado 1", conversas)
+	}
+
+	if strings.Count(saida, "Resposta\033[m: Brasília.") != 2 {
+		t.Errorf("esperadas 2 respostas na saída: %q", saida)
+	}
+	if !sessao.encerrada {
+		t.Error("a sessão não foi encerrada pelo \"/quit\"")
+	}
+	if linha, _ := sessao.entrada.ReadString('\n'); linha != "Esta linha não deve ser lida\n" {
+		t.Errorf("a entrada foi lida após o \"/quit\": restou %q", linha)
+	}
+}
+
+func TestSaidaJson(t *testing.T) {
+	testes := []struct {
+		nome     string
+		args     []string
+		prepara  func(f *servidorFake, sessao *Sessao)
+		resposta string
+		erro     string
+	}{
+		{"sucesso", []string{"--json", "Capital", "do", "Brasil?"}, func(f *servidorFake, sessao *Sessao) { f.Resposta = "Brasília." }, "Brasília.", ""},
+		{"ignora --interativo", []string{"--interativo", "--json", "Olá"}, func(f *servidorFake, sessao *Sessao) {}, "Resposta do servidor falso.", ""},
+		{"erro da API", []string{"--json", "Olá"}, func(f *servidorFake, sessao *Sessao) { f.ErroChat = "modelo inexistente" }, "", "modelo inexistente"},
+		{"timeout", []string{"--json", "Olá"}, func(f *servidorFake, sessao *Sessao) {
+			sessao.settings.TIMEOUT = 1
+			f.Atraso = time.Minute
+		}, "", "servidor demorou a responder"},
+		{"sem pergunta", []string{"--json"}, func(f *servidorFake, sessao *Sessao) {}, "", "nenhuma pergunta informada"},
+		{"tradução", []string{"--json", "translate", "--to", "en-us", "Bom", "dia"}, func(f *servidorFake, sessao *Sessao) { f.Resposta = "Good morning." }, "Good morning.", ""},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			f, sessao := novoServidorFake(t)
+			tt.prepara(f, sessao)
+			sessao.settings.TTS = true
+
+			saida, erros := &bytes.Buffer{}, &bytes.Buffer{}
+			sessao.saidaPadrao, sessao.saidaErros = saida, erros
+			sessao.argumentos = append([]string{"gpt"}, tt.args...)
+
+			// Nada deve ir para a tela, nem a resposta nem a narração.
+			naTela := capturaSaida(t, sessao, sessao.executaSessao)
+			if naTela != "" {
+				t.Errorf("saída na tela: %q", naTela)
+			}
+			if erros.Len() != 0 {
+				t.Errorf("saída de erros: %q", erros.String())
+			}
+			if len(f.Consultas()) != 0 {
+				t.Errorf("resposta narrada no modo --json")
+			}
+
+			// A saída padrão tem um único objeto json, em uma linha.
+			if n := strings.Count(saida.String(), "\n"); n != 1 {
+				t.Fatalf("saída com %d linhas, esperado 1: %q", n, saida.String())
+			}
+			r := RespostaJson{}
+			if err := json.Unmarshal(saida.Bytes(), &r); err != nil {
+				t.Fatal(err)
+			}
+
+			if r.Answer != tt.resposta {
+				t.Errorf("answer = %q, esperado %q", r.Answer, tt.resposta)
+			}
+			if r.Model != "gpt-teste" {
+				t.Errorf("model = %q, esperado gpt-teste", r.Model)
+			}
+			if tt.erro == "" {
+				if r.Error != "" || sessao.codigoSaida != 0 {
+					t.Errorf("error = %q, código de saída %d", r.Error, sessao.codigoSaida)
+				}
+				if r.FinishReason != "stop" || r.Usage == nil || r.Usage.TotalTokens != 15 {
+					t.Errorf("finish_reason = %q, usage = %+v", r.FinishReason, r.Usage)
+				}
+			} else {
+				if !strings.Contains(r.Error, tt.erro) || sessao.codigoSaida != 1 {
+					t.Errorf("error = %q, código de saída %d; esperado conter %q e código 1", r.Error, sessao.codigoSaida, tt.erro)
+				}
+				if r.Usage != nil {
+					t.Errorf("usage = %+v, esperado nenhum", r.Usage)
+				}
+			}
+		})
+	}
+}
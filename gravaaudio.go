@@ -0,0 +1,306 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+const (
+	FORMATO_MP3 = "mp3"
+	FORMATO_WAV = "wav"
+)
+
+func init() {
+	registraComando(&Comando{
+		Nome:       "speak",
+		Aliases:    []string{"fala"},
+		Argumentos: "replay | save <arquivo> | volume <0-100> | speed <0.5-2>",
+		Descricao: "Controla a narração da última resposta:\r\n" +
+			"replay narra a resposta novamente; save grava a narração no arquivo (.mp3 ou .wav);\r\n" +
+			"volume e speed alteram o volume e a velocidade da narração (a velocidade também altera o tom da voz).",
+		MinArgs: 1,
+		MaxArgs: 2,
+		Executa: (*Sessao).trataComandoSpeak,
+	})
+
+	registraSubcomando(&Subcomando{
+		Nome:       "speak",
+		Argumentos: "<texto>",
+		Descricao: "Narra o texto informado, sem enviá-lo à IA. Com o parâmetro \033[36m--save-audio\033[m,\n" +
+			"grava a narração no arquivo (ex: \033[36mgpt speak --save-audio bomdia.mp3 Bom dia!\033[m).",
+		Executa: func(sessao *Sessao, args []string) (string, error) {
+			sessao.encerrada = true
+			texto := strings.TrimSpace(strings.Join(args, " "))
+			if texto == "" {
+				return "", novoErro("informe o texto a narrar (ex: gpt speak \"Bom dia!\")")
+			}
+
+			if sessao.arquivoAudio != "" {
+				if err := sessao.gravaNarracaoDoTexto(texto, sessao.settings.IDIOMA, sessao.arquivoAudio); err != nil {
+					return "", err
+				}
+				sessao.tela.Printf(traduz("Narração gravada em \"%s\"\r\n"), sessao.arquivoAudio)
+				return "", nil
+			}
+
+			sessao.iniciaNarracao(blocosParaFala(texto, sessao.settings.IDIOMA), sessao.settings.IDIOMA)
+			sessao.narracao.Aguarda()
+			return "", nil
+		},
+	})
+}
+
+// Trata o comando "/speak <opção>" digitado no modo interativo.
+func (sessao *Sessao) trataComandoSpeak(args []string) string {
+	opcao := strings.ToLower(args[0])
+	valor := ""
+	if len(args) == 2 {
+		valor = args[1]
+	}
+
+	var err error
+	switch {
+	case opcao == "replay" && valor == "":
+		err = sessao.repeteNarracao()
+
+	case opcao == "save" && valor != "":
+		if err = sessao.gravaNarracao(valor); err == nil {
+			sessao.tela.Printf(traduz("Narração gravada em \"%s\""), valor)
+		}
+
+	case opcao == "volume" && valor != "":
+		var v int
+		if v, err = strconv.Atoi(valor); err == nil {
+			sessao.tela.Printf(traduz("Volume alterado para \"%d\""), sessao.controle.DefineVolume(v))
+		}
+
+	case opcao == "speed" && valor != "":
+		var v float64
+		if v, err = strconv.ParseFloat(valor, 64); err == nil {
+			sessao.tela.Printf(traduz("Velocidade alterada para \"%.2f\""), sessao.controle.DefineVelocidade(v))
+		}
+
+	default:
+		err = novoErro("opção inválida. Uso: /speak %s", traduz(comandosPorNome["speak"].Argumentos))
+	}
+
+	if err != nil {
+		sessao.tela.Erro(err)
+	}
+	return ""
+}
+
+// Retorna a última resposta da conversa.
+func (sessao *Sessao) ultimaResposta() (string, error) {
+	if resposta, ok := sessao.conversa.UltimaResposta(); ok {
+		return resposta.Content, nil
+	}
+	return "", novoErro("não há resposta na conversa para narrar")
+}
+
+// Grava a narração da última resposta no arquivo informado. Os blocos de audio são obtidos
+// do cache (ou baixados novamente, se o cache estiver inativo) e juntados em um único arquivo:
+// em MP3, os arquivos são concatenados; em WAV, os audios são decodificados e gravados em PCM.
+func (sessao *Sessao) gravaNarracao(caminho string) error {
+	resposta, err := sessao.ultimaResposta()
+	if err != nil {
+		return err
+	}
+	return sessao.gravaNarracaoDoTexto(resposta, sessao.settings.IDIOMA, caminho)
+}
+
+// Grava a narração do texto, no idioma informado, no arquivo informado (.mp3 ou .wav).
+func (sessao *Sessao) gravaNarracaoDoTexto(texto, idioma, caminho string) error {
+	formato := strings.TrimPrefix(strings.ToLower(filepath.Ext(caminho)), ".")
+	if formato != FORMATO_MP3 && formato != FORMATO_WAV {
+		return novoErro("formato \"%s\" inválido. Use .mp3 ou .wav", filepath.Ext(caminho))
+	}
+
+	blocos := blocosParaFala(texto, idioma)
+	if len(blocos) == 0 {
+		return novoErro("o texto não tem trechos para narrar")
+	}
+
+	// Os audios são baixados para uma pasta temporária, para não conflitarem com os
+	// da narração que pode estar em andamento.
+	temp, err := os.MkdirTemp("", "gpt-audio-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(temp)
+
+	audios := sessao.baixaAudios(blocos, idioma, temp)
+	for _, a := range audios {
+		if a.Erro != nil {
+			return novoErro("falha ao obter o audio do trecho \"%s\": %w", a.Texto, a.Erro)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if formato == FORMATO_MP3 {
+		err = concatenaMP3(buf, audios)
+	} else {
+		err = gravaWAV(buf, audios)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Descarta os audios mais antigos do cache só depois de juntar os trechos,
+	// para não apagar os audios que ainda seriam lidos.
+	if sessao.cacheAtivo() {
+		sessao.limitaCache()
+	}
+
+	return os.WriteFile(caminho, buf.Bytes(), 0600)
+}
+
+// Baixa os audios dos blocos para a pasta informada e aguarda todos terminarem.
+// Ao contrário de downloadAudios, não é interrompido pela tecla ESC.
+func (sessao *Sessao) baixaAudios(blocos []string, idioma, pasta string) []*DownloadedAudio {
+	audios := make([]*DownloadedAudio, 0, len(blocos))
+	wg := &sync.WaitGroup{}
+	vagas := make(chan struct{}, MAX_DOWNLOADS)
+	idiomas := idiomasDosBlocos(blocos, idioma)
+
+	for i, s := range blocos {
+		a := &DownloadedAudio{
+			Sequencia: i,
+			Path:      filepath.Join(pasta, fmt.Sprintf("%d.mp3", i)),
+			Texto:     s,
+			Idioma:    idiomas[i],
+			Pronto:    make(chan struct{}),
+		}
+		audios = append(audios, a)
+
+		vagas <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-vagas }()
+			sessao.baixaAudio(wg, a)
+		}()
+	}
+	wg.Wait()
+	return audios
+}
+
+// Concatena os arquivos MP3. Os frames de MP3 são independentes, então basta juntá-los;
+// apenas a tag ID3 dos arquivos seguintes ao primeiro é removida.
+func concatenaMP3(w io.Writer, audios []*DownloadedAudio) error {
+	for i, a := range audios {
+		conteudo, err := os.ReadFile(a.Path)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			conteudo = removeTagID3(conteudo)
+		}
+		if _, err = w.Write(conteudo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove a tag ID3v2 do início do arquivo MP3, se houver.
+// O tamanho da tag é gravado em 4 bytes de 7 bits cada ("synchsafe"), sem contar o cabeçalho de 10 bytes.
+func removeTagID3(conteudo []byte) []byte {
+	if len(conteudo) < 10 || string(conteudo[:3]) != "ID3" {
+		return conteudo
+	}
+	tamanho := 10 + (int(conteudo[6])<<21 | int(conteudo[7])<<14 | int(conteudo[8])<<7 | int(conteudo[9]))
+	if conteudo[5]&0x10 != 0 {
+		tamanho += 10 // Rodapé da tag.
+	}
+	if tamanho > len(conteudo) {
+		return conteudo
+	}
+	return conteudo[tamanho:]
+}
+
+// Decodifica os arquivos MP3 e grava o audio em WAV (PCM de 16 bits, estéreo, como o
+// retornado pelo decodificador), na taxa de amostragem do primeiro audio.
+// Os audios com outra taxa de amostragem são reamostrados.
+func gravaWAV(w io.Writer, audios []*DownloadedAudio) error {
+	pcm := &bytes.Buffer{}
+	taxa := 0
+
+	for _, a := range audios {
+		conteudo, err := os.ReadFile(a.Path)
+		if err != nil {
+			return err
+		}
+		decoder, err := mp3.NewDecoder(bytes.NewReader(conteudo))
+		if err != nil {
+			return err
+		}
+		if taxa == 0 {
+			taxa = decoder.SampleRate()
+		}
+
+		var audio io.Reader = decoder
+		if decoder.SampleRate() != taxa {
+			fator := float64(decoder.SampleRate()) / float64(taxa)
+			audio = &reamostrador{r: decoder, fator: func() float64 { return fator }}
+		}
+		if _, err = io.Copy(pcm, audio); err != nil {
+			return err
+		}
+	}
+
+	if err := cabecalhoWAV(w, taxa, 2, pcm.Len()); err != nil {
+		return err
+	}
+	_, err := io.Copy(w, pcm)
+	return err
+}
+
+// Grava o cabeçalho RIFF/WAVE com o bloco "fmt " (PCM de 16 bits) e o início do bloco "data",
+// que deve ser seguido pelos "tamanho" bytes do audio.
+func cabecalhoWAV(w io.Writer, taxa, canais, tamanho int) error {
+	const bytesPorAmostra = 2
+
+	cabecalho := []interface{}{
+		[]byte("RIFF"), uint32(36 + tamanho), []byte("WAVE"),
+		[]byte("fmt "), uint32(16), uint16(1), uint16(canais), uint32(taxa),
+		uint32(taxa * canais * bytesPorAmostra), uint16(canais * bytesPorAmostra), uint16(bytesPorAmostra * 8),
+		[]byte("data"), uint32(tamanho),
+	}
+	for _, campo := range cabecalho {
+		if err := binary.Write(w, binary.LittleEndian, campo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
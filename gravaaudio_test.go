@@ -0,0 +1,268 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+func TestTrataComandoSpeak(t *testing.T) {
+	testes := []struct {
+		args       []string
+		volume     int
+		velocidade float64
+		saida      string
+	}{
+		{[]string{"volume", "50"}, 50, 1, `Volume alterado para "50"`},
+		{[]string{"volume", "150"}, VOLUME_MAXIMO, 1, `Volume alterado para "100"`},
+		{[]string{"volume", "-5"}, 0, 1, `Volume alterado para "0"`},
+		{[]string{"volume", "alto"}, VOLUME_MAXIMO, 1, "invalid syntax"},
+		{[]string{"speed", "1.5"}, VOLUME_MAXIMO, 1.5, `Velocidade alterada para "1.50"`},
+		{[]string{"SPEED", "1.25"}, VOLUME_MAXIMO, 1.25, `Velocidade alterada para "1.25"`},
+		{[]string{"speed", "3"}, VOLUME_MAXIMO, VELOCIDADE_MAXIMA, `Velocidade alterada para "2.00"`},
+		{[]string{"speed", "0.1"}, VOLUME_MAXIMO, VELOCIDADE_MINIMA, `Velocidade alterada para "0.50"`},
+		{[]string{"speed"}, VOLUME_MAXIMO, 1, "opção inválida"},
+		{[]string{"replay", "agora"}, VOLUME_MAXIMO, 1, "opção inválida"},
+		{[]string{"pular"}, VOLUME_MAXIMO, 1, "opção inválida"},
+	}
+
+	for _, tt := range testes {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			sessao := configuraTeste(t)
+			saida := capturaSaida(t, sessao, func() { sessao.trataComandoSpeak(tt.args) })
+
+			if !strings.Contains(saida, tt.saida) {
+				t.Errorf("saída = %q, esperado conter %q", saida, tt.saida)
+			}
+			if sessao.controle.VolumeAtual() != tt.volume || sessao.controle.Velocidade() != tt.velocidade {
+				t.Errorf("volume = %d, velocidade = %.2f; esperado %d e %.2f",
+					sessao.controle.VolumeAtual(), sessao.controle.Velocidade(), tt.volume, tt.velocidade)
+			}
+		})
+	}
+}
+
+// Monta uma tag ID3v2 com o conteúdo informado (o tamanho é gravado em 4 bytes de 7 bits).
+func tagID3(conteudo []byte, rodape bool) []byte {
+	tamanho := len(conteudo)
+	flags := byte(0)
+	if rodape {
+		flags = 0x10
+	}
+	tag := []byte{'I', 'D', '3', 4, 0, flags, byte(tamanho >> 21 & 0x7F), byte(tamanho >> 14 & 0x7F), byte(tamanho >> 7 & 0x7F), byte(tamanho & 0x7F)}
+	tag = append(tag, conteudo...)
+	if rodape {
+		tag = append(tag, []byte{'3', 'D', 'I', 4, 0, flags, tag[6], tag[7], tag[8], tag[9]}...)
+	}
+	return tag
+}
+
+func TestRemoveTagID3(t *testing.T) {
+	audio := mp3Silencioso(2)
+	grande := bytes.Repeat([]byte{0xAB}, 300) // 300 = 0x12C: exige mais de um byte de 7 bits.
+
+	testes := []struct {
+		nome     string
+		conteudo []byte
+		esperado []byte
+	}{
+		{"sem tag", audio, audio},
+		{"tag vazia", append(tagID3(nil, false), audio...), audio},
+		{"tag de 300 bytes", append(tagID3(grande, false), audio...), audio},
+		{"tag com rodapé", append(tagID3(grande, true), audio...), audio},
+		{"somente a tag", tagID3(grande, false), []byte{}},
+		{"tag maior que o arquivo", tagID3(grande, false)[:200], tagID3(grande, false)[:200]},
+		{"menor que o cabeçalho", []byte("ID3\x04"), []byte("ID3\x04")},
+		{"texto ID3 fora do início", append([]byte{0}, tagID3(nil, false)...), append([]byte{0}, tagID3(nil, false)...)},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			if r := removeTagID3(tt.conteudo); !bytes.Equal(r, tt.esperado) {
+				t.Errorf("removeTagID3 retornou %d bytes, esperado %d", len(r), len(tt.esperado))
+			}
+		})
+	}
+}
+
+// Grava os audios na pasta atual (a pasta temporária do teste) e retorna os mesmos.
+func audiosTeste(t *testing.T, conteudos ...[]byte) []*DownloadedAudio {
+	t.Helper()
+	audios := make([]*DownloadedAudio, 0, len(conteudos))
+	for i, c := range conteudos {
+		a := &DownloadedAudio{Sequencia: i, Path: filepath.Join(".", fmt.Sprintf("%d.mp3", i))}
+		if err := os.WriteFile(a.Path, c, 0600); err != nil {
+			t.Fatal(err)
+		}
+		audios = append(audios, a)
+	}
+	return audios
+}
+
+// Somente a tag ID3 do primeiro arquivo é mantida; os frames de todos são juntados na ordem.
+func TestConcatenaMP3(t *testing.T) {
+	configuraTeste(t)
+	primeiro, segundo := mp3Silencioso(3), mp3Silencioso(5)
+	tag := tagID3([]byte("TIT2 primeiro"), false)
+
+	audios := audiosTeste(t, append(tag, primeiro...), append(tagID3([]byte("TIT2 segundo"), true), segundo...), segundo)
+	buf := &bytes.Buffer{}
+	if err := concatenaMP3(buf, audios); err != nil {
+		t.Fatal(err)
+	}
+
+	esperado := bytes.Join([][]byte{tag, primeiro, segundo, segundo}, nil)
+	if !bytes.Equal(buf.Bytes(), esperado) {
+		t.Errorf("concatenaMP3 gravou %d bytes, esperado %d", buf.Len(), len(esperado))
+	}
+
+	audios = append(audios, &DownloadedAudio{Path: "inexistente.mp3"})
+	if err := concatenaMP3(io.Discard, audios); err == nil {
+		t.Error("esperado erro com arquivo inexistente")
+	}
+}
+
+// Campos do cabeçalho WAV (44 bytes), na ordem em que são gravados.
+type cabecalhoWAVTeste struct {
+	Riff           [4]byte
+	TamanhoRiff    uint32
+	Wave           [4]byte
+	Fmt            [4]byte
+	TamanhoFmt     uint32
+	Formato        uint16
+	Canais         uint16
+	Taxa           uint32
+	BytesPorSeg    uint32
+	Alinhamento    uint16
+	BitsPorAmostra uint16
+	Data           [4]byte
+	TamanhoData    uint32
+}
+
+func leCabecalhoWAV(t *testing.T, wav []byte) cabecalhoWAVTeste {
+	t.Helper()
+	c := cabecalhoWAVTeste{}
+	if err := binary.Read(bytes.NewReader(wav), binary.LittleEndian, &c); err != nil {
+		t.Fatal(err)
+	}
+	if string(c.Riff[:]) != "RIFF" || string(c.Wave[:]) != "WAVE" || string(c.Fmt[:]) != "fmt " || string(c.Data[:]) != "data" {
+		t.Errorf("identificadores do cabeçalho inválidos: %q", wav[:44])
+	}
+	if c.TamanhoFmt != 16 || c.Formato != 1 || c.BitsPorAmostra != 16 {
+		t.Errorf("bloco fmt = %+v, esperado PCM de 16 bits", c)
+	}
+	return c
+}
+
+func TestCabecalhoWAV(t *testing.T) {
+	testes := []struct {
+		taxa, canais, tamanho    int
+		bytesPorSeg, alinhamento int
+	}{
+		{44100, 2, 1000, 176400, 4},
+		{24000, 2, 0, 96000, 4},
+		{16000, 1, 3200, 32000, 2},
+	}
+
+	for _, tt := range testes {
+		buf := &bytes.Buffer{}
+		if err := cabecalhoWAV(buf, tt.taxa, tt.canais, tt.tamanho); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() != 44 {
+			t.Fatalf("cabeçalho com %d bytes, esperado 44", buf.Len())
+		}
+
+		c := leCabecalhoWAV(t, buf.Bytes())
+		if int(c.TamanhoRiff) != 36+tt.tamanho || int(c.TamanhoData) != tt.tamanho || int(c.Taxa) != tt.taxa ||
+			int(c.Canais) != tt.canais || int(c.BytesPorSeg) != tt.bytesPorSeg || int(c.Alinhamento) != tt.alinhamento {
+			t.Errorf("cabecalhoWAV(%d, %d, %d) = %+v", tt.taxa, tt.canais, tt.tamanho, c)
+		}
+	}
+}
+
+// Audio decodificado do MP3 (PCM de 16 bits, estéreo).
+func decodificaMP3(t *testing.T, conteudo []byte) ([]byte, int) {
+	t.Helper()
+	decoder, err := mp3.NewDecoder(bytes.NewReader(conteudo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcm, err := io.ReadAll(decoder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pcm, decoder.SampleRate()
+}
+
+// O WAV tem o cabeçalho com a taxa do primeiro audio seguido do audio decodificado de todos;
+// os audios com outra taxa de amostragem são reamostrados para a do primeiro.
+func TestGravaWAV(t *testing.T) {
+	configuraTeste(t)
+
+	// Mesmo silêncio de mp3Silencioso, mas a 48000 Hz (quadros de 384 bytes).
+	quadro48k := make([]byte, 384)
+	copy(quadro48k, []byte{0xFF, 0xFB, 0x94, 0xC4})
+	mp348k := bytes.Repeat(quadro48k, 6)
+
+	primeiro, segundo := mp3Silencioso(4), mp3Silencioso(7)
+	pcm1, taxa := decodificaMP3(t, primeiro)
+	pcm2, _ := decodificaMP3(t, segundo)
+	pcm3, taxa3 := decodificaMP3(t, mp348k)
+	if taxa != 44100 || taxa3 != 48000 {
+		t.Fatalf("taxas dos audios de teste = %d e %d", taxa, taxa3)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := gravaWAV(buf, audiosTeste(t, primeiro, segundo, mp348k)); err != nil {
+		t.Fatal(err)
+	}
+	wav := buf.Bytes()
+
+	c := leCabecalhoWAV(t, wav)
+	if c.Taxa != 44100 || c.Canais != 2 || c.BytesPorSeg != 44100*4 || c.Alinhamento != 4 {
+		t.Errorf("cabeçalho = %+v, esperado 44100 Hz estéreo", c)
+	}
+	if int(c.TamanhoRiff) != len(wav)-8 || int(c.TamanhoData) != len(wav)-44 {
+		t.Errorf("tamanhos RIFF %d e data %d, arquivo com %d bytes", c.TamanhoRiff, c.TamanhoData, len(wav))
+	}
+
+	// O terceiro audio é reamostrado de 48000 para 44100 Hz.
+	quadros3 := int(math.Ceil(float64(len(pcm3)/4) / (48000.0 / 44100)))
+	if esperado := len(pcm1) + len(pcm2) + quadros3*4; int(c.TamanhoData) != esperado {
+		t.Errorf("bloco data com %d bytes, esperado %d", c.TamanhoData, esperado)
+	}
+	if !bytes.Equal(wav[44:44+len(pcm1)+len(pcm2)], append(pcm1, pcm2...)) {
+		t.Error("o audio gravado difere do audio decodificado")
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// Valor do parâmetro IDIOMA que ativa a detecção do idioma: a IA é instruída a responder
+	// no idioma da pergunta e cada trecho da narração é falado no idioma do próprio trecho.
+	IDIOMA_AUTOMATICO = "auto"
+
+	// Idiomas detectáveis no modo automático.
+	IDIOMA_PORTUGUES = "pt-BR"
+	IDIOMA_INGLES    = "en-US"
+)
+
+var (
+	// Palavras frequentes de cada idioma detectável. As palavras comuns aos dois idiomas
+	// (ex: "a", "no", "do", "as") não são consideradas, pois não os diferenciam.
+	palavrasDoIdioma = map[string][]string{
+		IDIOMA_PORTUGUES: strings.Fields(`o os e é um uma uns umas de da das dos em na nas nos num numa que para pra por
+			com não se mais como mas ao aos foi ser está estão são era qual quais quem onde quando porque
+			isso isto este esta esse essa aquele aquela eu você vocês ele ela nós eles elas meu minha seu sua
+			tem têm ou muito muita também já sobre pelo pela pelos pelas entre até há olá oi obrigado obrigada
+			bom boa dia sim ainda depois antes então fazer faz pode posso quero qualquer`),
+		IDIOMA_INGLES: strings.Fields(`the and is are was were of to in that it its with on what which who whom
+			where when why how this these those be been by from at an or not you your yours i we they he she
+			my our their can could will would should does did have has had there about please hello hi thanks
+			thank good morning yes still after before then make makes may want any if than`),
+	}
+
+	// Idioma de cada palavra frequente, montado a partir de palavrasDoIdioma.
+	idiomaDaPalavra = indexaPalavras(palavrasDoIdioma)
+)
+
+func indexaPalavras(palavras map[string][]string) map[string]string {
+	indice := make(map[string]string)
+	for idioma, lista := range palavras {
+		for _, p := range lista {
+			indice[p] = idioma
+		}
+	}
+	return indice
+}
+
+// Indica se o idioma configurado é o modo automático (parâmetro IDIOMA ou --lang "auto").
+func idiomaAutomatico(idioma string) bool {
+	return strings.EqualFold(strings.TrimSpace(idioma), IDIOMA_AUTOMATICO)
+}
+
+// Detecta o idioma do texto (português ou inglês) pela contagem das palavras frequentes de cada um.
+// Os caracteres acentuados e o "ç", que não existem em inglês, também contam para o português.
+// Retorna vazio se não for possível decidir (ex: texto curto, só com números ou nomes próprios).
+func detectaIdioma(texto string) string {
+	pontos := make(map[string]int)
+	for _, palavra := range strings.FieldsFunc(strings.ToLower(texto), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		if idioma, existe := idiomaDaPalavra[palavra]; existe {
+			pontos[idioma]++
+		} else if strings.ContainsAny(palavra, "áàâãéêíóôõúç") {
+			pontos[IDIOMA_PORTUGUES]++
+		}
+	}
+
+	escolhido, maior, empate := "", 0, false
+	for idioma, p := range pontos {
+		switch {
+		case p > maior:
+			escolhido, maior, empate = idioma, p, false
+		case p == maior:
+			empate = true
+		}
+	}
+	if empate {
+		return ""
+	}
+	return escolhido
+}
+
+// Idioma em que a IA deve responder à pergunta. No modo automático, é o idioma detectado
+// na pergunta; se não for possível detectá-lo, a pergunta é enviada sem a instrução de idioma
+// e a IA responde, naturalmente, no idioma em que foi perguntada.
+func idiomaDaPergunta(pergunta, configurado string) string {
+	if idiomaAutomatico(configurado) {
+		return detectaIdioma(pergunta)
+	}
+	return configurado
+}
+
+// Idioma do texto a ser narrado. No modo automático, é o idioma detectado no texto
+// ou, se não for possível detectá-lo, o idioma padrão (veja idiomaPadrao).
+func idiomaDoTexto(texto, configurado string) string {
+	if !idiomaAutomatico(configurado) {
+		return configurado
+	}
+	if idioma := detectaIdioma(texto); idioma != "" {
+		return idioma
+	}
+	return idiomaPadrao(configurado)
+}
+
+// Idioma da narração quando não há um texto para detectá-lo: no modo automático,
+// é o idioma da interface; nos demais casos, o próprio idioma configurado.
+func idiomaPadrao(configurado string) string {
+	if idiomaAutomatico(configurado) {
+		return idiomaInterface()
+	}
+	return configurado
+}
+
+// Idioma de cada bloco da narração. Fora do modo automático, todos usam o idioma configurado.
+// No modo automático, cada bloco usa o idioma detectado no mesmo; os blocos em que não foi
+// possível detectá-lo (ex: "Sim.", "1, 2, 3") seguem o idioma do bloco anterior, ou do primeiro
+// bloco detectado, ou, se nenhum foi detectado, o idioma da interface.
+func idiomasDosBlocos(blocos []string, configurado string) []string {
+	idiomas := make([]string, len(blocos))
+	if !idiomaAutomatico(configurado) {
+		for i := range idiomas {
+			idiomas[i] = configurado
+		}
+		return idiomas
+	}
+
+	anterior := ""
+	for i, b := range blocos {
+		idiomas[i] = detectaIdioma(b)
+		if anterior == "" {
+			anterior = idiomas[i]
+		}
+	}
+	if anterior == "" {
+		anterior = idiomaPadrao(configurado)
+	}
+	for i := range idiomas {
+		if idiomas[i] == "" {
+			idiomas[i] = anterior
+		}
+		anterior = idiomas[i]
+	}
+	return idiomas
+}
@@ -0,0 +1,132 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectaIdioma(t *testing.T) {
+	tests := []struct {
+		nome   string
+		texto  string
+		idioma string
+	}{
+		{"pergunta em português", "Qual é a capital do Brasil?", "pt-BR"},
+		{"pergunta em inglês", "What is the capital of Brazil?", "en-US"},
+		{"acentos sem palavras frequentes", "Programação orientação", "pt-BR"},
+		{"nome com acento em frase em inglês", "The capital of Brazil is Brasília.", "en-US"},
+		{"maiúsculas", "WHERE IS THE BOOK?", "en-US"},
+		{"contração em inglês", "I don't know what it is", "en-US"},
+		{"empate", "the e", ""},
+		{"sem palavras conhecidas", "Brasil 2023", ""},
+		{"vazio", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nome, func(t *testing.T) {
+			if idioma := detectaIdioma(tt.texto); idioma != tt.idioma {
+				t.Errorf("detectaIdioma(%q) = %q, esperado %q", tt.texto, idioma, tt.idioma)
+			}
+		})
+	}
+}
+
+func TestIdiomasDosBlocos(t *testing.T) {
+	configuraTeste(t)
+
+	tests := []struct {
+		nome        string
+		blocos      []string
+		configurado string
+		idiomas     []string
+	}{
+		{"idioma fixo", []string{"Hello, how are you?", "Tudo bem?"}, "pt-br", []string{"pt-br", "pt-br"}},
+		{"cada bloco no seu idioma", []string{"This is the answer.", "E esta é a tradução."}, "auto", []string{"en-US", "pt-BR"}},
+		{"bloco sem idioma segue o anterior", []string{"This is the answer.", "OK.", "Esta é outra."}, "AUTO", []string{"en-US", "en-US", "pt-BR"}},
+		{"bloco inicial sem idioma segue o primeiro detectado", []string{"1, 2, 3.", "These are numbers."}, "auto", []string{"en-US", "en-US"}},
+		{"nenhum detectado usa o idioma da interface", []string{"1, 2, 3."}, "auto", []string{IDIOMA_INTERFACE_PADRAO}},
+		{"sem blocos", []string{}, "auto", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nome, func(t *testing.T) {
+			if idiomas := idiomasDosBlocos(tt.blocos, tt.configurado); !reflect.DeepEqual(idiomas, tt.idiomas) {
+				t.Errorf("idiomasDosBlocos(%q, %q) = %q, esperado %q", tt.blocos, tt.configurado, idiomas, tt.idiomas)
+			}
+		})
+	}
+}
+
+// No modo automático, a IA é instruída a responder no idioma de cada pergunta e cada trecho
+// da resposta é narrado no idioma do próprio trecho.
+func TestIdiomaAutomatico(t *testing.T) {
+	f, sessao := novoServidorFake(t)
+	f.Resposta = "The capital of Brazil is Brasília, a city planned in the fifties. " +
+		"Em português: a capital do Brasil é Brasília, uma cidade planejada."
+	f.Audio = mp3Silencioso(4)
+	sessao.settings.IDIOMA = IDIOMA_AUTOMATICO
+	sessao.settings.TTS = true
+	sessao.noSleep = true
+
+	for _, tt := range []struct {
+		pergunta  string
+		instrucao string
+	}{
+		{"What is the capital of Brazil?", ` (You must answer in "en-US")`},
+		{"Qual é a capital do Brasil?", ` (You must answer in "pt-BR")`},
+		{"OK", ""},
+	} {
+		capturaSaida(t, sessao, func() {
+			if !sessao.respondePergunta(tt.pergunta) {
+				t.Fatalf("sem resposta para %q", tt.pergunta)
+			}
+			sessao.narracao.Aguarda()
+		})
+
+		requisicoes := f.Requisicoes()
+		mensagens := requisicoes[len(requisicoes)-1].Messages
+		if conteudo := mensagens[len(mensagens)-1].Content; conteudo != tt.pergunta+tt.instrucao {
+			t.Errorf("pergunta enviada = %q, esperado %q", conteudo, tt.pergunta+tt.instrucao)
+		}
+	}
+
+	idiomas := make(map[string]string)
+	for _, c := range f.Consultas() {
+		idiomas[c.Get("q")] = c.Get("tl")
+	}
+	if len(idiomas) != 2 {
+		t.Fatalf("trechos narrados = %v, esperado 2", idiomas)
+	}
+	for texto, idioma := range idiomas {
+		esperado := "en-US"
+		if strings.HasPrefix(texto, "Em português") {
+			esperado = "pt-BR"
+		}
+		if !strings.EqualFold(idioma, esperado) {
+			t.Errorf("trecho %q narrado em %q, esperado %q", texto, idioma, esperado)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	registraComando(&Comando{
+		Nome:       "import",
+		Argumentos: "<arquivo>",
+		Descricao:  "Acrescenta ao histórico a conversa gravada no arquivo (JSON ou Markdown).",
+		MinArgs:    1,
+		MaxArgs:    1,
+		Executa:    (*Sessao).trataComandoImport,
+	})
+}
+
+// Trata o comando "/import <arquivo>" digitado no modo interativo.
+func (sessao *Sessao) trataComandoImport(args []string) string {
+	n, err := sessao.importaHistorico(args[0])
+	if err != nil {
+		sessao.tela.Erro(err)
+		return ""
+	}
+	sessao.tela.Printf(traduz("%d mensagens importadas de \"%s\""), n, args[0])
+	return ""
+}
+
+// Carrega as mensagens do arquivo e acrescenta ao histórico da conversa atual.
+// Retorna a quantidade de mensagens importadas.
+func (sessao *Sessao) importaHistorico(caminho string) (int, error) {
+	importadas, err := leConversa(caminho)
+	if err != nil {
+		return 0, err
+	}
+
+	// A validação considera o histórico atual, pois as mensagens importadas são acrescentadas a ele.
+	historico := append(sessao.conversa.Mensagens(), importadas...)
+	if err := validaAlternancia(historico); err != nil {
+		return 0, novoErro("histórico \"%s\" inválido: %w", caminho, err)
+	}
+
+	sessao.conversa.Adiciona(importadas...)
+	return len(importadas), nil
+}
+
+// Lê uma conversa gravada em JSON (array de mensagens ou arquivo gerado pelo comando "export")
+// ou em Markdown (transcrição gerada pelo comando "export").
+func leConversa(caminho string) ([]Message, error) {
+	conteudo, err := os.ReadFile(caminho)
+	if err != nil {
+		return nil, err
+	}
+
+	conteudo = bytes.TrimSpace(conteudo)
+	if len(conteudo) == 0 {
+		return nil, novoErro("arquivo \"%s\" vazio", caminho)
+	}
+
+	// Arquivos .json ou cujo conteúdo começa com "[" ou "{" são tratados como JSON.
+	if strings.EqualFold(filepath.Ext(caminho), ".json") || conteudo[0] == '[' || conteudo[0] == '{' {
+		return leConversaJSON(conteudo)
+	}
+	return leConversaMarkdown(conteudo)
+}
+
+// Aceita tanto um array de mensagens (role/content) quanto o objeto gravado por "export json".
+// Neste último caso, os detalhes de cada turno (horário, modelo e tokens) também são restaurados.
+func leConversaJSON(conteudo []byte) ([]Message, error) {
+	if conteudo[0] == '[' {
+		msgs := make([]Message, 0)
+		if err := json.Unmarshal(conteudo, &msgs); err != nil {
+			return nil, err
+		}
+		return msgs, nil
+	}
+
+	conversa := ConversaExportada{}
+	if err := json.Unmarshal(conteudo, &conversa); err != nil {
+		return nil, err
+	}
+
+	for _, t := range conversa.Turns {
+		if t.Index < 0 || t.Index >= len(conversa.Messages) {
+			continue
+		}
+		conversa.Messages[t.Index].Horario = t.Timestamp
+		conversa.Messages[t.Index].Modelo = t.Model
+		conversa.Messages[t.Index].Uso = t.Usage
+	}
+	return conversa.Messages, nil
+}
+
+// Lê a transcrição em Markdown. Cada mensagem começa com um título de nível 2 ("## ")
+// indicando o papel: "Pergunta"/"user", "Resposta"/"assistant" ou "Sistema"/"system",
+// ou a tradução do título em algum idioma da interface (ex: "Question").
+// Os detalhes entre parênteses no título (horário, modelo, tokens) são ignorados.
+func leConversaMarkdown(conteudo []byte) ([]Message, error) {
+	msgs := make([]Message, 0)
+	linhas := make([]string, 0)
+
+	// Grava o texto acumulado na última mensagem encontrada.
+	fechaMensagem := func() {
+		if len(msgs) > 0 {
+			msgs[len(msgs)-1].Content = strings.TrimSpace(strings.Join(linhas, "\n"))
+		}
+		linhas = linhas[:0]
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(conteudo))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(conteudo)+1)
+	for scanner.Scan() {
+		linha := scanner.Text()
+
+		if strings.HasPrefix(linha, "## ") {
+			if role := roleDoTitulo(linha[3:]); role != "" {
+				fechaMensagem()
+				msgs = append(msgs, Message{Role: role})
+				continue
+			}
+		}
+
+		// O texto anterior ao primeiro título (cabeçalho do arquivo) é descartado.
+		if len(msgs) > 0 {
+			linhas = append(linhas, linha)
+		}
+	}
+	fechaMensagem()
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, novoErro("nenhuma mensagem encontrada. Use títulos \"## Pergunta\" e \"## Resposta\"")
+	}
+	return msgs, nil
+}
+
+// Converte o título do turno na transcrição para o papel (role) da mensagem. Aceita os títulos
+// de todos os idiomas da interface, pois a transcrição pode ter sido exportada em outro idioma.
+func roleDoTitulo(titulo string) string {
+	if n := strings.Index(titulo, "("); n >= 0 {
+		titulo = titulo[:n]
+	}
+	titulo = strings.TrimSpace(titulo)
+
+	for _, p := range []struct{ role, titulo string }{
+		{"user", "Pergunta"},
+		{"assistant", "Resposta"},
+		{"system", "Sistema"},
+	} {
+		if strings.EqualFold(titulo, p.role) || strings.EqualFold(titulo, p.titulo) {
+			return p.role
+		}
+		for _, catalogo := range catalogos {
+			if traducao, existe := catalogo[p.titulo]; existe && strings.EqualFold(titulo, traducao) {
+				return p.role
+			}
+		}
+	}
+	return ""
+}
+
+// Verifica se as mensagens estão na ordem esperada pela API: mensagens "system" opcionais
+// no início, seguidas de "user" e "assistant" alternadamente, terminando com "assistant"
+// (a próxima pergunta digitada continua a alternância).
+func validaAlternancia(msgs []Message) error {
+	esperado := "user"
+	inicio := true
+
+	for i, m := range msgs {
+		if strings.TrimSpace(m.Content) == "" {
+			return novoErro("mensagem %d sem conteúdo", i+1)
+		}
+
+		if m.Role == "system" && inicio {
+			continue
+		}
+		inicio = false
+
+		if m.Role != esperado {
+			return novoErro("mensagem %d tem role \"%s\", mas era esperado \"%s\"", i+1, m.Role, esperado)
+		}
+
+		if esperado == "user" {
+			esperado = "assistant"
+		} else {
+			esperado = "user"
+		}
+	}
+
+	if esperado != "user" {
+		return novoErro("a última mensagem deve ser uma resposta (\"assistant\")")
+	}
+	return nil
+}
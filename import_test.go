@@ -0,0 +1,196 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gpt-falador/falador"
+)
+
+func TestValidaAlternancia(t *testing.T) {
+	casos := []struct {
+		nome string
+		msgs []Message
+		erro string // Trecho esperado na mensagem de erro; vazio se as mensagens são válidas.
+	}{
+		{"vazio", nil, ""},
+		{"pergunta e resposta", []Message{{Role: "user", Content: "Oi"}, {Role: "assistant", Content: "Olá"}}, ""},
+		{"sistema no início", []Message{{Role: "system", Content: "Traduza"}, {Role: "system", Content: "Seja breve"}, {Role: "user", Content: "Oi"}, {Role: "assistant", Content: "Olá"}}, ""},
+		{"começa com resposta", []Message{{Role: "assistant", Content: "Olá"}, {Role: "user", Content: "Oi"}}, "mensagem 1 tem role \"assistant\""},
+		{"duas perguntas seguidas", []Message{{Role: "user", Content: "Oi"}, {Role: "user", Content: "Tudo bem?"}, {Role: "assistant", Content: "Olá"}}, "mensagem 2 tem role \"user\""},
+		{"sistema no meio", []Message{{Role: "user", Content: "Oi"}, {Role: "system", Content: "Traduza"}, {Role: "assistant", Content: "Olá"}}, "mensagem 2 tem role \"system\""},
+		{"role desconhecido", []Message{{Role: "user", Content: "Oi"}, {Role: "bot", Content: "Olá"}}, "mensagem 2 tem role \"bot\""},
+		{"termina com pergunta", []Message{{Role: "user", Content: "Oi"}, {Role: "assistant", Content: "Olá"}, {Role: "user", Content: "E aí?"}}, "a última mensagem deve ser uma resposta"},
+		{"só sistema", []Message{{Role: "system", Content: "Traduza"}}, ""},
+		{"sem conteúdo", []Message{{Role: "user", Content: "Oi"}, {Role: "assistant", Content: " \n"}}, "mensagem 2 sem conteúdo"},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			err := validaAlternancia(c.msgs)
+			if c.erro == "" {
+				if err != nil {
+					t.Errorf("erro inesperado: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.erro) {
+				t.Errorf("erro = %v, esperado contendo %q", err, c.erro)
+			}
+		})
+	}
+}
+
+func TestLeConversaMarkdown(t *testing.T) {
+	casos := []struct {
+		nome     string
+		conteudo string
+		msgs     []Message
+		erro     bool
+	}{
+		{
+			nome:     "títulos em português com detalhes",
+			conteudo: "# GPT-Falador - Conversa\n\nExportada em 2024-01-02 10:00:00\n\n## Pergunta (2024-01-02 09:59:00)\n\nOi\n\n## Resposta (2024-01-02 09:59:01 · gpt-4 · 1+2=3 tokens)\n\nOlá!\n",
+			msgs:     []Message{{Role: "user", Content: "Oi"}, {Role: "assistant", Content: "Olá!"}},
+		},
+		{
+			nome:     "títulos pelo role, sem cabeçalho",
+			conteudo: "## System\nTraduza\n## USER\nOi\n## assistant\nOlá",
+			msgs:     []Message{{Role: "system", Content: "Traduza"}, {Role: "user", Content: "Oi"}, {Role: "assistant", Content: "Olá"}},
+		},
+		{
+			nome:     "títulos traduzidos",
+			conteudo: "## Question\nHi\n## answer (2024-01-02 09:59:01 · gpt-4)\nHello",
+			msgs:     []Message{{Role: "user", Content: "Hi"}, {Role: "assistant", Content: "Hello"}},
+		},
+		{
+			nome:     "texto com várias linhas e subtítulos",
+			conteudo: "## Pergunta\n\nListe dois itens\n\n## Resposta\n\n### Itens\n\n- um\n- dois\n\n## Outro título\n\nfim",
+			msgs:     []Message{{Role: "user", Content: "Liste dois itens"}, {Role: "assistant", Content: "### Itens\n\n- um\n- dois\n\n## Outro título\n\nfim"}},
+		},
+		{
+			nome:     "título sem espaço após ##",
+			conteudo: "##Pergunta\nOi\n## Resposta\nOlá",
+			msgs:     []Message{{Role: "assistant", Content: "Olá"}},
+		},
+		{
+			nome:     "título de nível 1 não inicia mensagem",
+			conteudo: "# Pergunta\nOi\n## Resposta\nOlá",
+			msgs:     []Message{{Role: "assistant", Content: "Olá"}},
+		},
+		{
+			nome:     "título vazio",
+			conteudo: "## \nOi\n## (2024-01-02)\nOlá",
+			erro:     true,
+		},
+		{
+			nome:     "sem títulos",
+			conteudo: "Oi\nOlá",
+			erro:     true,
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			msgs, err := leConversaMarkdown([]byte(c.conteudo))
+			if c.erro {
+				if err == nil {
+					t.Errorf("esperado erro, obtido %+v", msgs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if !reflect.DeepEqual(msgs, c.msgs) {
+				t.Errorf("mensagens = %+v, esperado %+v", msgs, c.msgs)
+			}
+		})
+	}
+}
+
+// Mensagens usadas nos testes de ida e volta da exportação.
+func conversaExportavel() []Message {
+	horario := time.Date(2024, 1, 2, 9, 59, 0, 0, time.UTC)
+	return []Message{
+		{Role: "system", Content: "Traduza para o inglês"},
+		{Role: "user", Content: falador.InstrucaoIdioma("Qual é a capital do Brasil?", "pt-BR"), Horario: horario},
+		{Role: "assistant", Content: "Brasília.\n\n## Curiosidade\n\nFoi inaugurada em 1960.", Horario: horario.Add(time.Second), Modelo: "gpt-teste", Uso: &Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}},
+		{Role: "user", Content: "E a da Argentina?", Horario: horario.Add(time.Minute)},
+		{Role: "assistant", Content: "Buenos Aires.", Horario: horario.Add(time.Minute + time.Second), Modelo: "gpt-teste"},
+	}
+}
+
+// A transcrição em Markdown preserva o papel e o texto de cada mensagem; os detalhes do título
+// (horário, modelo e tokens) e a instrução de idioma das perguntas não são restaurados.
+func TestExportaMarkdownImporta(t *testing.T) {
+	originais := conversaExportavel()
+
+	buf := &bytes.Buffer{}
+	exportaMarkdown(buf, originais)
+	msgs, err := leConversaMarkdown(buf.Bytes())
+	if err != nil {
+		t.Fatalf("erro inesperado: %v\n%s", err, buf)
+	}
+
+	esperadas := make([]Message, 0, len(originais))
+	for _, m := range originais {
+		esperadas = append(esperadas, Message{Role: m.Role, Content: textoDaMensagem(m)})
+	}
+	if !reflect.DeepEqual(msgs, esperadas) {
+		t.Errorf("mensagens = %+v, esperado %+v", msgs, esperadas)
+	}
+	if err := validaAlternancia(msgs); err != nil {
+		t.Errorf("conversa importada inválida: %v", err)
+	}
+}
+
+// A exportação em JSON restaura as mensagens completas, inclusive os detalhes de cada turno.
+func TestExportaJSONImporta(t *testing.T) {
+	sessao := configuraTeste(t)
+	originais := conversaExportavel()
+
+	buf := &bytes.Buffer{}
+	if err := sessao.exportaJSON(buf, originais); err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := leConversaJSON(bytes.TrimSpace(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v\n%s", err, buf)
+	}
+
+	if len(msgs) != len(originais) {
+		t.Fatalf("%d mensagens importadas, esperado %d", len(msgs), len(originais))
+	}
+	for i, m := range msgs {
+		o := originais[i]
+		if m.Role != o.Role || m.Content != o.Content || !m.Horario.Equal(o.Horario) || m.Modelo != o.Modelo || !reflect.DeepEqual(m.Uso, o.Uso) {
+			t.Errorf("mensagem %d = %+v, esperado %+v", i+1, m, o)
+		}
+	}
+}
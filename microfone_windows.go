@@ -0,0 +1,185 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// Formato da gravação: 16 kHz, mono, 16 bits, o formato usado pelo Whisper.
+	TAXA_GRAVACAO = 16000
+
+	// Duração máxima da gravação, para não gravar indefinidamente se o usuário esquecer o microfone ligado.
+	DURACAO_MAXIMA_GRAVACAO = 2 * time.Minute
+
+	// Quantidade e tamanho (1/4 de segundo) dos buffers entregues ao driver de audio.
+	BUFFERS_GRAVACAO        = 4
+	TAMANHO_BUFFER_GRAVACAO = TAXA_GRAVACAO * 2 / 4
+
+	TECLA_ENTER = 0x0D
+
+	WAVE_MAPPER     = 0xFFFFFFFF // Dispositivo de gravação padrão do Windows.
+	WAVE_FORMAT_PCM = 1
+	CALLBACK_NULL   = 0
+	WHDR_DONE       = 1
+)
+
+type (
+	// Estrutura WAVEFORMATEX da API winmm.
+	waveFormatEx struct {
+		FormatTag      uint16
+		Channels       uint16
+		SamplesPerSec  uint32
+		AvgBytesPerSec uint32
+		BlockAlign     uint16
+		BitsPerSample  uint16
+		Size           uint16
+	}
+
+	// Estrutura WAVEHDR da API winmm.
+	waveHdr struct {
+		Data          uintptr
+		BufferLength  uint32
+		BytesRecorded uint32
+		User          uintptr
+		Flags         uint32
+		Loops         uint32
+		Next          uintptr
+		Reserved      uintptr
+	}
+)
+
+var (
+	// Funções da API winmm.dll do Windows usadas para gravar o audio do microfone.
+	winmm_dll             = windows.NewLazyDLL("winmm.dll")
+	waveInOpen            = winmm_dll.NewProc("waveInOpen")
+	waveInPrepareHeader   = winmm_dll.NewProc("waveInPrepareHeader")
+	waveInUnprepareHeader = winmm_dll.NewProc("waveInUnprepareHeader")
+	waveInAddBuffer       = winmm_dll.NewProc("waveInAddBuffer")
+	waveInStart           = winmm_dll.NewProc("waveInStart")
+	waveInReset           = winmm_dll.NewProc("waveInReset")
+	waveInClose           = winmm_dll.NewProc("waveInClose")
+
+	// Para descartar as teclas pressionadas durante a gravação, que iriam para a próxima pergunta.
+	kernel32_dll            = windows.NewLazyDLL("kernel32.dll")
+	FlushConsoleInputBuffer = kernel32_dll.NewProc("FlushConsoleInputBuffer")
+)
+
+// Grava o audio do microfone até o usuário pressionar ENTER (ou ESC, para cancelar).
+// Retorna o audio gravado no formato WAV.
+func gravaMicrofone() ([]byte, error) {
+	formato := waveFormatEx{
+		FormatTag:      WAVE_FORMAT_PCM,
+		Channels:       1,
+		SamplesPerSec:  TAXA_GRAVACAO,
+		AvgBytesPerSec: TAXA_GRAVACAO * 2,
+		BlockAlign:     2,
+		BitsPerSample:  16,
+	}
+
+	var dispositivo uintptr
+	if r, _, _ := waveInOpen.Call(uintptr(unsafe.Pointer(&dispositivo)), WAVE_MAPPER, uintptr(unsafe.Pointer(&formato)), 0, 0, CALLBACK_NULL); r != 0 {
+		return nil, novoErro("não foi possível abrir o microfone (erro %d)", r)
+	}
+	defer waveInClose.Call(dispositivo)
+
+	// Os buffers são preenchidos pelo driver na ordem em que foram entregues.
+	// Cada buffer preenchido (WHDR_DONE) é copiado e devolvido ao driver.
+	buffers := make([][]byte, BUFFERS_GRAVACAO)
+	cabecalhos := make([]waveHdr, BUFFERS_GRAVACAO)
+	tamanhoHdr := unsafe.Sizeof(waveHdr{})
+	for i := range cabecalhos {
+		buffers[i] = make([]byte, TAMANHO_BUFFER_GRAVACAO)
+		cabecalhos[i] = waveHdr{Data: uintptr(unsafe.Pointer(&buffers[i][0])), BufferLength: TAMANHO_BUFFER_GRAVACAO}
+		waveInPrepareHeader.Call(dispositivo, uintptr(unsafe.Pointer(&cabecalhos[i])), tamanhoHdr)
+		waveInAddBuffer.Call(dispositivo, uintptr(unsafe.Pointer(&cabecalhos[i])), tamanhoHdr)
+	}
+	defer func() {
+		for i := range cabecalhos {
+			waveInUnprepareHeader.Call(dispositivo, uintptr(unsafe.Pointer(&cabecalhos[i])), tamanhoHdr)
+		}
+	}()
+
+	if r, _, _ := waveInStart.Call(dispositivo); r != 0 {
+		return nil, novoErro("não foi possível iniciar a gravação (erro %d)", r)
+	}
+
+	pcm := &bytes.Buffer{}
+	proximo := 0
+	coletaBuffers := func(devolve bool) {
+		for i := 0; i < BUFFERS_GRAVACAO && atomic.LoadUint32(&cabecalhos[proximo].Flags)&WHDR_DONE != 0; i++ {
+			h := &cabecalhos[proximo]
+			pcm.Write(buffers[proximo][:h.BytesRecorded])
+			h.Flags &^= WHDR_DONE
+			if devolve {
+				h.BytesRecorded = 0
+				waveInAddBuffer.Call(dispositivo, uintptr(unsafe.Pointer(h)), tamanhoHdr)
+			}
+			proximo = (proximo + 1) % BUFFERS_GRAVACAO
+		}
+	}
+
+	// As teclas já pressionadas ao iniciar (ex: o ENTER do comando /listen) só valem após serem soltas.
+	enter, esc := teclaAbaixada(TECLA_ENTER), teclaAbaixada(TECLA_ESC)
+	inicio := time.Now()
+	cancelou := false
+	for time.Since(inicio) < DURACAO_MAXIMA_GRAVACAO {
+		time.Sleep(time.Millisecond * 10)
+		coletaBuffers(true)
+
+		e := teclaAbaixada(TECLA_ESC)
+		if e && !esc {
+			cancelou = true
+			break
+		}
+		esc = e
+
+		e = teclaAbaixada(TECLA_ENTER)
+		if e && !enter {
+			break
+		}
+		enter = e
+	}
+
+	// Encerra a gravação: os buffers pendentes são marcados como preenchidos (com o que foi gravado).
+	waveInReset.Call(dispositivo)
+	coletaBuffers(false)
+	FlushConsoleInputBuffer.Call(uintptr(windows.Stdin))
+
+	if cancelou {
+		return nil, errGravacaoCancelada
+	}
+
+	wav := &bytes.Buffer{}
+	if err := cabecalhoWAV(wav, TAXA_GRAVACAO, 1, pcm.Len()); err != nil {
+		return nil, err
+	}
+	wav.Write(pcm.Bytes())
+	return wav.Bytes(), nil
+}
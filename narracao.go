@@ -0,0 +1,157 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Progresso da narração da resposta, usado para imprimir o texto na tela no mesmo ritmo
+	// em que é narrado. O acesso aos campos é protegido pela mutex, pois o progresso é
+	// atualizado pela goroutine que executa os audios e lido pela que imprime a resposta.
+	ProgressoNarracao struct {
+		mutex   sync.Mutex
+		pesos   []int   // Quantidade de caracteres de cada bloco narrado.
+		total   int     // Soma dos pesos.
+		posicao float64 // Fração (de 0 a 1) do texto já narrado.
+		ativa   bool    // Se true, a impressão acompanha a narração.
+		tocou   bool    // Se true, algum audio chegou a ser executado.
+		emCurso bool    // Se true, a narração ainda não terminou.
+
+		fim chan struct{} // Fechado quando a narração termina.
+	}
+)
+
+// Inicia o acompanhamento da narração dos blocos informados. Cada bloco "pesa" a quantidade
+// de caracteres que tem, de modo que a posição na resposta é proporcional ao texto narrado.
+func (n *ProgressoNarracao) Inicia(blocos []string) {
+	defer n.mutex.Unlock()
+	n.mutex.Lock()
+
+	n.pesos = make([]int, len(blocos))
+	n.total = 0
+	for i, b := range blocos {
+		n.pesos[i] = tamanho(b)
+		n.total += n.pesos[i]
+	}
+	n.posicao = 0
+	n.ativa = n.total > 0
+	n.tocou = false
+	n.emCurso = true
+	n.fim = make(chan struct{})
+}
+
+// Atualiza a posição da narração: o bloco em execução e a fração (de 0 a 1) já executada do mesmo.
+func (n *ProgressoNarracao) Avanca(bloco int, fracao float64) {
+	defer n.mutex.Unlock()
+	n.mutex.Lock()
+
+	if bloco < 0 || bloco >= len(n.pesos) || n.total == 0 {
+		return
+	}
+	if fracao > 0 {
+		n.tocou = true
+	}
+	if fracao > 1 {
+		fracao = 1
+	}
+
+	narrado := 0
+	for _, p := range n.pesos[:bloco] {
+		narrado += p
+	}
+
+	// A posição nunca volta atrás (ex: bloco seguinte com erro de download).
+	if posicao := (float64(narrado) + fracao*float64(n.pesos[bloco])) / float64(n.total); posicao > n.posicao {
+		n.posicao = posicao
+	}
+}
+
+// Indica o fim da narração. Se algum audio foi executado, o restante da resposta é impresso
+// de imediato. Caso contrário (ex: sem conexão com o TTS), a impressão deixa de acompanhar
+// a narração e volta a usar o parâmetro MAX_DELAY.
+func (n *ProgressoNarracao) Termina() {
+	defer n.mutex.Unlock()
+	n.mutex.Lock()
+
+	if !n.emCurso {
+		return
+	}
+	if n.tocou {
+		n.posicao = 1
+	} else {
+		n.ativa = false
+	}
+	n.emCurso = false
+	close(n.fim)
+}
+
+// Indica se a narração ainda está em andamento.
+func (n *ProgressoNarracao) EmAndamento() bool {
+	defer n.mutex.Unlock()
+	n.mutex.Lock()
+	return n.emCurso
+}
+
+// Aguarda o fim da narração em andamento. Se não houver narração, retorna imediatamente.
+func (n *ProgressoNarracao) Aguarda() {
+	n.mutex.Lock()
+	fim := n.fim
+	n.mutex.Unlock()
+
+	if fim != nil {
+		<-fim
+	}
+}
+
+// Indica se a impressão da resposta deve acompanhar a narração.
+func (n *ProgressoNarracao) Ativa() bool {
+	defer n.mutex.Unlock()
+	n.mutex.Lock()
+	return n.ativa
+}
+
+// Retorna a fração (de 0 a 1) do texto já narrado.
+func (n *ProgressoNarracao) Posicao() float64 {
+	defer n.mutex.Unlock()
+	n.mutex.Lock()
+	return n.posicao
+}
+
+// Aguarda a narração alcançar a posição informada (fração de 0 a 1 da resposta).
+// Retorna antes disso se o usuário pressionar ESC ou ESPAÇO, informando a tecla pressionada,
+// ou se a narração deixar de ser acompanhada. Retorna 0 se alcançou a posição.
+func (sessao *Sessao) aguardaNarracao(posicao float64) int32 {
+	for sessao.narracao.Ativa() && sessao.narracao.Posicao() < posicao {
+		if teclaPressionada(TECLA_ESC) {
+			return TECLA_ESC
+		}
+		if teclaPressionada(TECLA_ESPACO) {
+			return TECLA_ESPACO
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return 0
+}
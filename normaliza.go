@@ -0,0 +1,440 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type (
+	// Palavras e expressões usadas na narração de cada idioma.
+	// Por enquanto, apenas português e inglês são normalizados; nos demais idiomas
+	// o texto é narrado como está (apenas a formatação é removida).
+	vocabulario struct {
+		codigo        string       // Frase narrada no lugar de um bloco de código.
+		codigoEm      string       // Idem, quando a linguagem do bloco é informada (%s).
+		link          string       // Palavra narrada antes do domínio de uma URL.
+		porCento      string       // Narrado no lugar do símbolo "%".
+		e             string       // Narrado no lugar do símbolo "&".
+		abreviacoes   []abreviacao // Abreviações e o respectivo texto por extenso.
+		separaMilhar  string       // Separador de milhares dos números.
+		separaDecimal string       // Separador decimal dos números.
+		decimal       string       // Palavra narrada no lugar do separador decimal.
+		porExtenso    func(n int64) string
+	}
+
+	// Abreviação a ser escrita por extenso. A expressão regular só encontra a abreviação
+	// como palavra inteira (ex: "ex." não é encontrado em "index.").
+	abreviacao struct {
+		re      *regexp.Regexp
+		extenso string
+	}
+)
+
+var (
+	// Bloco de código no formato Markdown: ```linguagem ... ```
+	blocoDeCodigo = regexp.MustCompile("(?s)```([^\\s`]*)[^\\n`]*\\n?(.*?)(```|$)")
+
+	linkMarkdown = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)\s]*)[^)]*\)`)
+	urlNoTexto   = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>()\[\]]+`)
+	titulo       = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+`)
+	citacao      = regexp.MustCompile(`(?m)^\s*>\s?`)
+	marcador     = regexp.MustCompile(`(?m)^\s*[-*+•]\s+`)
+	linhaTabela  = regexp.MustCompile(`(?m)^\s*\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)*\|?\s*$`)
+	separador    = regexp.MustCompile(`(?m)^\s*([-*_]\s*){3,}$`)
+	negrito      = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	italico      = regexp.MustCompile(`(^|[^\p{L}\p{N}*_])[*_]([^*_\n]+)[*_]([^\p{L}\p{N}*_]|$)`)
+	numero       = regexp.MustCompile(`\b\d+(?:[.,]\d+)*\b`)
+
+	vocabularioPortugues = &vocabulario{
+		codigo:   "Segue um trecho de código.",
+		codigoEm: "Segue um trecho de código em %s.",
+		link:     "link para",
+		porCento: " por cento",
+		e:        " e ",
+		abreviacoes: abreviacoes(
+			"p. ex.", "por exemplo", "ex.", "exemplo", "etc.", "etcétera", "sr.", "senhor", "sra.", "senhora",
+			"dr.", "doutor", "dra.", "doutora", "nº", "número", "pág.", "página", "aprox.", "aproximadamente",
+			"obs.", "observação", "vs.", "versus", "séc.", "século", "máx.", "máximo", "mín.", "mínimo",
+		),
+		separaMilhar:  ".",
+		separaDecimal: ",",
+		decimal:       "vírgula",
+		porExtenso:    porExtensoPortugues,
+	}
+
+	vocabularioIngles = &vocabulario{
+		codigo:   "Here is a code snippet.",
+		codigoEm: "Here is a code snippet in %s.",
+		link:     "link to",
+		porCento: " percent",
+		e:        " and ",
+		abreviacoes: abreviacoes(
+			"e.g.", "for example", "i.e.", "that is", "etc.", "et cetera", "mr.", "mister", "mrs.", "missus",
+			"dr.", "doctor", "vs.", "versus", "approx.", "approximately", "min.", "minimum", "max.", "maximum",
+		),
+		separaMilhar:  ",",
+		separaDecimal: ".",
+		decimal:       "point",
+		porExtenso:    porExtensoIngles,
+	}
+)
+
+// Prepara o texto retornado pela IA para ser narrado: substitui os blocos de código por uma
+// frase curta, remove a formatação Markdown, os emojis e as URLs, e escreve por extenso as
+// abreviações e os números, conforme o idioma da narração.
+// Não depende do TTS usado: recebe e retorna apenas texto.
+func normalizaParaFala(s, idioma string) string {
+	v := vocabularioDoIdioma(idioma)
+
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = removeBlocosDeCodigo(s, v)
+	s = removeFormatacao(s)
+	s = narraURLs(s, v)
+	s = removeEmojis(s)
+
+	if v.abreviacoes != nil {
+		s = expandeAbreviacoes(s, v)
+		s = strings.ReplaceAll(s, "%", v.porCento)
+		s = strings.ReplaceAll(s, "&", v.e)
+		s = numerosPorExtenso(s, v)
+	}
+
+	// Remove os espaços repetidos que sobraram, preservando as quebras de linha (fim das frases).
+	linhas := strings.Split(s, "\n")
+	for i, linha := range linhas {
+		linhas[i] = strings.Join(strings.Fields(linha), " ")
+	}
+	return strings.TrimSpace(strings.Join(linhas, "\n"))
+}
+
+// Retorna o vocabulário do idioma da narração (ex: "pt-br" --> português).
+func vocabularioDoIdioma(idioma string) *vocabulario {
+	idioma = strings.ToLower(idioma)
+	switch {
+	case strings.HasPrefix(idioma, "pt"):
+		return vocabularioPortugues
+	case strings.HasPrefix(idioma, "en"):
+		return vocabularioIngles
+	}
+
+	// Nos demais idiomas, apenas a formatação é removida. As frases dos blocos
+	// de código e das URLs são narradas em inglês.
+	return &vocabulario{
+		codigo:   vocabularioIngles.codigo,
+		codigoEm: vocabularioIngles.codigoEm,
+		link:     vocabularioIngles.link,
+	}
+}
+
+// Substitui os blocos de código por uma frase curta, para que não sejam narrados caractere por caractere.
+func removeBlocosDeCodigo(s string, v *vocabulario) string {
+	return blocoDeCodigo.ReplaceAllStringFunc(s, func(bloco string) string {
+		linguagem := blocoDeCodigo.FindStringSubmatch(bloco)[1]
+		if linguagem == "" {
+			return "\n" + v.codigo + "\n"
+		}
+		return "\n" + strings.Replace(v.codigoEm, "%s", linguagem, 1) + "\n"
+	})
+}
+
+// Remove os marcadores Markdown: títulos, citações, itens de lista, tabelas, negrito,
+// itálico e código. Os links são substituídos pelo texto dos mesmos.
+func removeFormatacao(s string) string {
+	s = linkMarkdown.ReplaceAllString(s, "$1")
+	s = strings.ReplaceAll(s, "`", "")
+	s = linhaTabela.ReplaceAllString(s, "")
+	s = separador.ReplaceAllString(s, "")
+	s = titulo.ReplaceAllString(s, "")
+	s = citacao.ReplaceAllString(s, "")
+	s = marcador.ReplaceAllString(s, "")
+	s = negrito.ReplaceAllString(s, "$2")
+	s = italico.ReplaceAllString(s, "$1$2$3")
+
+	// As células das tabelas são narradas como uma lista.
+	linhas := strings.Split(s, "\n")
+	for i, linha := range linhas {
+		if strings.Count(linha, "|") >= 2 {
+			celulas := strings.FieldsFunc(linha, func(c rune) bool { return c == '|' })
+			for j := range celulas {
+				celulas[j] = strings.TrimSpace(celulas[j])
+			}
+			linhas[i] = strings.Join(celulas, ", ") + "."
+		}
+	}
+	return strings.Join(linhas, "\n")
+}
+
+// Substitui as URLs pelo domínio (ex: "https://go.dev/doc/effective_go" --> "link para go.dev").
+func narraURLs(s string, v *vocabulario) string {
+	return urlNoTexto.ReplaceAllStringFunc(s, func(endereco string) string {
+		// Pontuação no fim da URL normalmente pertence à frase.
+		final := ""
+		for strings.ContainsAny(endereco[len(endereco)-1:], ".,;:!?") {
+			final = endereco[len(endereco)-1:] + final
+			endereco = endereco[:len(endereco)-1]
+		}
+
+		if !strings.Contains(endereco, "://") {
+			endereco = "http://" + endereco
+		}
+		u, err := url.Parse(endereco)
+		if err != nil || u.Hostname() == "" {
+			return v.link + final
+		}
+		return v.link + " " + strings.TrimPrefix(u.Hostname(), "www.") + final
+	})
+}
+
+// Remove os emojis e os demais símbolos pictográficos, que seriam narrados pelo nome
+// (ou ignorados de forma inconsistente, dependendo do TTS).
+func removeEmojis(s string) string {
+	return strings.Map(func(c rune) rune {
+		if unicode.Is(unicode.So, c) || unicode.Is(unicode.Sk, c) && c > 0x2000 ||
+			c == 0x200D || (c >= 0xFE00 && c <= 0xFE0F) {
+			return -1
+		}
+		return c
+	}, s)
+}
+
+// Monta a lista de abreviações a partir dos pares abreviação/extenso, na ordem informada.
+// As abreviações que contêm outras (ex: "p. ex." e "ex.") devem vir antes.
+func abreviacoes(pares ...string) []abreviacao {
+	lista := make([]abreviacao, 0, len(pares)/2)
+	for i := 0; i+1 < len(pares); i += 2 {
+		lista = append(lista, abreviacao{
+			re:      regexp.MustCompile(`(?i)(^|[^\p{L}\p{N}])` + regexp.QuoteMeta(pares[i]) + `(\s|$)`),
+			extenso: pares[i+1],
+		})
+	}
+	return lista
+}
+
+// Escreve por extenso as abreviações do idioma (ex: "etc." --> "etcétera").
+func expandeAbreviacoes(s string, v *vocabulario) string {
+	for _, a := range v.abreviacoes {
+		s = a.re.ReplaceAllString(s, "${1}"+a.extenso+"${2}")
+	}
+	return s
+}
+
+// Escreve os números por extenso, considerando os separadores de milhar e decimal do idioma.
+// Os números que não seguem esses formatos (ex: versões "1.2.3", endereços IP) não são alterados.
+func numerosPorExtenso(s string, v *vocabulario) string {
+	return numero.ReplaceAllStringFunc(s, func(n string) string {
+		inteiro, decimais, ok := separaNumero(n, v)
+		if !ok {
+			return n
+		}
+
+		valor, err := strconv.ParseInt(inteiro, 10, 64)
+		if err != nil || valor >= 1e12 {
+			return n
+		}
+
+		extenso := v.porExtenso(valor)
+		if decimais == "" {
+			return extenso
+		}
+
+		// Se a parte decimal começar com zero (ex: 1,05), é narrada algarismo por algarismo.
+		if decimais[0] == '0' || len(decimais) > 3 {
+			algarismos := make([]string, 0, len(decimais))
+			for _, d := range decimais {
+				algarismos = append(algarismos, v.porExtenso(int64(d-'0')))
+			}
+			return extenso + " " + v.decimal + " " + strings.Join(algarismos, " ")
+		}
+
+		d, _ := strconv.ParseInt(decimais, 10, 64)
+		return extenso + " " + v.decimal + " " + v.porExtenso(d)
+	})
+}
+
+// Separa a parte inteira e a parte decimal do número escrito no formato do idioma.
+// Também aceita o número decimal escrito com o separador do outro idioma (ex: "3.5" em português),
+// desde que não seja confundido com um separador de milhar.
+func separaNumero(n string, v *vocabulario) (string, string, bool) {
+	inteiro, decimais := n, ""
+	if i := strings.LastIndex(n, v.separaDecimal); i >= 0 {
+		inteiro, decimais = n[:i], n[i+1:]
+		if strings.Contains(decimais, v.separaMilhar) {
+			return "", "", false
+		}
+	}
+
+	grupos := strings.Split(inteiro, v.separaMilhar)
+	if len(grupos) == 1 {
+		if strings.Contains(inteiro, v.separaDecimal) {
+			return "", "", false
+		}
+		return inteiro, decimais, true
+	}
+
+	// Com separador de milhar: o primeiro grupo tem de 1 a 3 algarismos e os demais, exatamente 3.
+	// Se for apenas um separador que não forma um grupo de 3 (ex: "3.5"), é tratado como decimal.
+	if len(grupos) == 2 && decimais == "" && len(grupos[1]) != 3 {
+		return grupos[0], grupos[1], true
+	}
+	if len(grupos[0]) > 3 {
+		return "", "", false
+	}
+	for _, g := range grupos[1:] {
+		if len(g) != 3 {
+			return "", "", false
+		}
+	}
+	return strings.Join(grupos, ""), decimais, true
+}
+
+// Escreve o número por extenso em português (ex: 1234 --> "mil duzentos e trinta e quatro").
+func porExtensoPortugues(n int64) string {
+	unidades := []string{"zero", "um", "dois", "três", "quatro", "cinco", "seis", "sete", "oito", "nove",
+		"dez", "onze", "doze", "treze", "catorze", "quinze", "dezesseis", "dezessete", "dezoito", "dezenove"}
+	dezenas := []string{"", "", "vinte", "trinta", "quarenta", "cinquenta", "sessenta", "setenta", "oitenta", "noventa"}
+	centenas := []string{"", "cento", "duzentos", "trezentos", "quatrocentos", "quinhentos",
+		"seiscentos", "setecentos", "oitocentos", "novecentos"}
+
+	// Escreve por extenso os números de 1 a 999.
+	ate999 := func(n int64) string {
+		if n == 100 {
+			return "cem"
+		}
+		partes := make([]string, 0, 3)
+		if n >= 100 {
+			partes = append(partes, centenas[n/100])
+			n %= 100
+		}
+		if n >= 20 {
+			partes = append(partes, dezenas[n/10])
+			n %= 10
+		}
+		if n > 0 {
+			partes = append(partes, unidades[n])
+		}
+		return strings.Join(partes, " e ")
+	}
+
+	if n < 0 {
+		return "menos " + porExtensoPortugues(-n)
+	}
+	if n < 20 {
+		return unidades[n]
+	}
+
+	escalas := []struct {
+		valor            int64
+		singular, plural string
+	}{
+		{1e9, "um bilhão", "bilhões"},
+		{1e6, "um milhão", "milhões"},
+		{1e3, "mil", "mil"},
+	}
+
+	partes := make([]string, 0, 4)
+	resto, ultimo := n, int64(0) // "ultimo" é o valor (de 1 a 999) da última parte.
+	for _, e := range escalas {
+		if resto < e.valor {
+			continue
+		}
+		q := resto / e.valor
+		resto %= e.valor
+		if q == 1 {
+			partes = append(partes, e.singular)
+		} else {
+			partes = append(partes, ate999(q)+" "+e.plural)
+		}
+		ultimo = q
+	}
+	if resto > 0 {
+		partes = append(partes, ate999(resto))
+		ultimo = resto
+	}
+
+	// Usa "e" antes da última parte quando ela é menor que 100 ou uma centena exata
+	// (ex: "mil e cem", "dois mil e vinte", "dois milhões e quinhentos mil"), e apenas espaço
+	// nos demais casos ("mil duzentos e dez").
+	if len(partes) > 1 && (ultimo < 100 || ultimo%100 == 0) {
+		return strings.Join(partes[:len(partes)-1], " ") + " e " + partes[len(partes)-1]
+	}
+	return strings.Join(partes, " ")
+}
+
+// Escreve o número por extenso em inglês (ex: 1234 --> "one thousand two hundred thirty-four").
+func porExtensoIngles(n int64) string {
+	unidades := []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	dezenas := []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+
+	// Escreve por extenso os números de 1 a 999.
+	ate999 := func(n int64) string {
+		partes := make([]string, 0, 2)
+		if n >= 100 {
+			partes = append(partes, unidades[n/100]+" hundred")
+			n %= 100
+		}
+		switch {
+		case n >= 20 && n%10 != 0:
+			partes = append(partes, dezenas[n/10]+"-"+unidades[n%10])
+		case n >= 20:
+			partes = append(partes, dezenas[n/10])
+		case n > 0:
+			partes = append(partes, unidades[n])
+		}
+		return strings.Join(partes, " ")
+	}
+
+	if n < 0 {
+		return "minus " + porExtensoIngles(-n)
+	}
+	if n < 20 {
+		return unidades[n]
+	}
+
+	escalas := []struct {
+		valor int64
+		nome  string
+	}{
+		{1e9, "billion"},
+		{1e6, "million"},
+		{1e3, "thousand"},
+		{1, ""},
+	}
+
+	partes := make([]string, 0, 4)
+	resto := n
+	for _, e := range escalas {
+		if resto < e.valor {
+			continue
+		}
+		q := resto / e.valor
+		resto %= e.valor
+		partes = append(partes, strings.TrimSpace(ate999(q)+" "+e.nome))
+	}
+	return strings.Join(partes, " ")
+}
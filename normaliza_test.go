@@ -0,0 +1,131 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"testing"
+)
+
+func TestNormalizaParaFala(t *testing.T) {
+	testes := []struct {
+		nome   string
+		idioma string
+		texto  string
+		fala   string
+	}{
+		{"texto simples", "pt-BR", "Olá, mundo!", "Olá, mundo!"},
+		{"título e negrito", "pt-BR", "## Resumo\nO **Go** é _simples_.", "Resumo\nO Go é simples."},
+		{"marcadores", "pt-BR", "- Primeiro\n* Segundo\n• Terceiro", "Primeiro\nSegundo\nTerceiro"},
+		{"citação e código em linha", "pt-BR", "> Use `go test`.", "Use go test."},
+		{"link markdown", "pt-BR", "Veja a [documentação](https://go.dev/doc).", "Veja a documentação."},
+		{"url", "pt-BR", "Veja https://www.go.dev/doc/effective_go.", "Veja link para go.dev."},
+		{"url em inglês", "en-US", "See www.example.com/page, please.", "See link to example.com, please."},
+		{"bloco de código", "pt-BR", "Exemplo:\n```go\nfmt.Println(1)\n```\nFim.", "Exemplo:\n\nSegue um trecho de código em go.\n\nFim."},
+		{"bloco de código sem linguagem", "en-US", "```\nls -la\n```", "Here is a code snippet."},
+		{"emoji", "pt-BR", "Pronto! 🎉👍 Até mais.", "Pronto! Até mais."},
+		{"abreviações", "pt-BR", "Frutas, p. ex. maçã, banana etc. são boas.", "Frutas, por exemplo maçã, banana etcétera são boas."},
+		{"abreviações em inglês", "en-US", "Fruits, e.g. apples, vs. candy.", "Fruits, for example apples, versus candy."},
+		{"abreviação dentro de palavra", "pt-BR", "Veja o index.", "Veja o index."},
+		{"por cento", "pt-BR", "Subiu 5%.", "Subiu cinco por cento."},
+		{"número com milhar e decimal", "pt-BR", "Custa 1.500,50 reais.", "Custa mil e quinhentos vírgula cinquenta reais."},
+		{"número com milhar e decimal em inglês", "en-US", "It costs 1,500.50 dollars.", "It costs one thousand five hundred point fifty dollars."},
+		{"número no formato do outro idioma", "en-US", "Custa 1.500,50.", "Custa 1.500,50."},
+		{"versão não é número", "pt-BR", "Versão 1.2.3 lançada.", "Versão 1.2.3 lançada."},
+		{"outro idioma só remove a formatação", "es", "**Hola** 5 etc.", "Hola 5 etc."},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			if fala := normalizaParaFala(tt.texto, tt.idioma); fala != tt.fala {
+				t.Errorf("normalizaParaFala(%q, %q) = %q, esperado %q", tt.texto, tt.idioma, fala, tt.fala)
+			}
+		})
+	}
+}
+
+func TestSeparaNumero(t *testing.T) {
+	testes := []struct {
+		numero            string
+		v                 *vocabulario
+		inteiro, decimais string
+		ok                bool
+	}{
+		{"42", vocabularioPortugues, "42", "", true},
+		{"1.500,50", vocabularioPortugues, "1500", "50", true},
+		{"1.000.000", vocabularioPortugues, "1000000", "", true},
+		{"3,14", vocabularioPortugues, "3", "14", true},
+		{"3.5", vocabularioPortugues, "3", "5", true},
+		{"1.2.3", vocabularioPortugues, "", "", false},
+		{"1234.567", vocabularioPortugues, "", "", false},
+		{"1,500.50", vocabularioPortugues, "", "", false},
+		{"1,500.50", vocabularioIngles, "1500", "50", true},
+		{"1,000,000", vocabularioIngles, "1000000", "", true},
+		{"3.14", vocabularioIngles, "3", "14", true},
+		{"1.500,50", vocabularioIngles, "", "", false},
+		{"192.168.0.1", vocabularioIngles, "", "", false},
+	}
+
+	for _, tt := range testes {
+		inteiro, decimais, ok := separaNumero(tt.numero, tt.v)
+		if inteiro != tt.inteiro || decimais != tt.decimais || ok != tt.ok {
+			t.Errorf("separaNumero(%q, %s) = %q, %q, %v; esperado %q, %q, %v", tt.numero, tt.v.decimal,
+				inteiro, decimais, ok, tt.inteiro, tt.decimais, tt.ok)
+		}
+	}
+}
+
+func TestPorExtenso(t *testing.T) {
+	testes := []struct {
+		n                 int64
+		portugues, ingles string
+	}{
+		{0, "zero", "zero"},
+		{7, "sete", "seven"},
+		{15, "quinze", "fifteen"},
+		{21, "vinte e um", "twenty-one"},
+		{40, "quarenta", "forty"},
+		{100, "cem", "one hundred"},
+		{101, "cento e um", "one hundred one"},
+		{999, "novecentos e noventa e nove", "nine hundred ninety-nine"},
+		{1000, "mil", "one thousand"},
+		{1100, "mil e cem", "one thousand one hundred"},
+		{1234, "mil duzentos e trinta e quatro", "one thousand two hundred thirty-four"},
+		{1500, "mil e quinhentos", "one thousand five hundred"},
+		{2020, "dois mil e vinte", "two thousand twenty"},
+		{1000000, "um milhão", "one million"},
+		{2500000, "dois milhões e quinhentos mil", "two million five hundred thousand"},
+		{1001000, "um milhão e mil", "one million one thousand"},
+		{1234000, "um milhão duzentos e trinta e quatro mil", "one million two hundred thirty-four thousand"},
+		{1000000001, "um bilhão e um", "one billion one"},
+		{-3, "menos três", "minus three"},
+	}
+
+	for _, tt := range testes {
+		if s := porExtensoPortugues(tt.n); s != tt.portugues {
+			t.Errorf("porExtensoPortugues(%d) = %q, esperado %q", tt.n, s, tt.portugues)
+		}
+		if s := porExtensoIngles(tt.n); s != tt.ingles {
+			t.Errorf("porExtensoIngles(%d) = %q, esperado %q", tt.n, s, tt.ingles)
+		}
+	}
+}
@@ -24,11 +24,23 @@ hnovaes@yahoo.com
 
 import (
 	"bytes"
-	"os"
+	"encoding/binary"
+	"io"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hajimehoshi/go-mp3"
-	"github.com/hajimehoshi/oto/v2"
+)
+
+const (
+	// Taxa de amostragem da saída de audio. É a mesma dos audios do Google, que assim não
+	// precisam ser reamostrados; os audios com outra taxa são convertidos para esta.
+	TAXA_SAIDA = 24000
+
+	// Tamanho do buffer do player: 100 ms de audio (estéreo, 16 bits). Quanto menor,
+	// mais rápido o player atende à pausa, ao pulo de trecho e à interrupção.
+	BUFFER_SAIDA = TAXA_SAIDA * 4 / 10
 )
 
 type (
@@ -36,77 +48,348 @@ type (
 		Sequencia int
 		Path      string
 		Texto     string
+		Idioma    string // Idioma da narração do texto. Se vazio, usa o parâmetro IDIOMA.
 		Erro      error
 		Playing   bool
+		Pronto    chan struct{} // Fechado quando o download termina (com ou sem erro).
+	}
+
+	// Dispositivo que executa o audio entregue pela saída de audio. No Windows, é o player do oto.
+	dispositivoAudio interface {
+		Pause()
+		Play()
+		Volume() float64
+		SetVolume(volume float64)
+		UnplayedBufferSize() int
+	}
+
+	// Saída de audio do aplicativo. O oto só permite criar um contexto por processo, por isso
+	// um único contexto e um único player são criados e mantidos até o fim da execução.
+	// O player lê continuamente a fila de trechos: quando um trecho termina, o seguinte já
+	// está no buffer, sem pausa entre eles. Sem trechos na fila, o player fica em silêncio.
+	SaidaAudio struct {
+		mutex    sync.Mutex
+		player   dispositivoAudio
+		fila     []*TrechoAudio // Trechos aguardando a vez.
+		atual    *TrechoAudio   // Trecho sendo lido pelo player.
+		enviados []*TrechoAudio // Trechos lidos (total ou parcialmente), mas ainda não executados até o fim.
+		escritos int64          // Total de bytes entregues ao player.
+		pausado  bool
+	}
+
+	// Trecho de audio na fila da saída de audio.
+	TrechoAudio struct {
+		Sequencia  int
+		Progresso  func(fracao float64) // Se informada, é acionada com a fração (de 0 a 1) já executada do trecho.
+		audio      *reamostrador
+		total      int64   // Tamanho, em bytes, do audio decodificado.
+		marcos     []marco // Posições na saída em que o player leu cada parte do trecho.
+		executados int64   // Bytes do audio decodificado já executados.
+		lido       bool    // Se true, o trecho foi lido até o fim.
+		fimEm      int64   // Posição na saída em que o trecho termina (se já foi lido até o fim).
+		fim        chan struct{}
 	}
 
-	Player struct {
-		AudioToPlay     *DownloadedAudio
-		DeleteAfterPlay bool
-		player          oto.Player
+	// Indica que, ao executar a saída até a posição "saida", o trecho terá executado
+	// "consumidos" bytes do audio decodificado.
+	marco struct {
+		saida      int64
+		consumidos int64
+	}
+
+	// Estado da saída de audio, para os demais módulos do aplicativo.
+	EstadoAudio struct {
+		Tocando   bool    // Há algum trecho sendo executado.
+		Pausado   bool    // A saída está pausada.
+		Trecho    int     // Sequência do trecho em execução (-1 se nenhum).
+		Progresso float64 // Fração (de 0 a 1) já executada do trecho.
+		NaFila    int     // Quantidade de trechos aguardando a vez.
+	}
+
+	// Reamostra o audio decodificado (PCM de 16 bits, estéreo) por interpolação linear.
+	// A cada quadro (par de amostras esquerda/direita) gerado, avança "fator" quadros no audio
+	// original: com fator 2, o audio é executado no dobro da velocidade (e com tom mais agudo).
+	// O fator é usado tanto para alterar a velocidade quanto para converter a taxa de amostragem.
+	reamostrador struct {
+		r           io.Reader
+		fator       func() float64
+		amostras    []int16 // Amostras lidas do audio original e ainda não descartadas.
+		resto       []byte  // Bytes lidos que não completam uma amostra.
+		posicao     float64 // Posição, em quadros, dentro de "amostras".
+		descartados int64   // Quadros já descartados do início de "amostras".
+		consumidos  int64   // Bytes do audio original já consumidos (acesso atômico).
+		fim         bool
 	}
 )
 
-// Trecho extraido e adaptado do https://github.com/hegedustibor/htgo-tts
-// O que tem de diferente?
-// 1 - Recebe como parâmetro uma função que é acionada para verificar se é para dar stop no player.
-// 2 - Usa a estrutura Player com os detalhes do audio a executar.
-// 3 - Se o campo DeleteAfterPlayer da estrutura for true, deleta o arquivo ao terminar de tocar.
-func (p *Player) Play(stopFunc func() bool) error {
-	defer p.Close()
+// Retorna a saída de audio, abrindo o dispositivo de audio na primeira vez.
+func (sessao *Sessao) saidaDeAudio() (*SaidaAudio, error) {
+	sessao.criaSaida.Do(func() {
+		s := &SaidaAudio{}
+		player, err := sessao.abreDispositivo(s)
+		if err != nil {
+			sessao.erroSaida = err
+			return
+		}
 
-	fileName := p.AudioToPlay.Path
-	fileBytes, err := os.ReadFile(fileName)
+		s.player = player
+		s.player.Play()
+		sessao.saida = s
+
+		go s.acompanha()
+	})
+	return sessao.saida, sessao.erroSaida
+}
+
+// Decodifica o audio MP3 e prepara o trecho para ser colocado na fila da saída de audio.
+// O trecho é convertido para a taxa de amostragem da saída e executado na velocidade
+// definida nos controles da narração informados.
+func NovoTrecho(sequencia int, mp3Bytes []byte, controle *ControleNarracao, progresso func(fracao float64)) (*TrechoAudio, error) {
+	decodedMp3, err := mp3.NewDecoder(bytes.NewReader(mp3Bytes))
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	taxa := float64(decodedMp3.SampleRate()) / TAXA_SAIDA
+	return &TrechoAudio{
+		Sequencia: sequencia,
+		Progresso: progresso,
+		audio:     &reamostrador{r: decodedMp3, fator: func() float64 { return taxa * controle.Velocidade() }},
+		total:     decodedMp3.Length(),
+		fim:       make(chan struct{}),
+	}, nil
+}
+
+// Indica se o trecho terminou de ser executado (ou foi descartado).
+func (t *TrechoAudio) Terminou() bool {
+	select {
+	case <-t.fim:
+		return true
+	default:
+		return false
 	}
+}
 
-	fileBytesReader := bytes.NewReader(fileBytes)
+// Calcula a fração já executada do trecho, considerando que a saída executou até a posição informada.
+// Os marcos anteriores a essa posição são descartados.
+func (t *TrechoAudio) fracao(executado int64) float64 {
+	for len(t.marcos) > 0 && t.marcos[0].saida <= executado {
+		t.executados = t.marcos[0].consumidos
+		t.marcos = t.marcos[1:]
+	}
+	if t.total <= 0 {
+		return 0
+	}
+	return float64(t.executados) / float64(t.total)
+}
 
-	decodedMp3, err := mp3.NewDecoder(fileBytesReader)
-	if err != nil {
-		return err
+// Coloca o trecho no fim da fila. É executado logo após os que já estão na fila, sem pausa entre eles.
+func (s *SaidaAudio) Enfileira(t *TrechoAudio) {
+	defer s.mutex.Unlock()
+	s.mutex.Lock()
+	s.fila = append(s.fila, t)
+}
+
+// Descarta o trecho em execução, passando para o próximo da fila.
+func (s *SaidaAudio) Pula() {
+	defer s.mutex.Unlock()
+	s.mutex.Lock()
+
+	if len(s.enviados) == 0 {
+		return
+	}
+	t := s.enviados[0]
+	if t == s.atual {
+		s.atual = nil
 	}
+	s.enviados = s.enviados[1:]
+	s.finaliza(t, true)
+}
 
-	numOfChannels := 2
-	audioBitDepth := 2
+// Descarta todos os trechos, o em execução e os da fila.
+func (s *SaidaAudio) Interrompe() {
+	defer s.mutex.Unlock()
+	s.mutex.Lock()
 
-	otoCtx, readyChan, err := oto.NewContext(decodedMp3.SampleRate(), numOfChannels, audioBitDepth)
-	if err != nil {
-		return err
+	for _, t := range append(s.enviados, s.fila...) {
+		s.finaliza(t, false)
+	}
+	s.enviados = nil
+	s.fila = nil
+	s.atual = nil
+}
+
+// Pausa ou continua a execução.
+// Os métodos do player não são chamados com a mutex bloqueada, pois o player aciona
+// o método Read (que bloqueia a mutex) com a sua própria mutex bloqueada.
+func (s *SaidaAudio) Pausa(pausar bool) {
+	s.mutex.Lock()
+	alterou := s.pausado != pausar
+	s.pausado = pausar
+	s.mutex.Unlock()
+
+	if !alterou {
+		return
 	}
-	<-readyChan
+	if pausar {
+		s.player.Pause()
+	} else {
+		s.player.Play()
+	}
+}
 
-	p.player = otoCtx.NewPlayer(decodedMp3)
+// Altera o volume da saída (de 0 a 1).
+func (s *SaidaAudio) DefineVolume(volume float64) {
+	if s.player.Volume() != volume {
+		s.player.SetVolume(volume)
+	}
+}
 
-	p.player.Play()
+// Retorna o estado atual da saída de audio.
+func (s *SaidaAudio) Estado() EstadoAudio {
+	pendente := int64(s.player.UnplayedBufferSize())
 
-	for p.player.IsPlaying() {
+	defer s.mutex.Unlock()
+	s.mutex.Lock()
 
-		if stopFunc != nil {
-			if stopFunc() {
+	estado := EstadoAudio{Pausado: s.pausado, Trecho: -1, NaFila: len(s.fila)}
+	if len(s.enviados) > 0 {
+		t := s.enviados[0]
+		estado.Tocando = true
+		estado.Trecho = t.Sequencia
+		estado.Progresso = t.fracao(s.escritos - pendente)
+	}
+	return estado
+}
+
+// Entrega ao player o audio dos trechos da fila, um após o outro.
+// Sem trechos na fila, não entrega nada e o player fica em silêncio até o próximo trecho.
+func (s *SaidaAudio) Read(b []byte) (int, error) {
+	defer s.mutex.Unlock()
+	s.mutex.Lock()
+
+	// Entrega apenas quadros completos (2 canais de 16 bits).
+	limite := len(b) - len(b)%4
+	n := 0
+	for n < limite {
+		if s.atual == nil {
+			if len(s.fila) == 0 {
 				break
 			}
+			s.atual = s.fila[0]
+			s.fila = s.fila[1:]
+			s.enviados = append(s.enviados, s.atual)
 		}
 
-		time.Sleep(time.Millisecond * 10)
+		t := s.atual
+		m, err := t.audio.Read(b[n:limite])
+		n += m
+		if m > 0 {
+			t.marcos = append(t.marcos, marco{saida: s.escritos + int64(n), consumidos: atomic.LoadInt64(&t.audio.consumidos)})
+		}
+		if err != nil {
+			t.lido = true
+			t.fimEm = s.escritos + int64(n)
+			s.atual = nil
+		} else if m == 0 {
+			break
+		}
 	}
 
-	return p.Close()
+	s.escritos += int64(n)
+	return n, nil
+}
+
+// Acompanha a execução dos trechos, informando o progresso de cada um e
+// sinalizando o fim dos que terminaram de ser executados.
+func (s *SaidaAudio) acompanha() {
+	for {
+		time.Sleep(time.Millisecond * 10)
+
+		pendente := int64(s.player.UnplayedBufferSize())
+
+		s.mutex.Lock()
+		executado := s.escritos - pendente
+		for len(s.enviados) > 0 {
+			t := s.enviados[0]
+			if t.lido && executado >= t.fimEm {
+				s.enviados = s.enviados[1:]
+				s.finaliza(t, true)
+				continue
+			}
+			if t.Progresso != nil {
+				t.Progresso(t.fracao(executado))
+			}
+			break
+		}
+		s.mutex.Unlock()
+	}
 }
 
-func (p *Player) IsPlaying() bool {
-	return p.player != nil && p.player.IsPlaying()
+// Sinaliza o fim do trecho. Se "completo" for true, informa que o trecho foi executado até o fim.
+func (s *SaidaAudio) finaliza(t *TrechoAudio, completo bool) {
+	if t.Terminou() {
+		return
+	}
+	if completo && t.Progresso != nil {
+		t.Progresso(1)
+	}
+	close(t.fim)
 }
 
-func (p *Player) Close() error {
-	if p.player == nil {
-		return nil
+func (a *reamostrador) Read(b []byte) (int, error) {
+	fator := a.fator()
+	n := 0
+
+	for n+4 <= len(b) {
+		i := int(a.posicao)
+
+		// Garante que o quadro atual e o seguinte (para a interpolação) foram lidos.
+		for !a.fim && len(a.amostras)/2 < i+2 {
+			a.carrega()
+		}
+		if len(a.amostras)/2 <= i {
+			break
+		}
+
+		fracao := a.posicao - float64(i)
+		for c := 0; c < 2; c++ {
+			atual := float64(a.amostras[i*2+c])
+			seguinte := atual
+			if len(a.amostras)/2 > i+1 {
+				seguinte = float64(a.amostras[(i+1)*2+c])
+			}
+			binary.LittleEndian.PutUint16(b[n+c*2:], uint16(int16(atual+(seguinte-atual)*fracao)))
+		}
+		n += 4
+		a.posicao += fator
+
+		// Descarta os quadros já executados, para não acumular o audio inteiro em memória.
+		if d := int(a.posicao); d >= 4096 && d <= len(a.amostras)/2 {
+			a.amostras = a.amostras[d*2:]
+			a.posicao -= float64(d)
+			a.descartados += int64(d)
+		}
 	}
 
-	if p.DeleteAfterPlay && p.AudioToPlay != nil {
-		os.Remove(p.AudioToPlay.Path)
+	atomic.StoreInt64(&a.consumidos, (a.descartados+int64(a.posicao))*4)
+
+	if n == 0 && a.fim {
+		return 0, io.EOF
 	}
+	return n, nil
+}
 
-	return p.player.Close()
+// Lê o próximo trecho do audio original.
+func (a *reamostrador) carrega() {
+	buf := make([]byte, 8192)
+	m, err := a.r.Read(buf)
+	a.resto = append(a.resto, buf[:m]...)
+	for len(a.resto) >= 2 {
+		a.amostras = append(a.amostras, int16(binary.LittleEndian.Uint16(a.resto)))
+		a.resto = a.resto[2:]
+	}
+	if err != nil {
+		a.fim = true
+	}
 }
@@ -0,0 +1,237 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// Audio PCM (estéreo, 16 bits) em rampa: as duas amostras do quadro i valem i.
+func pcmRampa(quadros int) []byte {
+	pcm := make([]byte, quadros*4)
+	for i := 0; i < quadros; i++ {
+		binary.LittleEndian.PutUint16(pcm[i*4:], uint16(int16(i)))
+		binary.LittleEndian.PutUint16(pcm[i*4+2:], uint16(int16(i)))
+	}
+	return pcm
+}
+
+// Com o fator f, cada quadro gerado avança f quadros no audio original: são gerados N/f
+// quadros, e cada um é a interpolação dos quadros vizinhos do original. Com fatores inexatos em
+// ponto flutuante (ex: 2/3), N/f não pode ser inteiro, pois o arredondamento mudaria a contagem.
+func TestReamostrador(t *testing.T) {
+	testes := []struct {
+		nome    string
+		quadros int
+		fator   float64
+		leitor  func(io.Reader) io.Reader
+		buffer  int
+	}{
+		{"mesma taxa", 10000, 1, nil, BUFFER_SAIDA},
+		{"dobro da velocidade", 10000, 2, nil, BUFFER_SAIDA},
+		{"metade da velocidade", 10000, 0.5, nil, BUFFER_SAIDA},
+		{"44100 Hz para a saída", 10000, 44100.0 / TAXA_SAIDA, nil, BUFFER_SAIDA},
+		{"16000 Hz para a saída", 10001, 16000.0 / TAXA_SAIDA, nil, BUFFER_SAIDA},
+		{"velocidade e taxa", 15000, 1.5 * 44100 / TAXA_SAIDA, nil, BUFFER_SAIDA},
+		{"leitura byte a byte", 3000, 1.25, iotest.OneByteReader, BUFFER_SAIDA},
+		{"leitura pela metade", 3000, 1.25, iotest.HalfReader, BUFFER_SAIDA},
+		{"buffer sem quadro completo", 3000, 0.75, nil, 1001},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			var fonte io.Reader = bytes.NewReader(pcmRampa(tt.quadros))
+			if tt.leitor != nil {
+				fonte = tt.leitor(fonte)
+			}
+			a := &reamostrador{r: fonte, fator: func() float64 { return tt.fator }}
+
+			saida := make([]byte, 0)
+			buf := make([]byte, tt.buffer)
+			for {
+				n, err := a.Read(buf)
+				if n%4 != 0 {
+					t.Fatalf("Read entregou %d bytes, que não completam um quadro", n)
+				}
+				saida = append(saida, buf[:n]...)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n == 0 {
+					t.Fatal("Read não entregou nada antes do fim do audio")
+				}
+			}
+
+			// Quadros nas posições 0, f, 2f... enquanto a posição estiver dentro do audio original.
+			gerados := len(saida) / 4
+			if esperados := int(math.Ceil(float64(tt.quadros) / tt.fator)); gerados != esperados {
+				t.Errorf("%d quadros gerados, esperado %d (razão %.4f, esperada %.4f)",
+					gerados, esperados, float64(tt.quadros)/float64(gerados), tt.fator)
+			}
+
+			for q := 0; q < gerados; q++ {
+				posicao := float64(q) * tt.fator
+				if posicao > float64(tt.quadros-1) {
+					break
+				}
+				for c := 0; c < 2; c++ {
+					amostra := float64(int16(binary.LittleEndian.Uint16(saida[q*4+c*2:])))
+					if math.Abs(amostra-posicao) > 1 {
+						t.Fatalf("quadro %d, canal %d = %v, esperado %.2f", q, c, amostra, posicao)
+					}
+				}
+			}
+
+			if a.consumidos < int64(tt.quadros)*4 {
+				t.Errorf("%d bytes consumidos, esperado %d", a.consumidos, tt.quadros*4)
+			}
+		})
+	}
+}
+
+// Trechos de silêncio na saída de audio da sessão (com o dispositivo nulo). Registra a ordem
+// em que os trechos são executados até o fim (ou pulados), que é quando o progresso chega a 1.
+type filaTeste struct {
+	saida     *SaidaAudio
+	trechos   []*TrechoAudio
+	mutex     sync.Mutex
+	completos []int
+}
+
+func novaFilaTeste(t *testing.T, sessao *Sessao, quadros ...int) *filaTeste {
+	t.Helper()
+
+	saida, err := sessao.saidaDeAudio()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &filaTeste{saida: saida}
+	for i, q := range quadros {
+		sequencia := i
+		trecho, err := NovoTrecho(i, mp3Silencioso(q), sessao.controle, func(fracao float64) {
+			f.mutex.Lock()
+			defer f.mutex.Unlock()
+			if fracao >= 1 && (len(f.completos) == 0 || f.completos[len(f.completos)-1] != sequencia) {
+				f.completos = append(f.completos, sequencia)
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.trechos = append(f.trechos, trecho)
+		saida.Enfileira(trecho)
+	}
+	return f
+}
+
+func (f *filaTeste) Completos() []int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]int{}, f.completos...)
+}
+
+// Aguarda a condição ser atendida, por no máximo 10 segundos.
+func aguardaCondicao(t *testing.T, descricao string, condicao func() bool) {
+	t.Helper()
+	limite := time.Now().Add(10 * time.Second)
+	for !condicao() {
+		if time.Now().After(limite) {
+			t.Fatalf("tempo esgotado aguardando: %s", descricao)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// Os trechos da fila são executados um após o outro, até o fim.
+func TestSaidaAudioExecutaFila(t *testing.T) {
+	sessao := configuraTeste(t)
+	f := novaFilaTeste(t, sessao, 8, 4, 8)
+
+	aguardaCondicao(t, "fim do último trecho", f.trechos[2].Terminou)
+	for i, trecho := range f.trechos {
+		if !trecho.Terminou() {
+			t.Errorf("trecho %d não terminou", i)
+		}
+	}
+	if c := f.Completos(); !reflect.DeepEqual(c, []int{0, 1, 2}) {
+		t.Errorf("trechos executados até o fim = %v, esperado [0 1 2]", c)
+	}
+	aguardaCondicao(t, "saída sem trechos", func() bool { return !f.saida.Estado().Tocando })
+	if e := f.saida.Estado(); e.Trecho != -1 || e.NaFila != 0 {
+		t.Errorf("estado após o fim da fila = %+v", e)
+	}
+}
+
+// Pular o trecho em execução passa para o próximo da fila; interromper descarta todos.
+func TestSaidaAudioPula(t *testing.T) {
+	sessao := configuraTeste(t)
+	// Cada trecho tem cerca de 8 segundos, para não terminar antes de ser pulado.
+	f := novaFilaTeste(t, sessao, 300, 300, 300)
+
+	for _, trecho := range []int{0, 1, 2} {
+		aguardaCondicao(t, "execução do trecho", func() bool { return f.saida.Estado().Trecho == trecho })
+		if e := f.saida.Estado(); !e.Tocando || e.NaFila != 2-trecho {
+			t.Errorf("estado no trecho %d = %+v, esperado %d na fila", trecho, e, 2-trecho)
+		}
+		if trecho == 2 {
+			break
+		}
+
+		f.saida.Pula()
+		if !f.trechos[trecho].Terminou() {
+			t.Errorf("trecho %d não terminou ao ser pulado", trecho)
+		}
+		if f.trechos[trecho+1].Terminou() {
+			t.Errorf("trecho %d terminou antes de ser executado", trecho+1)
+		}
+	}
+	// O trecho pulado é dado como executado, para a impressão da resposta continuar.
+	if c := f.Completos(); !reflect.DeepEqual(c, []int{0, 1}) {
+		t.Errorf("trechos completos = %v, esperado [0 1]", c)
+	}
+
+	f.saida.Interrompe()
+	if !f.trechos[2].Terminou() {
+		t.Error("o último trecho não terminou ao interromper a saída")
+	}
+	if c := f.Completos(); !reflect.DeepEqual(c, []int{0, 1}) {
+		t.Errorf("trechos completos após interromper = %v, esperado [0 1]", c)
+	}
+	if e := f.saida.Estado(); e.Tocando || e.NaFila != 0 {
+		t.Errorf("estado após interromper = %+v", e)
+	}
+
+	// Sem trechos, pular não faz nada.
+	f.saida.Pula()
+}
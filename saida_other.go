@@ -0,0 +1,35 @@
+//go:build !windows
+
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"io"
+)
+
+// A execução do audio usa o oto, que neste aplicativo só é configurado para o Windows.
+// Nos demais sistemas, a narração não pode ser ouvida, mas pode ser gravada em arquivo.
+func abreDispositivoDoSistema(fonte io.Reader) (dispositivoAudio, error) {
+	return nil, novoErro("a execução de audio só está disponível no Windows. Grave a narração com /speak save ou --save-audio, ou desative-a com /set tts=false")
+}
@@ -0,0 +1,58 @@
+//go:build !windows
+
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"regexp"
+	"testing"
+)
+
+// O erro exibido fora do Windows sugere comandos que funcionam: o "/set" sugerido desativa a narração,
+// no idioma da interface em português e em inglês.
+func TestSugestaoSemAudio(t *testing.T) {
+	comandoSet := regexp.MustCompile(`/set \S+`)
+
+	for _, idioma := range []string{"pt-BR", "en"} {
+		t.Run(idioma, func(t *testing.T) {
+			sessao := configuraTeste(t)
+			sessao.settings.TTS = true
+			defineIdiomaInterface(idioma)
+
+			_, err := abreDispositivoDoSistema(nil)
+			if err == nil {
+				t.Fatal("esperado erro ao abrir o dispositivo de audio")
+			}
+			comando := comandoSet.FindString(err.Error())
+			if comando == "" {
+				t.Fatalf("o erro %q não sugere o comando /set", err)
+			}
+
+			capturaSaida(t, sessao, func() { sessao.executaComando(comando) })
+			if sessao.settings.TTS {
+				t.Errorf("o comando %q sugerido não desativou a narração", comando)
+			}
+		})
+	}
+}
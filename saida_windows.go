@@ -0,0 +1,45 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"io"
+
+	"github.com/hajimehoshi/oto/v2"
+)
+
+// Cria o contexto do oto e o player que lê o audio da fonte informada.
+// O oto só permite criar um contexto por processo, por isso esta função é chamada uma única vez.
+func abreDispositivoDoSistema(fonte io.Reader) (dispositivoAudio, error) {
+	otoCtx, readyChan, err := oto.NewContext(TAXA_SAIDA, 2, 2)
+	if err != nil {
+		return nil, err
+	}
+	<-readyChan
+
+	player := otoCtx.NewPlayer(fonte)
+	if p, ok := player.(interface{ SetBufferSize(int) }); ok {
+		p.SetBufferSize(BUFFER_SAIDA)
+	}
+	return player, nil
+}
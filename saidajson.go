@@ -0,0 +1,135 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"gpt-falador/falador"
+)
+
+type (
+	// Objeto impresso pelo parâmetro --json: a resposta e os dados da requisição, ou o erro.
+	// Os nomes dos campos seguem os da API, para facilitar o uso em scripts (ex: jq).
+	RespostaJson struct {
+		Answer       string `json:"answer"`                  // Texto da resposta (vazio, em caso de erro).
+		Model        string `json:"model"`                   // Modelo que respondeu (ou o configurado, em caso de erro).
+		FinishReason string `json:"finish_reason,omitempty"` // Motivo do fim da resposta (ex: "stop", "length").
+		Usage        *Usage `json:"usage,omitempty"`         // Tokens consumidos na requisição.
+		LatencyMs    int64  `json:"latency_ms"`              // Tempo de resposta da API, em milissegundos.
+		Error        string `json:"error,omitempty"`         // Mensagem de erro, se a pergunta falhou.
+	}
+)
+
+// Ativa o modo --json: as demais mensagens (ex: erros ao gravar a conversa) vão para a saída de erros,
+// sem cores e sem pausas, para que a saída padrão tenha somente o objeto json.
+func (sessao *Sessao) ativaSaidaJson() {
+	if sessao.saidaJson {
+		return
+	}
+	sessao.saidaJson = true
+	sessao.tela = NovoRenderizador(sessao.saidaErros, Capacidades{})
+}
+
+// Verifica se o parâmetro --json foi informado na linha de comando, antes de imprimir qualquer mensagem.
+func (sessao *Sessao) pediuSaidaJson() bool {
+	for _, arg := range sessao.argumentos[1:] {
+		if arg == "--" {
+			break
+		}
+		nome, valor, temValor := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if strings.HasPrefix(arg, "-") && nome == "json" {
+			b, _ := strconv.ParseBool(valor)
+			return !temValor || b
+		}
+	}
+	return false
+}
+
+// Envia a pergunta (ou, no modo de tradução, o texto a traduzir) e imprime a resposta como
+// um único objeto json, sem narração. Retorna false se a pergunta falhou (o erro é informado no campo "error").
+func (sessao *Sessao) respondeEmJson(pergunta string) bool {
+	inicio := time.Now()
+	var resposta *falador.Resposta
+	var err error
+	if sessao.modoTraducao {
+		resposta, _, err = sessao.traduzTexto(pergunta)
+	} else {
+		resposta, err = sessao.perguntaAoServidor(pergunta)
+	}
+	r := sessao.montaRespostaJson(resposta, err, time.Since(inicio))
+
+	// A tradução não faz parte da conversa.
+	if err == nil && !sessao.modoTraducao {
+		// Grava e exporta a conversa como no modo normal.
+		if err := sessao.gravaConversa(); err != nil {
+			sessao.tela.Erro(err)
+		}
+		if sessao.arquivoExport != "" {
+			if err := sessao.exportaConversa("", sessao.arquivoExport); err != nil {
+				sessao.tela.Erro(err)
+			}
+		}
+	}
+
+	codificador := json.NewEncoder(sessao.saidaPadrao)
+	codificador.SetEscapeHTML(false)
+	if e := codificador.Encode(r); e != nil {
+		sessao.tela.Erro(e)
+		return false
+	}
+	return err == nil
+}
+
+// Monta o objeto json com a resposta ou com o erro da pergunta. Em caso de erro, o tempo de
+// resposta é o decorrido até a falha e o modelo é o configurado.
+func (sessao *Sessao) montaRespostaJson(resposta *falador.Resposta, err error, decorrido time.Duration) RespostaJson {
+	r := RespostaJson{Model: sessao.configuracoes().GPT_MODEL, LatencyMs: decorrido.Milliseconds()}
+	if err != nil {
+		r.Error = descricaoDoErro(err)
+		return r
+	}
+
+	r.Answer = resposta.Mensagem.Content
+	r.FinishReason = resposta.FinishReason
+	r.LatencyMs = resposta.Duracao.Milliseconds()
+	if resposta.Resultado != nil {
+		r.Model = resposta.Resultado.Model
+		r.Usage = &resposta.Resultado.Usage
+	}
+	return r
+}
+
+// Envia a pergunta ao servidor, dentro da conversa atual, com as configurações atuais.
+func (sessao *Sessao) perguntaAoServidor(pergunta string) (*falador.Resposta, error) {
+	if pergunta == "" {
+		return nil, novoErro("nenhuma pergunta informada")
+	}
+	sessao.conversa.Cliente = novoCliente(sessao.configuracoes())
+	sessao.conversa.Idioma = idiomaDaPergunta(pergunta, sessao.settings.IDIOMA)
+	return sessao.conversa.Pergunta(context.Background(), pergunta)
+}
@@ -0,0 +1,125 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"gpt-falador/falador"
+)
+
+type (
+	// Requisição recebida pelo subcomando "serve" no endereço /ask.
+	PerguntaJson struct {
+		Question string `json:"question"`       // Pergunta enviada à IA.
+		Lang     string `json:"lang,omitempty"` // Idioma da resposta. Se vazio, usa o parâmetro IDIOMA.
+	}
+)
+
+const (
+	// Endereço padrão do subcomando "serve". Só aceita conexões do próprio computador.
+	ENDERECO_SERVIDOR = "localhost:8080"
+
+	// Tamanho máximo da requisição recebida pelo servidor.
+	MAX_REQUISICAO = 1 << 20
+)
+
+func init() {
+	registraOpcao(&Opcao{
+		Nome:       "addr",
+		Argumento:  "<host:porta>",
+		Descricao:  "Endereço do subcomando \033[36mserve\033[m",
+		Padrao:     ENDERECO_SERVIDOR,
+		Subcomando: "serve",
+		Define: func(sessao *Sessao, valor string) error {
+			sessao.enderecoServidor = valor
+			return nil
+		},
+	})
+
+	registraSubcomando(&Subcomando{
+		Nome: "serve",
+		Descricao: "Responde as perguntas recebidas via HTTP: POST /ask com {\"question\": \"...\"}\n" +
+			"retorna o mesmo objeto json do parâmetro \033[36m--json\033[m. Cada pergunta é independente (sem histórico).",
+		Executa: func(sessao *Sessao, args []string) (string, error) {
+			sessao.encerrada = true
+			if len(args) > 0 {
+				return "", novoErro("o subcomando serve não aceita argumentos. Use --addr para informar o endereço")
+			}
+			sessao.tela.Printf(traduz("Aguardando perguntas em \033[96mhttp://%s/ask\033[m (tecle Ctrl+C para encerrar)\r\n"), sessao.enderecoServidor)
+			return "", http.ListenAndServe(sessao.enderecoServidor, sessao.servidorHTTP())
+		},
+	})
+}
+
+// Rotas do subcomando "serve".
+func (sessao *Sessao) servidorHTTP() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ask", sessao.trataPerguntaHTTP)
+	return mux
+}
+
+// Responde a pergunta recebida via HTTP em uma nova conversa, com as configurações atuais.
+func (sessao *Sessao) trataPerguntaHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		respondeHTTP(w, http.StatusMethodNotAllowed, RespostaJson{Error: "use o método POST"})
+		return
+	}
+
+	p := PerguntaJson{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MAX_REQUISICAO)).Decode(&p); err != nil {
+		respondeHTTP(w, http.StatusBadRequest, RespostaJson{Error: "json inválido: " + err.Error()})
+		return
+	}
+	if strings.TrimSpace(p.Question) == "" {
+		respondeHTTP(w, http.StatusBadRequest, RespostaJson{Error: "informe a pergunta no campo \"question\""})
+		return
+	}
+
+	cfg := sessao.configuracoes()
+	idioma := cfg.IDIOMA
+	if p.Lang != "" {
+		idioma = p.Lang
+	}
+	conversa := falador.NovaConversa(novoCliente(sessao.configuracoes()), idiomaDaPergunta(p.Question, idioma))
+
+	inicio := time.Now()
+	resposta, err := conversa.Pergunta(r.Context(), p.Question)
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusBadGateway
+	}
+	respondeHTTP(w, status, sessao.montaRespostaJson(resposta, err, time.Since(inicio)))
+}
+
+func respondeHTTP(w http.ResponseWriter, status int, r RespostaJson) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	codificador := json.NewEncoder(w)
+	codificador.SetEscapeHTML(false)
+	codificador.Encode(r)
+}
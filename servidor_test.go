@@ -0,0 +1,92 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServidorHTTP(t *testing.T) {
+	testes := []struct {
+		nome     string
+		metodo   string
+		corpo    string
+		prepara  func(f *servidorFake)
+		status   int
+		resposta string
+		erro     string
+		idioma   string
+	}{
+		{"pergunta", http.MethodPost, `{"question": "Capital do Brasil?"}`, func(f *servidorFake) { f.Resposta = "Brasília." }, http.StatusOK, "Brasília.", "", "pt-BR"},
+		{"idioma", http.MethodPost, `{"question": "Olá", "lang": "en-us"}`, func(f *servidorFake) {}, http.StatusOK, "Resposta do servidor falso.", "", "en-us"},
+		{"erro da API", http.MethodPost, `{"question": "Olá"}`, func(f *servidorFake) { f.ErroChat = "modelo inexistente" }, http.StatusBadGateway, "", "modelo inexistente", ""},
+		{"sem pergunta", http.MethodPost, `{"question": " "}`, func(f *servidorFake) {}, http.StatusBadRequest, "", "informe a pergunta", ""},
+		{"json inválido", http.MethodPost, `{question`, func(f *servidorFake) {}, http.StatusBadRequest, "", "json inválido", ""},
+		{"método GET", http.MethodGet, "", func(f *servidorFake) {}, http.StatusMethodNotAllowed, "", "POST", ""},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			f, sessao := novoServidorFake(t)
+			tt.prepara(f)
+			servidor := httptest.NewServer(sessao.servidorHTTP())
+			defer servidor.Close()
+
+			req, err := http.NewRequest(tt.metodo, servidor.URL+"/ask", strings.NewReader(tt.corpo))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, esperado %d", resp.StatusCode, tt.status)
+			}
+			r := RespostaJson{}
+			if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+				t.Fatal(err)
+			}
+			if r.Answer != tt.resposta || !strings.Contains(r.Error, tt.erro) || (tt.erro == "") != (r.Error == "") {
+				t.Errorf("answer = %q, error = %q; esperado %q e %q", r.Answer, r.Error, tt.resposta, tt.erro)
+			}
+
+			// Cada pergunta é independente: a conversa atual não é alterada e o idioma é o da requisição.
+			if n := len(sessao.conversa.Mensagens()); n != 0 {
+				t.Errorf("conversa atual com %d mensagens, esperado 0", n)
+			}
+			if tt.idioma != "" {
+				msgs := f.Requisicoes()[0].Messages
+				if len(msgs) != 1 || !strings.Contains(msgs[0].Content, `"`+tt.idioma+`"`) {
+					t.Errorf("mensagens enviadas = %+v, esperado uma pergunta no idioma %s", msgs, tt.idioma)
+				}
+			}
+		})
+	}
+}
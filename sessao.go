@@ -0,0 +1,122 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync"
+
+	"gpt-falador/falador"
+)
+
+type (
+	// Estado da sessão do aplicativo: a linha de comando, as configurações, a conversa, a entrada e a
+	// saída, a narração e os modos definidos pelos parâmetros e comandos. É criada pela função main()
+	// (e pelos testes) e repassada aos comandos, parâmetros e subcomandos, que a recebem ao executar.
+	Sessao struct {
+		argumentos []string              // Linha de comando (com o nome do programa), zerada depois de interpretada.
+		conversa   *falador.Conversation // Conversa atual, com o histórico das mensagens trocadas entre o usuário e a IA.
+		entrada    *bufio.Reader         // Entrada de onde são lidas as perguntas no modo interativo.
+		tela       *Renderizador         // Saída do aplicativo (a console, ou a saída de erros no modo --json).
+
+		saidaPadrao io.Writer // Destino do objeto RespostaJson.
+		saidaErros  io.Writer // Destino das demais mensagens, no modo --json.
+		codigoSaida int       // Código de saída do programa (1 se a pergunta falhou, 2 se a linha de comando é inválida).
+
+		interativo    bool   // Se true, executa o GPT no modo interativo (para manter histórico das conversas)
+		encerrada     bool   // Se true, o modo interativo termina (comando "/quit" ou fim da entrada).
+		noSleep       bool   // Se true, imprime o texto sem pausas.
+		printJson     bool   // Se true, imprime o payload retornado pela API.
+		saidaJson     bool   // Se true (--json), imprime somente o objeto RespostaJson.
+		pediuAjuda    bool   // Se true (--help), exibe a ajuda e encerra.
+		modoVoz       bool   // Se true, cada pergunta é ditada pelo microfone em vez de digitada (comando "/listen on").
+		arquivoExport string // Se informado (--export), grava a conversa nesse arquivo após cada resposta.
+		arquivoAudio  string // Se informado (--save-audio), grava a narração da resposta nesse arquivo.
+		nomeTemplate  string // Se informado (--template), monta a pergunta a partir desse template.
+
+		// Arquivo da conversa atual. É definido ao gravar a primeira resposta e zerado no "/reset".
+		arquivoConversa string
+
+		// Endereço onde o subcomando "serve" aguarda as requisições (parâmetro --addr).
+		enderecoServidor string
+
+		// Modo de tradução (subcomando "translate" ou comando "/translate"): os textos digitados são
+		// traduzidos, em vez de enviados à conversa. As traduções não entram no histórico da conversa.
+		modoTraducao bool
+
+		// Idiomas da tradução. Se a origem for vazia, a IA detecta o idioma do texto; se o destino
+		// for vazio, os textos em português são traduzidos para o inglês e os demais para o português.
+		idiomaOrigem, idiomaDestino string
+
+		// Alterações das configurações feitas pelos parâmetros (ex: --model). São reaplicadas
+		// sempre que o arquivo settings.json é recarregado (ex: comando "/reset").
+		sobreposicoes []func(s *Settings)
+
+		// Configurações carregadas do arquivo settings.json, com as alterações dos parâmetros.
+		settings *Settings
+
+		// Configurações como foram carregadas, com as alterações dos parâmetros, mas sem as dos
+		// comandos (ex: "/set"). Ao gravar o arquivo, somente os campos que diferem destas são gravados.
+		carregadas Settings
+
+		// Protege as configurações. Somente a goroutine principal altera as configurações, e o faz com
+		// esta mutex travada para escrita; por isso, ela pode ler os campos de settings diretamente.
+		// As demais goroutines (ex: download dos audios da narração) devem usar a função configuracoes().
+		mutexSettings sync.RWMutex
+
+		controle *ControleNarracao  // Controles da narração em andamento.
+		narracao *ProgressoNarracao // Progresso da narração da resposta atual.
+
+		// Saída de audio, criada na primeira narração com o dispositivo aberto pela função
+		// abreDispositivo (os testes a substituem por um dispositivo nulo). O oto só permite
+		// abrir um dispositivo por processo, por isso a função main() cria uma única sessão.
+		saida           *SaidaAudio
+		erroSaida       error
+		criaSaida       sync.Once
+		abreDispositivo func(fonte io.Reader) (dispositivoAudio, error)
+	}
+)
+
+// Cria a sessão que interpreta os argumentos informados, lê as perguntas da entrada e escreve na saída.
+// Se a saída for a console, usa as capacidades do terminal (cores, largura); caso contrário, escreve sem cores.
+func novaSessao(argumentos []string, entrada io.Reader, saida, erros io.Writer) *Sessao {
+	capacidades := Capacidades{}
+	if f, ok := saida.(*os.File); ok {
+		capacidades = capacidadesDoTerminal(f)
+	}
+	return &Sessao{
+		argumentos:       argumentos,
+		conversa:         falador.NovaConversa(nil, ""),
+		entrada:          bufio.NewReader(entrada),
+		tela:             NovoRenderizador(saida, capacidades),
+		saidaPadrao:      saida,
+		saidaErros:       erros,
+		enderecoServidor: ENDERECO_SERVIDOR,
+		settings:         &Settings{VOLUME: VOLUME_MAXIMO, VELOCIDADE: 1},
+		controle:         &ControleNarracao{volume: VOLUME_MAXIMO, velocidade: 1},
+		narracao:         &ProgressoNarracao{},
+		abreDispositivo:  abreDispositivoDoSistema,
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+const (
+	STT_OPENAI     = "openai"
+	STT_WHISPERCPP = "whispercpp"
+
+	// Modelo usado pela API de transcrição da OpenAI.
+	MODELO_STT_OPENAI = "whisper-1"
+)
+
+type (
+	// Motor de STT (Speech-To-Text): transcreve o audio de um arquivo WAV.
+	MotorSTT interface {
+		// Nome do motor, usado no parâmetro STT_ENGINE.
+		Nome() string
+
+		// Transcreve o audio do arquivo, com as configurações informadas (ex: endereço da API).
+		// O idioma pode ser vazio (detectado pelo motor).
+		Transcreve(cfg Settings, arquivo, idioma string) (string, error)
+	}
+
+	// Transcrição pela API da OpenAI (endpoint /v1/audio/transcriptions, modelo Whisper).
+	MotorWhisperAPI struct{}
+
+	// Transcrição local pelo whisper.cpp: executa o programa informado no parâmetro WHISPER_CPP
+	// com o modelo informado no parâmetro WHISPER_CPP_MODEL.
+	MotorWhisperCpp struct{}
+)
+
+var (
+	// Motores de STT disponíveis, indexados pelo nome.
+	motoresSTT = map[string]MotorSTT{
+		STT_OPENAI:     MotorWhisperAPI{},
+		STT_WHISPERCPP: MotorWhisperCpp{},
+	}
+
+	// Retornado pela gravação do microfone quando o usuário a cancela (tecla ESC).
+	errGravacaoCancelada = errors.New("gravação cancelada")
+)
+
+func init() {
+	registraComando(&Comando{
+		Nome:       "listen",
+		Aliases:    []string{"ouvir"},
+		Argumentos: "[arquivo.wav | on | off]",
+		Descricao: "Dita a pergunta pelo microfone (ENTER envia, ESC cancela) ou transcreve o arquivo WAV.\r\n" +
+			"on/off liga ou desliga o modo de voz: todas as perguntas são ditadas.",
+		MaxArgs: 1,
+		Executa: (*Sessao).trataComandoListen,
+	})
+}
+
+// Trata o comando "/listen". A transcrição é retornada para ser enviada à IA como pergunta.
+func (sessao *Sessao) trataComandoListen(args []string) string {
+	if len(args) == 0 {
+		return sessao.ouvePergunta()
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		sessao.modoVoz = true
+		sessao.tela.Print(traduz("Modo de voz ativado: as perguntas serão ditadas pelo microfone. Tecle ESC para voltar a digitar."))
+		return ""
+	case "off":
+		sessao.modoVoz = false
+		sessao.tela.Print(traduz("Modo de voz desativado."))
+		return ""
+	}
+
+	texto, err := sessao.transcreve(args[0])
+	if err != nil {
+		sessao.tela.Erro(err)
+		return ""
+	}
+	sessao.tela.Printf(traduz("\033[32mTranscrição\033[m: %s\r\n"), texto)
+	return texto
+}
+
+// Grava a pergunta pelo microfone e retorna a transcrição. Antes de gravar, aguarda o fim da
+// narração da resposta anterior, para não gravá-la junto. Se o usuário cancelar (ESC) ou a
+// gravação falhar no modo de voz, o modo de voz é desativado e a pergunta volta a ser digitada.
+func (sessao *Sessao) ouvePergunta() string {
+	// A tecla ESC interrompe a narração, encerrando a espera.
+	sessao.narracao.Aguarda()
+
+	sessao.tela.Print(traduz("\r\n\033[32mOuvindo\033[m (tecle \033[36mENTER\033[m para enviar ou \033[36mESC\033[m para cancelar)..."))
+	wav, err := gravaMicrofone()
+	sessao.tela.Println()
+	if err != nil {
+		if !errors.Is(err, errGravacaoCancelada) {
+			sessao.tela.Erro(err)
+		}
+		if sessao.modoVoz {
+			sessao.modoVoz = false
+			sessao.tela.Println(traduz("Modo de voz desativado."))
+		}
+		return ""
+	}
+
+	temp, err := os.CreateTemp("", "gpt-fala-*.wav")
+	if err != nil {
+		sessao.tela.Erro(err)
+		return ""
+	}
+	defer os.Remove(temp.Name())
+	_, err = temp.Write(wav)
+	temp.Close()
+	if err != nil {
+		sessao.tela.Erro(err)
+		return ""
+	}
+
+	texto, err := sessao.transcreve(temp.Name())
+	if err != nil {
+		sessao.tela.Erro(err)
+		return ""
+	}
+	sessao.tela.Printf(traduz("\033[32mPergunta\033[m: %s\r\n"), texto)
+	return texto
+}
+
+// Transcreve o arquivo WAV com o motor de STT definido no parâmetro STT_ENGINE (padrão: openai).
+func (sessao *Sessao) transcreve(arquivo string) (string, error) {
+	nome := strings.ToLower(sessao.settings.STT_ENGINE)
+	if nome == "" {
+		nome = STT_OPENAI
+	}
+	motor, existe := motoresSTT[nome]
+	if !existe {
+		nomes := make([]string, 0, len(motoresSTT))
+		for n := range motoresSTT {
+			nomes = append(nomes, n)
+		}
+		sort.Strings(nomes)
+		return "", novoErro("motor de STT \"%s\" inexistente. Use: %s", sessao.settings.STT_ENGINE, strings.Join(nomes, ", "))
+	}
+
+	texto, err := motor.Transcreve(sessao.configuracoes(), arquivo, idiomaISO(sessao.settings.IDIOMA))
+	if err != nil {
+		return "", err
+	}
+	if texto = strings.TrimSpace(texto); texto == "" {
+		return "", novoErro("nenhuma fala reconhecida no audio")
+	}
+	return texto, nil
+}
+
+// Retorna o código do idioma sem a região (ex: "pt-BR" -> "pt"), como esperado pelo Whisper.
+// No modo automático, retorna vazio: o idioma é detectado pelo próprio motor.
+func idiomaISO(idioma string) string {
+	if idiomaAutomatico(idioma) {
+		return ""
+	}
+	if i := strings.IndexAny(idioma, "-_"); i > 0 {
+		idioma = idioma[:i]
+	}
+	return strings.ToLower(idioma)
+}
+
+func (MotorWhisperAPI) Nome() string {
+	return STT_OPENAI
+}
+
+// Envia o arquivo para a API de transcrição da OpenAI. O endereço é obtido a partir do
+// parâmetro URL_API, trocando "chat/completions" por "audio/transcriptions".
+func (MotorWhisperAPI) Transcreve(cfg Settings, arquivo, idioma string) (string, error) {
+	audio, err := os.ReadFile(arquivo)
+	if err != nil {
+		return "", err
+	}
+
+	corpo := &bytes.Buffer{}
+	form := multipart.NewWriter(corpo)
+	form.WriteField("model", MODELO_STT_OPENAI)
+	if idioma != "" {
+		form.WriteField("language", idioma)
+	}
+	parte, err := form.CreateFormFile("file", "audio.wav")
+	if err != nil {
+		return "", err
+	}
+	parte.Write(audio)
+	form.Close()
+
+	cliente := novoCliente(cfg)
+	url := cliente.Endereco("audio/transcriptions")
+	req, _ := http.NewRequest(http.MethodPost, url, corpo)
+	req.Header.Add("Content-Type", form.FormDataContentType())
+	cliente.Autoriza(req)
+
+	response, err := cliente.ClienteHTTP().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	retBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var retorno struct {
+		Text  string `json:"text"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err = json.Unmarshal(retBody, &retorno); err != nil {
+		return "", novoErro("falha na transcrição: %s", response.Status)
+	}
+	if retorno.Error.Message != "" {
+		return "", novoErro("falha na transcrição: %s", retorno.Error.Message)
+	}
+	return retorno.Text, nil
+}
+
+func (MotorWhisperCpp) Nome() string {
+	return STT_WHISPERCPP
+}
+
+// Executa o whisper.cpp sem imprimir o progresso (-np) nem os horários (-nt):
+// a saída padrão contém apenas o texto transcrito. O arquivo tem que estar em WAV de 16 kHz.
+func (MotorWhisperCpp) Transcreve(cfg Settings, arquivo, idioma string) (string, error) {
+	if cfg.WHISPER_CPP == "" || cfg.WHISPER_CPP_MODEL == "" {
+		return "", novoErro("informe os parâmetros WHISPER_CPP e WHISPER_CPP_MODEL no arquivo settings.json")
+	}
+
+	args := []string{"-m", cfg.WHISPER_CPP_MODEL, "-np", "-nt", "-f", arquivo}
+	if idioma != "" {
+		args = append(args, "-l", idioma)
+	}
+
+	resultado, err := exec.Command(cfg.WHISPER_CPP, args...).Output()
+	if err != nil {
+		if e, ok := err.(*exec.ExitError); ok && len(e.Stderr) > 0 {
+			return "", novoErro("falha no whisper.cpp: %s", strings.TrimSpace(string(e.Stderr)))
+		}
+		return "", err
+	}
+	return strings.Join(strings.Fields(string(resultado)), " "), nil
+}
@@ -0,0 +1,45 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// A transcrição pela API da OpenAI desiste quando a API não responde no tempo do parâmetro TIMEOUT.
+func TestMotorWhisperAPITimeout(t *testing.T) {
+	sessao := novoServidorLento(t)
+	if err := os.WriteFile("pergunta.wav", []byte("RIFF"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	inicio := time.Now()
+	if _, err := (MotorWhisperAPI{}).Transcreve(sessao.configuracoes(), "pergunta.wav", "pt"); err == nil {
+		t.Fatal("esperado erro quando a API não responde")
+	}
+	if d := time.Since(inicio); d > 10*time.Second {
+		t.Errorf("aguardou %v, esperado o tempo do parâmetro TIMEOUT", d)
+	}
+}
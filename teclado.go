@@ -0,0 +1,46 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+type (
+	// Leitura do estado das teclas, usada para controlar a impressão da resposta e a narração
+	// sem interromper o loop em que estão. A implementação da console depende do sistema
+	// (console_windows.go e console_other.go); os testes usam um teclado simulado.
+	leitorTeclado interface {
+		Pressionada(t int32) bool // Indica se a tecla foi pressionada e liberada.
+		Abaixada(t int32) bool    // Indica se a tecla está pressionada neste momento.
+	}
+)
+
+// Teclado lido pelo aplicativo.
+var teclado leitorTeclado = tecladoConsole{}
+
+// Verifica se pressionou e liberou a tecla informada no parâmetro t.
+func teclaPressionada(t int32) bool {
+	return teclado.Pressionada(t)
+}
+
+// Verifica se a tecla está pressionada neste momento.
+func teclaAbaixada(t int32) bool {
+	return teclado.Abaixada(t)
+}
@@ -0,0 +1,200 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type (
+	// Template de pergunta carregado da pasta ./templates
+	Template struct {
+		Nome      string   // Nome do arquivo, sem a extensão .txt
+		Descricao string   // Linhas iniciadas por "#" no início do arquivo.
+		Texto     string   // Texto da pergunta, com as variáveis no formato {{nome}}.
+		Variaveis []string // Nomes das variáveis encontradas no texto, na ordem em que aparecem.
+	}
+)
+
+const (
+	// Pasta onde ficam os templates de perguntas (um arquivo .txt para cada template).
+	TEMPLATES = "templates"
+
+	// Variável que recebe o texto livre informado junto com o template
+	// (os argumentos que não estão no formato chave=valor).
+	VARIAVEL_TEXTO = "texto"
+)
+
+var (
+	variavelTemplate  = regexp.MustCompile(`{{\s*([A-Za-z0-9_]+)\s*}}`)
+	argumentoVariavel = regexp.MustCompile(`^([A-Za-z0-9_]+)=(.*)$`)
+)
+
+func init() {
+	registraComando(&Comando{
+		Nome:       "t",
+		Aliases:    []string{"template"},
+		Argumentos: "[nome] [chave=valor...]",
+		Descricao: "Pergunta usando um template da pasta ./templates.\r\n" +
+			"Sem argumentos, lista os templates disponíveis.",
+		MaxArgs: -1,
+		Executa: (*Sessao).trataComandoTemplate,
+	})
+}
+
+// Trata o comando "/t <nome> chave=valor..." digitado no modo interativo.
+// Retorna a pergunta montada a partir do template ou "" se houver erro ou se apenas listou os templates.
+func (sessao *Sessao) trataComandoTemplate(args []string) string {
+	if len(args) == 0 {
+		sessao.listaTemplates()
+		return ""
+	}
+
+	pergunta, err := aplicaTemplate(args[0], args[1:])
+	if err != nil {
+		sessao.tela.Erro(err)
+		return ""
+	}
+	return pergunta
+}
+
+// Carrega o template e substitui as variáveis pelos argumentos informados.
+// Argumentos no formato chave=valor preenchem a variável {{chave}}; os demais são unidos
+// e preenchem a variável {{texto}}. Se o valor começar com "@", é substituído pelo
+// conteúdo do arquivo indicado (ex: codigo=@main.go).
+func aplicaTemplate(nome string, args []string) (string, error) {
+	t, err := carregaTemplate(nome)
+	if err != nil {
+		return "", err
+	}
+
+	valores := make(map[string]string)
+	texto := make([]string, 0)
+
+	for _, arg := range args {
+		m := argumentoVariavel.FindStringSubmatch(arg)
+		if m == nil {
+			texto = append(texto, arg)
+			continue
+		}
+
+		valor := m[2]
+		if strings.HasPrefix(valor, "@") {
+			conteudo, err := os.ReadFile(valor[1:])
+			if err != nil {
+				return "", err
+			}
+			valor = string(conteudo)
+		}
+		valores[strings.ToLower(m[1])] = valor
+	}
+
+	if len(texto) > 0 {
+		valores[VARIAVEL_TEXTO] = strings.Join(texto, " ")
+	}
+
+	return t.Renderiza(valores)
+}
+
+// Substitui as variáveis {{nome}} do template pelos valores informados.
+// Se faltar o valor de alguma variável, retorna erro com a lista das que faltam.
+func (t *Template) Renderiza(valores map[string]string) (string, error) {
+	faltando := make([]string, 0)
+	for _, v := range t.Variaveis {
+		if _, ok := valores[v]; !ok {
+			faltando = append(faltando, v)
+		}
+	}
+
+	if len(faltando) > 0 {
+		return "", novoErro("template \"%s\": informe o valor de %s", t.Nome, strings.Join(faltando, ", "))
+	}
+
+	return variavelTemplate.ReplaceAllStringFunc(t.Texto, func(s string) string {
+		return valores[strings.ToLower(variavelTemplate.FindStringSubmatch(s)[1])]
+	}), nil
+}
+
+// Lê o arquivo ./templates/<nome>.txt
+// As linhas iniciadas por "#" no começo do arquivo são a descrição do template.
+func carregaTemplate(nome string) (*Template, error) {
+	if strings.ContainsAny(nome, `/\.`) {
+		return nil, novoErro("nome de template \"%s\" inválido", nome)
+	}
+
+	conteudo, err := os.ReadFile(filepath.Join(TEMPLATES, nome+".txt"))
+	if os.IsNotExist(err) {
+		return nil, novoErro("template \"%s\" não encontrado na pasta ./%s", nome, TEMPLATES)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Template{Nome: nome}
+
+	linhas := strings.Split(strings.ReplaceAll(string(conteudo), "\r\n", "\n"), "\n")
+	descricao := make([]string, 0)
+	for len(linhas) > 0 && strings.HasPrefix(linhas[0], "#") {
+		descricao = append(descricao, strings.TrimSpace(strings.TrimLeft(linhas[0], "#")))
+		linhas = linhas[1:]
+	}
+
+	t.Descricao = strings.Join(descricao, " ")
+	t.Texto = strings.TrimSpace(strings.Join(linhas, "\n"))
+
+	// Guarda o nome das variáveis sem repetição, na ordem em que aparecem no texto.
+	for _, m := range variavelTemplate.FindAllStringSubmatch(t.Texto, -1) {
+		v := strings.ToLower(m[1])
+		if !contem(t.Variaveis, v) {
+			t.Variaveis = append(t.Variaveis, v)
+		}
+	}
+
+	return t, nil
+}
+
+// Imprime os templates disponíveis na pasta ./templates com a descrição e as variáveis de cada um.
+func (sessao *Sessao) listaTemplates() {
+	arquivos, _ := filepath.Glob(filepath.Join(TEMPLATES, "*.txt"))
+	if len(arquivos) == 0 {
+		sessao.tela.Printf(traduz("Nenhum template encontrado na pasta ./%s\r\n"), TEMPLATES)
+		return
+	}
+
+	sort.Strings(arquivos)
+	sessao.tela.Println(traduz("Templates disponíveis:"))
+	for _, arquivo := range arquivos {
+		t, err := carregaTemplate(strings.TrimSuffix(filepath.Base(arquivo), ".txt"))
+		if err != nil {
+			continue
+		}
+		sessao.tela.Printf("\t\033[36m%s\033[m %s\r\n", t.Nome, t.Descricao)
+		if len(t.Variaveis) > 0 {
+			sessao.tela.Printf(traduz("\t  Variáveis: %s\r\n"), strings.Join(t.Variaveis, ", "))
+		}
+	}
+}
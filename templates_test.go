@@ -0,0 +1,98 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Os argumentos são separados da linha digitada, como no comando "/t <nome> <argumentos>".
+func TestAplicaTemplate(t *testing.T) {
+	testes := []struct {
+		nome     string
+		template string
+		linha    string
+		esperado string
+		erro     string // Trecho esperado na mensagem de erro; vazio se não deve haver erro.
+	}{
+		{"texto livre", "Traduza: {{texto}}", "bom dia", "Traduza: bom dia", ""},
+		{"chave=valor e texto", "Traduza para {{idioma}}: {{texto}}", "idioma=inglês bom dia", "Traduza para inglês: bom dia", ""},
+		{"valor entre aspas", "Traduza para {{idioma}}: {{texto}}", `idioma="inglês britânico" texto='bom dia'`, "Traduza para inglês britânico: bom dia", ""},
+		{"aspas sem fechar", "Traduza: {{texto}}", `texto="bom dia, tudo bem`, "Traduza: bom dia, tudo bem", ""},
+		{"apóstrofo no texto", "Traduza: {{texto}}", "copo d'água", "Traduza: copo d'água", ""},
+		{"caixa e espaços na variável", "{{ Idioma }} / {{IDIOMA}}", "IDIOMA=pt", "pt / pt", ""},
+		{"variável repetida", "{{x}} e {{x}}", "x=1", "1 e 1", ""},
+		{"valor com =", "Calcule {{expr}}", `expr="a=b+1"`, "Calcule a=b+1", ""},
+		{"valor vazio", "[{{opcional}}]", "opcional=", "[]", ""},
+		{"chave a mais é ignorada", "Oi {{texto}}", "extra=1 mundo", "Oi mundo", ""},
+		{"texto sem variável texto", "Sem variáveis", "algo", "Sem variáveis", ""},
+		{"descrição não faz parte", "# Traduz o texto\n# para o idioma\n{{texto}}", "olá", "olá", ""},
+		{"conteúdo de arquivo", "Revise:\n{{codigo}}", "codigo=@codigo.go", "Revise:\npackage main", ""},
+		{"falta uma variável", "Traduza para {{idioma}}: {{texto}}", "bom dia", "", "informe o valor de idioma"},
+		{"faltam várias", "{{idioma}} {{tom}} {{texto}}", "", "", "informe o valor de idioma, tom, texto"},
+		{"chave com outro nome", "{{idioma}}", "lingua=en", "", "informe o valor de idioma"},
+		{"arquivo inexistente", "{{codigo}}", "codigo=@nao_existe.go", "", "nao_existe.go"},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			configuraTeste(t)
+			if err := os.Mkdir(TEMPLATES, 0700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(TEMPLATES, "teste.txt"), []byte(tt.template), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile("codigo.go", []byte("package main"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			pergunta, err := aplicaTemplate("teste", separaArgumentos(tt.linha))
+			if tt.erro != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.erro) {
+					t.Errorf("erro = %v, esperado contendo %q", err, tt.erro)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if pergunta != tt.esperado {
+				t.Errorf("pergunta = %q, esperado %q", pergunta, tt.esperado)
+			}
+		})
+	}
+}
+
+func TestCarregaTemplateInvalido(t *testing.T) {
+	configuraTeste(t)
+
+	for _, nome := range []string{"../settings", "sub/teste", `sub\teste`, "teste.txt", "inexistente"} {
+		if _, err := carregaTemplate(nome); err == nil {
+			t.Errorf("carregaTemplate(%q): esperado erro", nome)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	// Capacidades da saída onde o aplicativo escreve.
+	Capacidades struct {
+		Cores   bool // Se true, mantém as cores (Escape Codes). Caso contrário, as cores são removidas.
+		Largura int  // Largura da tela, em colunas. Se 0, é desconhecida.
+
+		// Se true, a saída é um terminal: a resposta é impressa com pausas e o indicador de
+		// atividade ("pensando") é exibido. Se false (ex: saída redirecionada para um arquivo),
+		// a resposta é impressa de uma só vez.
+		Interativo bool
+	}
+
+	// Saída do aplicativo: as respostas, mensagens e erros são escritos no io.Writer informado,
+	// conforme as capacidades do mesmo. As escritas são protegidas por mutex, pois a narração
+	// (em outra goroutine) também informa os seus erros.
+	Renderizador struct {
+		Capacidades
+
+		mutex sync.Mutex
+		w     io.Writer
+	}
+)
+
+const (
+	// Largura usada quando a largura da tela é desconhecida.
+	LARGURA_PADRAO = 80
+)
+
+// Escape Codes das cores (ex: "\033[96m"), removidos quando a saída não tem cores.
+var codigoDeCor = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// Cria o renderizador que escreve no io.Writer, com as capacidades informadas.
+func NovoRenderizador(w io.Writer, c Capacidades) *Renderizador {
+	return &Renderizador{Capacidades: c, w: w}
+}
+
+// Escreve o texto, removendo as cores se a saída não as suporta.
+func (r *Renderizador) Write(p []byte) (int, error) {
+	defer r.mutex.Unlock()
+	r.mutex.Lock()
+
+	if r.Cores {
+		return r.w.Write(p)
+	}
+	if _, err := r.w.Write(codigoDeCor.ReplaceAll(p, nil)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (r *Renderizador) Print(a ...interface{}) {
+	fmt.Fprint(r, a...)
+}
+
+func (r *Renderizador) Printf(format string, a ...interface{}) {
+	fmt.Fprintf(r, format, a...)
+}
+
+func (r *Renderizador) Println(a ...interface{}) {
+	fmt.Fprintln(r, a...)
+}
+
+// Imprime a mensagem de erro em vermelho.
+func (r *Renderizador) Erro(err error) {
+	r.Println("\033[31m", descricaoDoErro(err), "\033[m")
+}
+
+// Imprime uma linha separadora com o tamanho informado, limitada à largura da tela.
+func (r *Renderizador) Separador(tamanho int) {
+	if colunas := r.Colunas() - 1; tamanho > colunas {
+		tamanho = colunas
+	}
+	r.Println(strings.Repeat("-", tamanho))
+}
+
+// Retorna a largura da tela ou, se for desconhecida, a largura padrão.
+func (r *Renderizador) Colunas() int {
+	if r.Largura > 0 {
+		return r.Largura
+	}
+	return LARGURA_PADRAO
+}
+
+// Obtém as capacidades a partir das variáveis de ambiente, quando não podem ser obtidas
+// do terminal: NO_COLOR desativa as cores (https://no-color.org) e COLUMNS informa a largura.
+func capacidadesDoAmbiente(c Capacidades) Capacidades {
+	if _, existe := os.LookupEnv("NO_COLOR"); existe {
+		c.Cores = false
+	}
+	if c.Largura == 0 {
+		c.Largura, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	}
+	return c
+}
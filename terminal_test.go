@@ -0,0 +1,149 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Use "go test -run Golden -atualiza" para regravar os arquivos .golden após alterar a impressão.
+var atualiza = flag.Bool("atualiza", false, "regrava os arquivos .golden da pasta testdata")
+
+// Compara o texto com o conteúdo do arquivo .golden da pasta testdata.
+func comparaGolden(t *testing.T, nome, texto string) {
+	t.Helper()
+
+	caminho := filepath.Join(pastaTestdata, nome+".golden")
+	if *atualiza {
+		if err := os.WriteFile(caminho, []byte(texto), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	esperado, err := os.ReadFile(caminho)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if texto != string(esperado) {
+		t.Errorf("saída difere de %s:\n%q\nesperado:\n%q", caminho, texto, esperado)
+	}
+}
+
+// Caminho absoluto da pasta testdata, pois os testes mudam a pasta atual (configuraTeste).
+var pastaTestdata, _ = filepath.Abs("testdata")
+
+func TestImprimeRespostaGolden(t *testing.T) {
+	resposta, err := os.ReadFile(filepath.Join(pastaTestdata, "resposta.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testes := []struct {
+		nome        string
+		capacidades Capacidades
+	}{
+		{"resposta_cores", Capacidades{Cores: true, Largura: 80, Interativo: true}},
+		{"resposta_sem_cores", Capacidades{Largura: 80, Interativo: true}},
+		// Fora do terminal, a resposta é impressa de uma só vez, mesmo com pausas configuradas.
+		{"resposta_redirecionada", Capacidades{}},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.nome, func(t *testing.T) {
+			sessao := configuraTeste(t)
+			novoTecladoFake(t, sessao)
+			sessao.noSleep = tt.capacidades.Interativo
+
+			saida := capturaSaidaCom(t, sessao, tt.capacidades, func() { sessao.imprimeResposta(string(resposta), sessao.settings.IDIOMA) })
+			comparaGolden(t, tt.nome, saida)
+		})
+	}
+}
+
+func TestRenderizadorRemoveCores(t *testing.T) {
+	testes := []struct {
+		cores bool
+		saida string
+	}{
+		{true, "\033[31m erro \033[m\n\033[96mazul\033[m e normal\n"},
+		{false, " erro \nazul e normal\n"},
+	}
+
+	for _, tt := range testes {
+		buf := &bytes.Buffer{}
+		r := NovoRenderizador(buf, Capacidades{Cores: tt.cores})
+		r.Erro(errors.New("erro"))
+		r.Printf("\033[96m%s\033[m e %s\n", "azul", "normal")
+
+		if buf.String() != tt.saida {
+			t.Errorf("cores=%v: saída = %q, esperado %q", tt.cores, buf.String(), tt.saida)
+		}
+	}
+}
+
+func TestRenderizadorSeparador(t *testing.T) {
+	testes := []struct {
+		largura int
+		tamanho int
+	}{
+		{0, 51}, // Largura desconhecida: usa LARGURA_PADRAO.
+		{120, 51},
+		{30, 29}, // Limitado à largura, sem ocupar a última coluna.
+	}
+
+	for _, tt := range testes {
+		buf := &bytes.Buffer{}
+		NovoRenderizador(buf, Capacidades{Largura: tt.largura}).Separador(51)
+		if got := strings.TrimSuffix(buf.String(), "\n"); got != strings.Repeat("-", tt.tamanho) {
+			t.Errorf("largura %d: separador com %d caracteres, esperado %d", tt.largura, len(got), tt.tamanho)
+		}
+	}
+}
+
+// As escritas de várias goroutines não se misturam (execute com -race).
+func TestRenderizadorConcorrente(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := NovoRenderizador(buf, Capacidades{})
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Println("\033[93mlinha\033[m")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := strings.Count(buf.String(), "linha\n"); got != 1000 {
+		t.Errorf("%d linhas escritas, esperado 1000", got)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"context"
+	"strings"
+
+	"gpt-falador/falador"
+)
+
+func init() {
+	registraOpcao(&Opcao{
+		Nome:       "from",
+		Argumento:  "<idioma>",
+		Descricao:  "Idioma do texto do subcomando \033[36mtranslate\033[m (padrão: detectado pela IA).",
+		Subcomando: "translate",
+		Define: func(sessao *Sessao, valor string) error {
+			sessao.idiomaOrigem = idiomaDaTraducao(valor)
+			return nil
+		},
+	})
+	registraOpcao(&Opcao{
+		Nome:      "to",
+		Argumento: "<idioma>",
+		Descricao: "Idioma da tradução do subcomando \033[36mtranslate\033[m (padrão: inglês para os textos\n" +
+			"em português e português para os demais).",
+		Subcomando: "translate",
+		Define: func(sessao *Sessao, valor string) error {
+			sessao.idiomaDestino = idiomaDaTraducao(valor)
+			return nil
+		},
+	})
+
+	registraSubcomando(&Subcomando{
+		Nome:       "translate",
+		Argumentos: "[texto]",
+		Descricao: "Traduz o texto e narra a tradução no idioma de destino. Sem o texto, inicia o modo\n" +
+			"interativo de tradução (ex: \033[36mgpt translate --from pt-br --to en-us Bom dia!\033[m).",
+		Executa: func(sessao *Sessao, args []string) (string, error) {
+			sessao.modoTraducao = true
+			texto, err := sessao.montaPergunta(args)
+			if err != nil {
+				return "", err
+			}
+			if texto == "" && sessao.saidaJson {
+				return "", novoErro("informe o texto a traduzir (ex: gpt translate --to en-us \"Bom dia!\")")
+			}
+			sessao.interativo = texto == ""
+			return texto, nil
+		},
+	})
+
+	registraComando(&Comando{
+		Nome:       "translate",
+		Aliases:    []string{"traduzir"},
+		Argumentos: "[origem] <destino> | on | off",
+		Descricao: "Ativa o modo de tradução: os textos digitados são traduzidos, sem entrar no histórico,\r\n" +
+			"e a tradução é narrada no idioma de destino. off volta à conversa.\r\n" +
+			"Exemplo: /translate pt-br en-us",
+		MaxArgs: 2,
+		Executa: (*Sessao).trataComandoTranslate,
+	})
+}
+
+// Trata o comando "/translate". Sem argumentos (ou com "on"), ativa o modo de tradução com os
+// idiomas atuais; com um idioma, define o destino (a origem é detectada); com dois, a origem e o destino.
+func (sessao *Sessao) trataComandoTranslate(args []string) string {
+	switch {
+	case len(args) == 1 && strings.EqualFold(args[0], "off"):
+		sessao.modoTraducao = false
+		sessao.tela.Print(traduz("Modo de tradução desativado. Os textos voltam a ser enviados à conversa."))
+		return ""
+	case len(args) == 1 && !strings.EqualFold(args[0], "on"):
+		sessao.idiomaOrigem, sessao.idiomaDestino = "", idiomaDaTraducao(args[0])
+	case len(args) == 2:
+		sessao.idiomaOrigem, sessao.idiomaDestino = idiomaDaTraducao(args[0]), idiomaDaTraducao(args[1])
+	}
+
+	sessao.modoTraducao = true
+	sessao.tela.Printf(traduz("Modo de tradução ativado (%s → %s). Digite \033[36m/translate off\033[m para voltar à conversa."),
+		descreveIdiomaTraducao(sessao.idiomaOrigem), descreveIdiomaTraducao(sessao.idiomaDestino))
+	return ""
+}
+
+// Idioma informado para a tradução. "auto" equivale a vazio (idioma detectado ou padrão).
+func idiomaDaTraducao(valor string) string {
+	if idiomaAutomatico(valor) {
+		return ""
+	}
+	return strings.TrimSpace(valor)
+}
+
+// Idioma da tradução exibido ao usuário: "auto" se não foi informado.
+func descreveIdiomaTraducao(idioma string) string {
+	if idioma == "" {
+		return IDIOMA_AUTOMATICO
+	}
+	return idioma
+}
+
+// Idiomas de origem e de destino da tradução do texto. Sem o destino, traduz entre o português
+// e o inglês: o texto em português (informado na origem ou detectado) é traduzido para o inglês,
+// e os demais, para o português.
+func (sessao *Sessao) idiomasDaTraducao(texto string) (origem, destino string) {
+	origem, destino = sessao.idiomaOrigem, sessao.idiomaDestino
+	if destino != "" {
+		return origem, destino
+	}
+
+	detectado := origem
+	if detectado == "" {
+		detectado = detectaIdioma(texto)
+	}
+	if strings.HasPrefix(strings.ToLower(detectado), "pt") {
+		return origem, IDIOMA_INGLES
+	}
+	return origem, IDIOMA_PORTUGUES
+}
+
+// Envia o texto para ser traduzido, sem o histórico da conversa. Retorna também o idioma de destino.
+func (sessao *Sessao) traduzTexto(texto string) (*falador.Resposta, string, error) {
+	if texto == "" {
+		return nil, "", novoErro("nenhum texto informado para traduzir")
+	}
+	origem, destino := sessao.idiomasDaTraducao(texto)
+	resposta, err := novoCliente(sessao.configuracoes()).Traduz(context.Background(), texto, origem, destino)
+	return resposta, destino, err
+}
+
+// Traduz o texto, imprime e narra a tradução no idioma de destino.
+// Retorna false se não obteve a tradução.
+func (sessao *Sessao) respondeTraducao(texto string) bool {
+	terminaPensando := sessao.iniciaPensando()
+	resposta, destino, err := sessao.traduzTexto(texto)
+	terminaPensando()
+	if sessao.trataResposta(resposta, err) == nil {
+		return false
+	}
+	sessao.tela.Printf(traduz("\r\033[94m        \rTradução\033[m (%s): "), destino)
+
+	sessao.imprimeResposta(resposta.Mensagem.Content, destino)
+
+	sessao.tela.Println()
+
+	// Se o parâmetro --save-audio foi informado, grava a narração da tradução no arquivo.
+	if sessao.arquivoAudio != "" {
+		if err := sessao.gravaNarracaoDoTexto(resposta.Mensagem.Content, destino, sessao.arquivoAudio); err != nil {
+			sessao.tela.Erro(err)
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdiomasDaTraducao(t *testing.T) {
+	tests := []struct {
+		nome                   string
+		origem, destino, texto string
+		esperadoOrigem         string
+		esperadoDestino        string
+	}{
+		{"idiomas informados", "pt-br", "es", "Bom dia", "pt-br", "es"},
+		{"português sem destino vai para o inglês", "", "", "Qual é o seu nome?", "", IDIOMA_INGLES},
+		{"inglês sem destino vai para o português", "", "", "What is your name?", "", IDIOMA_PORTUGUES},
+		{"origem informada decide o destino", "pt", "", "OK", "pt", IDIOMA_INGLES},
+		{"idioma não detectado vai para o português", "", "", "OK", "", IDIOMA_PORTUGUES},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nome, func(t *testing.T) {
+			sessao := configuraTeste(t)
+			sessao.idiomaOrigem, sessao.idiomaDestino = tt.origem, tt.destino
+
+			origem, destino := sessao.idiomasDaTraducao(tt.texto)
+			if origem != tt.esperadoOrigem || destino != tt.esperadoDestino {
+				t.Errorf("idiomasDaTraducao(%q) = %q, %q, esperado %q, %q", tt.texto, origem, destino, tt.esperadoOrigem, tt.esperadoDestino)
+			}
+		})
+	}
+}
+
+// Modo interativo de tradução: cada texto é traduzido sem o histórico e narrado no idioma de destino.
+// Após "/translate off", as perguntas voltam à conversa, que não contém as traduções.
+func TestModoTraducao(t *testing.T) {
+	f, sessao := novoServidorFake(t)
+	f.Resposta = "Good morning."
+	f.Audio = mp3Silencioso(4)
+	sessao.settings.TTS = true
+
+	sessao.argumentos = []string{"gpt", "translate", "--from", "pt-br", "--to", "en-us"}
+	entradaTeste(sessao,
+		"Bom dia",
+		"Boa noite",
+		"/translate off",
+		"Qual é a capital do Brasil?",
+		"/quit",
+	)
+
+	terminou := make(chan string)
+	go func() {
+		terminou <- capturaSaida(t, sessao, sessao.executaSessao)
+	}()
+
+	var saida string
+	select {
+	case saida = <-terminou:
+	case <-time.After(20 * time.Second):
+		t.Fatal("a sessão não terminou")
+	}
+
+	for _, esperado := range []string{"Traduzir\033[m (pt-br → en-us)", "Tradução\033[m (en-us)", "Modo de tradução desativado"} {
+		if !strings.Contains(saida, esperado) {
+			t.Errorf("saída não contém %q:\n%s", esperado, saida)
+		}
+	}
+
+	requisicoes := f.Requisicoes()
+	if len(requisicoes) != 3 {
+		t.Fatalf("API acionada %d vezes, esperado 3", len(requisicoes))
+	}
+	for i, texto := range []string{"Bom dia", "Boa noite"} {
+		mensagens := requisicoes[i].Messages
+		if len(mensagens) != 2 || mensagens[0].Role != "system" || mensagens[1].Content != texto {
+			t.Errorf("tradução %d enviada com %+v, esperado a instrução e %q", i+1, mensagens, texto)
+			continue
+		}
+		if !strings.Contains(mensagens[0].Content, `from "pt-br" to "en-us"`) {
+			t.Errorf("instrução da tradução = %q", mensagens[0].Content)
+		}
+	}
+	if n := len(requisicoes[2].Messages); n != 1 {
+		t.Errorf("pergunta enviada com %d mensagens, esperado 1 (sem as traduções)", n)
+	}
+	if n := len(sessao.conversa.Mensagens()); n != 2 {
+		t.Errorf("histórico com %d mensagens, esperado 2", n)
+	}
+
+	// As traduções são narradas no idioma de destino; a resposta da conversa, no parâmetro IDIOMA.
+	idiomas := make(map[string]int)
+	for _, c := range f.Consultas() {
+		idiomas[strings.ToLower(c.Get("tl"))]++
+	}
+	if idiomas["en-us"] != 2 || idiomas["pt-br"] != 1 {
+		t.Errorf("idiomas narrados = %v, esperado 2 em en-us e 1 em pt-br", idiomas)
+	}
+}
+
+func TestComandoTranslate(t *testing.T) {
+	tests := []struct {
+		args            []string
+		ativo           bool
+		origem, destino string
+	}{
+		{[]string{}, true, "", ""},
+		{[]string{"en-us"}, true, "", "en-us"},
+		{[]string{"pt-br", "EN"}, true, "pt-br", "EN"},
+		{[]string{"auto", "es"}, true, "", "es"},
+		{[]string{"off"}, false, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			sessao := configuraTeste(t)
+			capturaSaida(t, sessao, func() { sessao.trataComandoTranslate(tt.args) })
+
+			if sessao.modoTraducao != tt.ativo || sessao.idiomaOrigem != tt.origem || sessao.idiomaDestino != tt.destino {
+				t.Errorf("modo = %v, origem = %q, destino = %q; esperado %v, %q, %q",
+					sessao.modoTraducao, sessao.idiomaOrigem, sessao.idiomaDestino, tt.ativo, tt.origem, tt.destino)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type (
+	// Motor de TTS (Text-To-Speech): converte um trecho de texto em audio MP3.
+	MotorTTS interface {
+		// Nome do motor, usado no parâmetro TTS_ENGINE e na chave do cache de audios.
+		Nome() string
+
+		// Vozes disponíveis no motor.
+		Vozes() []Voz
+
+		// Voz usada quando nenhuma voz foi configurada para o idioma.
+		VozPadrao(idioma string) string
+
+		// Gera o audio do texto no idioma e voz informados, com as configurações informadas
+		// (ex: endereço da API). O chamador fecha o retorno.
+		Sintetiza(cfg Settings, texto, idioma, voz string) (io.ReadCloser, error)
+	}
+
+	// Voz disponível em um motor de TTS.
+	Voz struct {
+		Nome      string
+		Descricao string
+	}
+)
+
+// Motores de TTS disponíveis, indexados pelo nome.
+var motoresTTS = make(map[string]MotorTTS)
+
+func init() {
+	registraComando(&Comando{
+		Nome:      "voices",
+		Aliases:   []string{"vozes"},
+		Descricao: "Lista as vozes disponíveis no motor de TTS ativo (parâmetro tts_engine).",
+		Executa:   (*Sessao).trataComandoVoices,
+	})
+}
+
+// Adiciona o motor à lista de motores de TTS disponíveis.
+func registraMotorTTS(m MotorTTS) {
+	motoresTTS[strings.ToLower(m.Nome())] = m
+}
+
+// Retorna o motor de TTS definido no parâmetro TTS_ENGINE. Se não estiver definido, usa o Google.
+func (sessao *Sessao) motorAtivo() (MotorTTS, error) {
+	motor := sessao.configuracoes().TTS_ENGINE
+	nome := strings.ToLower(motor)
+	if nome == "" {
+		nome = MOTOR_GOOGLE
+	}
+	m, existe := motoresTTS[nome]
+	if !existe {
+		return nil, novoErro("motor de TTS \"%s\" inexistente. Use: %s", motor, strings.Join(nomesMotores(), ", "))
+	}
+	return m, nil
+}
+
+// Nomes dos motores de TTS disponíveis, em ordem alfabética.
+func nomesMotores() []string {
+	nomes := make([]string, 0, len(motoresTTS))
+	for nome := range motoresTTS {
+		nomes = append(nomes, nome)
+	}
+	sort.Strings(nomes)
+	return nomes
+}
+
+// Define a voz usada pelo motor para o idioma, nesta ordem:
+// 1 - O parâmetro VOICE, se informado.
+// 2 - A voz mapeada para o idioma no parâmetro VOZES (ex: "pt-br"), ou para o idioma sem a região (ex: "pt").
+// 3 - A voz padrão do motor para o idioma.
+func (sessao *Sessao) vozDoIdioma(m MotorTTS, idioma string) string {
+	cfg := sessao.configuracoes()
+	if cfg.VOICE != "" {
+		return cfg.VOICE
+	}
+
+	idioma = strings.ToLower(idioma)
+	vozes := make(map[string]string)
+	for i, v := range cfg.VOZES[strings.ToLower(m.Nome())] {
+		vozes[strings.ToLower(i)] = v
+	}
+	if v, existe := vozes[idioma]; existe {
+		return v
+	}
+	if i := strings.IndexAny(idioma, "-_"); i > 0 {
+		if v, existe := vozes[idioma[:i]]; existe {
+			return v
+		}
+	}
+	return m.VozPadrao(idioma)
+}
+
+// Trata o comando "/voices": lista as vozes do motor ativo, destacando a usada no idioma atual.
+func (sessao *Sessao) trataComandoVoices(args []string) string {
+	m, err := sessao.motorAtivo()
+	if err != nil {
+		sessao.tela.Erro(err)
+		return ""
+	}
+
+	atual := sessao.vozDoIdioma(m, idiomaPadrao(sessao.settings.IDIOMA))
+	sessao.tela.Printf(traduz("Vozes do motor \033[96m%s\033[m (em uso: \033[96m%s\033[m):\r\n"), m.Nome(), atual)
+	for _, v := range m.Vozes() {
+		marcador := " "
+		if strings.EqualFold(v.Nome, atual) {
+			marcador = "*"
+		}
+		sessao.tela.Printf(" %s \033[36m%-10s\033[m %s\r\n", marcador, v.Nome, traduz(v.Descricao))
+	}
+	sessao.tela.Print(traduz("Use \033[96m/set voice=<voz>\033[m para escolher a voz, ou o parâmetro VOZES do settings.json para definir a voz de cada idioma."))
+	return ""
+}
+
+// Envia o bloco de texto para o motor de TTS ativo, para converter em audio.
+// Se o cache estiver ativo e o audio já tiver sido baixado antes, usa o arquivo do cache.
+// O idioma e a voz fazem parte da chave do cache, junto com o nome do motor.
+func (sessao *Sessao) baixaAudio(wg *sync.WaitGroup, downloadedAudio *DownloadedAudio) {
+	defer wg.Done()
+	defer close(downloadedAudio.Pronto)
+
+	motor, err := sessao.motorAtivo()
+	if err != nil {
+		downloadedAudio.Erro = err
+		sessao.tela.Erro(err)
+		return
+	}
+	idioma := downloadedAudio.Idioma
+	if idioma == "" {
+		idioma = idiomaPadrao(sessao.configuracoes().IDIOMA)
+	}
+	voz := sessao.vozDoIdioma(motor, idioma)
+
+	if sessao.cacheAtivo() {
+		downloadedAudio.Path = arquivoCache(downloadedAudio.Texto, idioma, motor.Nome(), voz)
+		if buscaNoCache(downloadedAudio.Path) {
+			return
+		}
+	}
+
+	// Cria a pasta de destino dos audios baixados.
+	dir, err := os.Open("./audio")
+	if os.IsNotExist(err) {
+		os.MkdirAll("./audio", 0700)
+	}
+
+	dir.Close()
+
+	audio, err := motor.Sintetiza(sessao.configuracoes(), downloadedAudio.Texto, idioma, voz)
+	if err != nil {
+		downloadedAudio.Erro = err
+		sessao.tela.Erro(err)
+		return
+	}
+	defer audio.Close()
+
+	if sessao.cacheAtivo() {
+		if err = gravaNoCache(downloadedAudio.Path, audio); err != nil {
+			downloadedAudio.Erro = err
+			sessao.tela.Erro(err)
+		}
+		return
+	}
+
+	// Cria o arquivo de destino do audio baixado.
+	output, err := os.Create(downloadedAudio.Path)
+	if err != nil {
+		downloadedAudio.Erro = err
+		sessao.tela.Erro(err)
+		return
+	}
+	defer output.Close()
+
+	// Copia o conteúdo baixado para o arquivo de destino.
+	_, err = io.Copy(output, audio)
+	if err != nil {
+		downloadedAudio.Erro = err
+		sessao.tela.Erro(err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Baixa o audio do texto pelo motor de TTS ativo, como feito na narração.
+func baixaAudioTeste(t *testing.T, sessao *Sessao, texto string) *DownloadedAudio {
+	t.Helper()
+
+	a := &DownloadedAudio{
+		Path:   filepath.Join(t.TempDir(), "0.mp3"),
+		Texto:  texto,
+		Pronto: make(chan struct{}),
+	}
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	sessao.baixaAudio(wg, a)
+	wg.Wait()
+	return a
+}
+
+func TestMotorGoogleUsaTTS_URL(t *testing.T) {
+	f, sessao := novoServidorFake(t)
+
+	a := baixaAudioTeste(t, sessao, "Olá, mundo!")
+	if a.Erro != nil {
+		t.Fatalf("erro inesperado: %v", a.Erro)
+	}
+
+	conteudo, err := os.ReadFile(a.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(conteudo, f.Audio) {
+		t.Errorf("audio gravado = %q, esperado %q", conteudo, f.Audio)
+	}
+
+	consultas := f.Consultas()
+	if len(consultas) != 1 {
+		t.Fatalf("TTS acionado %d vezes, esperado 1", len(consultas))
+	}
+	if q := consultas[0].Get("q"); q != "Olá, mundo!" {
+		t.Errorf("q = %q, esperado %q", q, "Olá, mundo!")
+	}
+	// O Google não diferencia maiúsculas de minúsculas no idioma.
+	if tl := consultas[0].Get("tl"); !strings.EqualFold(tl, "pt-BR") {
+		t.Errorf("tl = %q, esperado %q", tl, "pt-BR")
+	}
+}
+
+// Transporte HTTP que registra o endereço acessado e responde sem acessar a rede.
+type transporteFake struct {
+	url string
+}
+
+func (f *transporteFake) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.url = r.URL.String()
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("audio")), Request: r}, nil
+}
+
+func TestMotorGoogleURLPadrao(t *testing.T) {
+	sessao := configuraTeste(t)
+
+	// Sem o parâmetro TTS_URL, usa o endereço do Google com HTTPS.
+	transporte := &transporteFake{}
+	original := http.DefaultTransport
+	http.DefaultTransport = transporte
+	t.Cleanup(func() { http.DefaultTransport = original })
+
+	if a := baixaAudioTeste(t, sessao, "Oi"); a.Erro != nil {
+		t.Fatalf("erro inesperado: %v", a.Erro)
+	}
+	if !strings.HasPrefix(transporte.url, "https://translate.google.com/translate_tts?") {
+		t.Errorf("endereço acessado = %q, esperado o TTS do Google com HTTPS", transporte.url)
+	}
+}
+
+func TestMotorGoogleVozDoIdioma(t *testing.T) {
+	f, sessao := novoServidorFake(t)
+	sessao.settings.VOZES = map[string]map[string]string{MOTOR_GOOGLE: {"pt": "pt-PT"}}
+
+	if a := baixaAudioTeste(t, sessao, "Bom dia"); a.Erro != nil {
+		t.Fatalf("erro inesperado: %v", a.Erro)
+	}
+	if tl := f.Consultas()[0].Get("tl"); tl != "pt-PT" {
+		t.Errorf("tl = %q, esperado a voz mapeada para o idioma (pt-PT)", tl)
+	}
+}
+
+func TestMotorGoogleErroHTTP(t *testing.T) {
+	f, sessao := novoServidorFake(t)
+	f.StatusTTS = http.StatusServiceUnavailable
+	sessao.settings.CACHE_TTS_MB = 1
+
+	a := baixaAudioTeste(t, sessao, "Sem audio")
+	if a.Erro == nil {
+		t.Fatal("esperado erro quando o TTS não retorna o audio")
+	}
+
+	// A resposta de erro não pode ser guardada no cache.
+	if _, err := os.Stat(a.Path); err == nil {
+		t.Errorf("o arquivo %s não deveria ter sido gravado no cache", a.Path)
+	}
+}
+
+func TestMotorGoogleUsaCache(t *testing.T) {
+	f, sessao := novoServidorFake(t)
+	sessao.settings.CACHE_TTS_MB = 1
+
+	for i := 0; i < 2; i++ {
+		if a := baixaAudioTeste(t, sessao, "Texto repetido"); a.Erro != nil {
+			t.Fatalf("erro inesperado: %v", a.Erro)
+		}
+	}
+	if n := len(f.Consultas()); n != 1 {
+		t.Errorf("TTS acionado %d vezes, esperado 1 (a segunda vez deveria usar o cache)", n)
+	}
+}
+
+// Os motores desistem do audio quando o servidor não responde no tempo do parâmetro TIMEOUT.
+func TestMotoresTTSTimeout(t *testing.T) {
+	testes := []struct {
+		motor MotorTTS
+		voz   string
+	}{
+		{MotorGoogle{}, "pt-BR"},
+		{MotorOpenAI{}, "alloy"},
+	}
+
+	for _, tt := range testes {
+		t.Run(tt.motor.Nome(), func(t *testing.T) {
+			sessao := novoServidorLento(t)
+
+			inicio := time.Now()
+			if _, err := tt.motor.Sintetiza(sessao.configuracoes(), "Oi", "pt-BR", tt.voz); err == nil {
+				t.Fatal("esperado erro quando o servidor não responde")
+			}
+			if d := time.Since(inicio); d > 10*time.Second {
+				t.Errorf("aguardou %v, esperado o tempo do parâmetro TIMEOUT", d)
+			}
+		})
+	}
+}
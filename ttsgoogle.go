@@ -0,0 +1,104 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const (
+	MOTOR_GOOGLE = "google"
+
+	// Endereço padrão do TTS do Google, usado se o parâmetro TTS_URL não for informado.
+	URL_TTS_GOOGLE = "https://translate.google.com/translate_tts"
+)
+
+type (
+	// Motor de TTS do Google Translate. Não tem vozes com nomes: cada "voz" é um idioma/sotaque
+	// (parâmetro "tl"), por isso a voz padrão é o próprio idioma da narração.
+	MotorGoogle struct{}
+)
+
+func init() {
+	registraMotorTTS(MotorGoogle{})
+}
+
+func (MotorGoogle) Nome() string {
+	return MOTOR_GOOGLE
+}
+
+func (MotorGoogle) Vozes() []Voz {
+	return []Voz{
+		{"pt-BR", "Português do Brasil"},
+		{"pt-PT", "Português de Portugal"},
+		{"en-US", "Inglês dos Estados Unidos"},
+		{"en-GB", "Inglês do Reino Unido"},
+		{"en-AU", "Inglês da Austrália"},
+		{"en-IN", "Inglês da Índia"},
+		{"es-ES", "Espanhol da Espanha"},
+		{"es-US", "Espanhol dos Estados Unidos"},
+		{"fr-FR", "Francês da França"},
+		{"fr-CA", "Francês do Canadá"},
+		{"de-DE", "Alemão"},
+		{"it-IT", "Italiano"},
+		{"ja-JP", "Japonês"},
+		{"zh-CN", "Chinês (Mandarim)"},
+	}
+}
+
+func (MotorGoogle) VozPadrao(idioma string) string {
+	return idioma
+}
+
+// Envia o bloco de texto para o Google Translate para converter em audio
+// Lembrando que o limite de tamanho do texto é de 100 caracteres.
+// O parâmetro da QueryString "q" é o texto a ser narrado.
+// O parâmetro "tl" (To Language) é a voz (idioma/sotaque) em que o audio será gerado.
+func (MotorGoogle) Sintetiza(cfg Settings, texto, idioma, voz string) (io.ReadCloser, error) {
+
+	// O endereço pode ser trocado pelo parâmetro TTS_URL (ex: servidor local nos testes).
+	endereco := cfg.TTS_URL
+	if endereco == "" {
+		endereco = URL_TTS_GOOGLE
+	}
+
+	// Transforma o texto em padrão de URL
+	txt := url.QueryEscape(texto)
+	url := fmt.Sprintf("%s?ie=UTF-8&client=tw-ob&q=%s&tl=%s", endereco, txt, url.QueryEscape(voz))
+
+	// Estabelece a conexão com o site, desistindo após o tempo do parâmetro TIMEOUT.
+	response, err := novoCliente(cfg).ClienteHTTP().Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	// Se o site não retornou o audio, não grava a resposta (para não guardar um erro no cache).
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, novoErro("falha ao obter o audio: %s", response.Status)
+	}
+	return response.Body, nil
+}
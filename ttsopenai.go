@@ -0,0 +1,110 @@
+package main
+
+/* ==============================================================================
+Aviso legal: Este software é fornecido "como está", sem garantia de qualquer tipo,
+expressa ou implícita, incluindo, mas não se limitando a garantias de adequação a
+uma finalidade específica e não violação. Em nenhum caso o autor será responsável
+por quaisquer danos diretos, indiretos, incidentais, especiais, exemplares ou
+consequenciais (incluindo, mas não se limitando a, aquisição de bens ou serviços
+substitutos; perda de uso, dados ou lucros; ou interrupção de negócios)
+decorrentes de qualquer forma do uso deste software, mesmo que avisado da
+possibilidade de tais danos.
+
+Licença: Este software é distribuído sob a Licença Pública Geral GNU v3.0. Você
+pode usar, modificar e/ou redistribuir este software sob os termos da GPL v3.0.
+Para mais informações, consulte o arquivo LICENSE.md incluído neste repositório.
+
+Contribuições financeiras são bem-vindas e podem ser feitas através da chave
+PIX: 2dc5381e-78d6-4a62-9469-4f50d0ed8a01.
+
+Obrigado!
+Hugo S. Novaes
+hnovaes@yahoo.com
+==============================================================================*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	MOTOR_OPENAI = "openai"
+
+	// Modelo usado pela API de TTS da OpenAI.
+	MODELO_TTS_OPENAI = "tts-1"
+)
+
+type (
+	// Motor de TTS da OpenAI (endpoint /v1/audio/speech). As vozes são nomeadas e falam
+	// qualquer idioma: o idioma é deduzido do próprio texto.
+	MotorOpenAI struct{}
+
+	requisicaoTTSOpenAI struct {
+		Model          string `json:"model"`
+		Input          string `json:"input"`
+		Voice          string `json:"voice"`
+		ResponseFormat string `json:"response_format"`
+	}
+)
+
+func init() {
+	registraMotorTTS(MotorOpenAI{})
+}
+
+func (MotorOpenAI) Nome() string {
+	return MOTOR_OPENAI
+}
+
+func (MotorOpenAI) Vozes() []Voz {
+	return []Voz{
+		{"alloy", "Neutra, equilibrada"},
+		{"echo", "Masculina, calma"},
+		{"fable", "Britânica, expressiva"},
+		{"onyx", "Masculina, grave"},
+		{"nova", "Feminina, jovem"},
+		{"shimmer", "Feminina, suave"},
+	}
+}
+
+func (MotorOpenAI) VozPadrao(idioma string) string {
+	return "alloy"
+}
+
+// Envia o bloco de texto para a API de TTS da OpenAI. O endereço é obtido a partir do
+// parâmetro URL_API, trocando "chat/completions" por "audio/speech".
+func (MotorOpenAI) Sintetiza(cfg Settings, texto, idioma, voz string) (io.ReadCloser, error) {
+	corpo, _ := json.Marshal(requisicaoTTSOpenAI{
+		Model:          MODELO_TTS_OPENAI,
+		Input:          texto,
+		Voice:          voz,
+		ResponseFormat: "mp3",
+	})
+
+	cliente := novoCliente(cfg)
+	url := cliente.Endereco("audio/speech")
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(corpo))
+	req.Header.Add("Content-Type", "application/json")
+	cliente.Autoriza(req)
+
+	response, err := cliente.ClienteHTTP().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Se a API não retornou o audio, não grava a resposta (para não guardar um erro no cache).
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		var erro struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.NewDecoder(response.Body).Decode(&erro) == nil && erro.Error.Message != "" {
+			return nil, novoErro("falha ao obter o audio: %s", erro.Error.Message)
+		}
+		return nil, novoErro("falha ao obter o audio: %s", response.Status)
+	}
+	return response.Body, nil
+}